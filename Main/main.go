@@ -34,6 +34,7 @@ type Game struct {
 	translationSpeed                         int
 	angle                                    int
 	isRight, isLeft, isTop, isDown, isAttack bool
+	player                                   objects.PlayerObject
 }
 
 // Initalisation of Game with
@@ -42,6 +43,19 @@ func NewGame(screenWidth, screenHeight int) *Game {
 	buttonImage := ebiten.NewImage(200, 100)
 	buttonImage.Fill(color.RGBA{220, 220, 220, 255})
 
+	// Player which is created once, so its animation state carries over between frames
+	x, y := 100, 100
+	player := objects.NewPlayerObject(nil, color.Black, color.RGBA{150, 100, 200, 255}, x, y)
+	err := player.LoadHero("Movement")
+	if err != nil {
+		logError(err)
+	}
+	player.SetRightMovement(createRange(12, 17))
+	player.SetLeftMovement(createRange(6, 11))
+	player.SetTopMovement(createRange(0, 5))
+	player.SetDownMovement(createRange(18, 23))
+	player.SetCalm(18)
+
 	return &Game{
 		buttonImage:      buttonImage,
 		backgroundColor:  color.Black,
@@ -55,6 +69,7 @@ func NewGame(screenWidth, screenHeight int) *Game {
 		isAttack:         false,
 		isLeft:           false,
 		isDown:           false,
+		player:           player,
 	}
 }
 
@@ -174,54 +189,41 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	shapOb := objects.NewShapeObject(drawOb, tranOb)
 
 	squaOb1 := objects.NewSquareObject(shapOb, 100, 100, 100, col)
-	squaOb1.Draw()
 	squaOb1.Scale(2)
 	squaOb1.Rotate(-30)
 	squaOb1.Translate(200, 200)
+	squaOb1.Draw()
 	squaOb2 := objects.EnhancedNewSquareObject(screen, g.backgroundColor, 100, 100, 100, col)
-	squaOb2.Draw()
-	squaOb2.Rotate(g.angle)
+	squaOb2.Rotate(float64(g.angle))
 	squaOb2.Scale(2)
 	squaOb2.Translate(100, 100)
+	squaOb2.Draw()
 
 	squaOb3 := objects.EnhancedNewSquareObject(screen, g.backgroundColor, 500, 500, 100, white)
-	squaOb3.Draw()
-	squaOb3.Rotate(g.angle)
+	squaOb3.Rotate(float64(g.angle))
 	squaOb3.Scale(2)
 	squaOb3.Translate(100, 100)
+	squaOb3.Draw()
 
 	lineOb1 := objects.EnhancedNewLineObject(screen, g.backgroundColor, 500, 500, 600, 600, col)
 
-	lineOb1.Draw()
 	lineOb1.Translate(-300, -400)
 	lineOb1.Scale(4)
 
-	lineOb1.Rotate(g.angle)
+	lineOb1.Rotate(float64(g.angle))
+	lineOb1.Draw()
 	lineOb2 := objects.EnhancedNewLineObject(screen, g.backgroundColor, 500, 500, 600, 600, col)
-	lineOb2.Draw()
 	lineOb2.Translate(-400, -300)
 	lineOb2.Scale(4)
+	lineOb2.Draw()
 
 	circOb1 := objects.EnhancedNewCircleObject(screen, g.backgroundColor, 100, 600, 40, col)
-	circOb1.Draw()
 	circOb1.Scale(2)
 	circOb1.Translate(0, -200)
+	circOb1.Draw()
 
-	x, y := 100, 100
-
-	player := objects.NewPlayerObject(screen, g.backgroundColor, col, x+g.xTranslate, y+g.yTranslate)
-	err := player.LoadHero("Movement")
-
-	player.SetRightMovement(createRange(12, 17))
-	player.SetLeftMovement(createRange(6, 11))
-	player.SetTopMovement(createRange(0, 5))
-	player.SetDownMovement(createRange(18, 23))
-	player.SetCalm(18)
-	err = player.Move(g.isRight, g.isLeft, g.isTop, g.isDown, g.isAttack, g.xTranslate, g.yTranslate)
-	if err != nil {
-		logError(err)
-	}
-
+	g.player.GetSpriteObject().GetBitmapObject().GetDrawableObject().GetGameObject().SetScreen(screen)
+	err := g.player.Move(g.isRight, g.isLeft, g.isTop, g.isDown, g.isAttack, g.xTranslate, g.yTranslate)
 	if err != nil {
 		logError(err)
 	}
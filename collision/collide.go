@@ -0,0 +1,152 @@
+package collision
+
+import "math"
+
+// Contact describes how two overlapping shapes intersect.
+type Contact struct {
+	Normal Vector  // The unit direction from the first shape to the second one.
+	Depth  float64 // How far the second shape must move along Normal to stop overlapping.
+}
+
+// Collide tests whether two shapes overlap using the separating axis theorem.
+// Touching shapes (depth 0) do not overlap.
+// @param a Shape: The first shape.
+// @param b Shape: The second shape.
+// @return Contact: The contact, moving b by Normal*Depth (or a by the opposite) separates the shapes.
+// @return bool: True if the shapes overlap.
+func Collide(a, b Shape) (Contact, bool) {
+	if !a.Bounds().Overlaps(b.Bounds()) {
+		return Contact{}, false
+	}
+	circleA, isCircleA := a.(Circle)
+	circleB, isCircleB := b.(Circle)
+	switch {
+	case isCircleA && isCircleB:
+		return collideCircles(circleA, circleB)
+	case isCircleA:
+		contact, ok := collidePolygonCircle(b.vertices(), b.Center(), circleA)
+		contact.Normal = contact.Normal.Scale(-1)
+		return contact, ok
+	case isCircleB:
+		return collidePolygonCircle(a.vertices(), a.Center(), circleB)
+	}
+	return collidePolygons(a.vertices(), a.Center(), b.vertices(), b.Center())
+}
+
+// Overlaps tests whether two shapes overlap.
+// @param a Shape: The first shape.
+// @param b Shape: The second shape.
+// @return bool: True if the shapes overlap.
+func Overlaps(a, b Shape) bool {
+	_, ok := Collide(a, b)
+	return ok
+}
+
+// collideCircles tests two circles.
+// @param a, b Circle: The circles.
+// @return Contact: The contact from a to b.
+// @return bool: True if the circles overlap.
+func collideCircles(a, b Circle) (Contact, bool) {
+	delta := b.Position.Sub(a.Position)
+	distance := delta.Length()
+	depth := a.Radius + b.Radius - distance
+	if depth <= 0 {
+		return Contact{}, false
+	}
+	normal := delta.Normalize()
+	if distance == 0 {
+		normal = Vector{X: 1} // Same centers, any direction separates the circles.
+	}
+	return Contact{Normal: normal, Depth: depth}, true
+}
+
+// collidePolygons tests two convex polygons on the normals of all their edges.
+// @param a, b []Vector: The corners of the polygons.
+// @param centerA, centerB Vector: The centers used to orient the normal.
+// @return Contact: The contact from a to b.
+// @return bool: True if the polygons overlap.
+func collidePolygons(a []Vector, centerA Vector, b []Vector, centerB Vector) (Contact, bool) {
+	axes := append(edgeNormals(a), edgeNormals(b)...)
+	return separatingAxes(axes, centerB.Sub(centerA), func(axis Vector) (float64, float64, float64, float64) {
+		minA, maxA := project(a, axis)
+		minB, maxB := project(b, axis)
+		return minA, maxA, minB, maxB
+	})
+}
+
+// collidePolygonCircle tests a convex polygon and a circle on the normals of the polygon's edges
+// and the axis from the closest corner to the circle's center.
+// @param polygon []Vector: The corners of the polygon.
+// @param center Vector: The center of the polygon used to orient the normal.
+// @param circle Circle: The circle.
+// @return Contact: The contact from the polygon to the circle.
+// @return bool: True if the shapes overlap.
+func collidePolygonCircle(polygon []Vector, center Vector, circle Circle) (Contact, bool) {
+	closest := polygon[0]
+	for _, point := range polygon[1:] {
+		if point.Sub(circle.Position).Length() < closest.Sub(circle.Position).Length() {
+			closest = point
+		}
+	}
+	axes := edgeNormals(polygon)
+	if axis := circle.Position.Sub(closest).Normalize(); axis != (Vector{}) {
+		axes = append(axes, axis)
+	}
+	return separatingAxes(axes, circle.Position.Sub(center), func(axis Vector) (float64, float64, float64, float64) {
+		minA, maxA := project(polygon, axis)
+		position := circle.Position.Dot(axis)
+		return minA, maxA, position - circle.Radius, position + circle.Radius
+	})
+}
+
+// separatingAxes projects two shapes on every axis and returns the axis with the smallest overlap.
+// @param axes []Vector: The unit axes to test.
+// @param direction Vector: The direction from the first shape to the second one, used to orient the normal.
+// @param projections func(axis Vector) (minA, maxA, minB, maxB float64): Projects both shapes on an axis.
+// @return Contact: The contact from the first shape to the second one.
+// @return bool: False if one of the axes separates the shapes.
+func separatingAxes(axes []Vector, direction Vector, projections func(axis Vector) (float64, float64, float64, float64)) (Contact, bool) {
+	contact := Contact{Depth: math.Inf(1)}
+	for _, axis := range axes {
+		minA, maxA, minB, maxB := projections(axis)
+		overlap := math.Min(maxA-minB, maxB-minA)
+		if overlap <= 0 {
+			return Contact{}, false
+		}
+		if overlap < contact.Depth {
+			contact.Depth = overlap
+			contact.Normal = axis
+		}
+	}
+	if contact.Normal.Dot(direction) < 0 {
+		contact.Normal = contact.Normal.Scale(-1)
+	}
+	return contact, true
+}
+
+// edgeNormals returns the unit normals of the edges of a polygon.
+// @param polygon []Vector: The corners of the polygon.
+// @return []Vector: The normals.
+func edgeNormals(polygon []Vector) []Vector {
+	normals := make([]Vector, 0, len(polygon))
+	for i := range polygon {
+		edge := polygon[(i+1)%len(polygon)].Sub(polygon[i])
+		if normal := edge.Perpendicular().Normalize(); normal != (Vector{}) {
+			normals = append(normals, normal)
+		}
+	}
+	return normals
+}
+
+// project returns the interval covered by the projection of points on an axis.
+// @param points []Vector: The points.
+// @param axis Vector: The unit axis.
+// @return (float64, float64): The minimum and maximum of the projection.
+func project(points []Vector, axis Vector) (float64, float64) {
+	minimum, maximum := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		position := point.Dot(axis)
+		minimum, maximum = math.Min(minimum, position), math.Max(maximum, position)
+	}
+	return minimum, maximum
+}
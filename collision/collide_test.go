@@ -0,0 +1,74 @@
+package collision
+
+import (
+	"math"
+	"testing"
+)
+
+// nearlyEqual compares two floats with a tolerance suitable for the projections.
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCollide(t *testing.T) {
+	triangle, err := NewPolygon(NewVector(20, 0), NewVector(30, 10), NewVector(10, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name    string
+		a, b    Shape
+		overlap bool
+		normal  Vector
+		depth   float64
+	}{
+		{"aabb overlap", NewAABB(0, 0, 10, 10), NewAABB(8, 2, 18, 12), true, NewVector(1, 0), 2},
+		{"aabb touching", NewAABB(0, 0, 10, 10), NewAABB(10, 0, 20, 10), false, Vector{}, 0},
+		{"circles", NewCircle(0, 0, 5), NewCircle(0, 8, 5), true, NewVector(0, 1), 2},
+		{"circles apart", NewCircle(0, 0, 5), NewCircle(8, 8, 5), false, Vector{}, 0},
+		{"aabb circle", NewAABB(0, 0, 10, 10), NewCircle(13, 5, 4), true, NewVector(1, 0), 1},
+		{"circle aabb", NewCircle(13, 5, 4), NewAABB(0, 0, 10, 10), true, NewVector(-1, 0), 1},
+		{"circle near corner", NewAABB(0, 0, 10, 10), NewCircle(13, 13, 4), false, Vector{}, 0},
+		{"obb diamond corner", NewOBB(0, 0, 10, 10, 45), NewAABB(6, -1, 16, 1), true, NewVector(1, 0), 5*math.Sqrt2 - 6},
+		{"obb diamond apart", NewOBB(0, 0, 10, 10, 45), NewAABB(4, 4, 14, 14), false, Vector{}, 0},
+		{"triangle aabb", triangle, NewAABB(15, 8, 25, 18), true, NewVector(0, 1), 2},
+	}
+	for _, tc := range cases {
+		contact, ok := Collide(tc.a, tc.b)
+		if ok != tc.overlap {
+			t.Errorf("%s: overlap = %v, want %v", tc.name, ok, tc.overlap)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !nearlyEqual(contact.Normal.X, tc.normal.X) || !nearlyEqual(contact.Normal.Y, tc.normal.Y) || !nearlyEqual(contact.Depth, tc.depth) {
+			t.Errorf("%s: contact = %+v, want normal %v depth %v", tc.name, contact, tc.normal, tc.depth)
+		}
+	}
+}
+
+func TestContactSeparates(t *testing.T) {
+	a := NewOBB(0, 0, 20, 6, 30)
+	b := NewCircle(4, 4, 3)
+	contact, ok := Collide(a, b)
+	if !ok {
+		t.Fatal("expected an overlap")
+	}
+	moved := NewCircle(b.Position.X+contact.Normal.X*(contact.Depth+1e-6), b.Position.Y+contact.Normal.Y*(contact.Depth+1e-6), b.Radius)
+	if Overlaps(a, moved) {
+		t.Errorf("moving by the contact %+v does not separate the shapes", contact)
+	}
+}
+
+func TestNewPolygon(t *testing.T) {
+	if _, err := NewPolygon(NewVector(0, 0), NewVector(1, 1)); err == nil {
+		t.Error("expected an error for two points")
+	}
+	if _, err := NewPolygon(NewVector(0, 0), NewVector(10, 0), NewVector(2, 2), NewVector(0, 10)); err == nil {
+		t.Error("expected an error for a concave polygon")
+	}
+	if _, err := NewPolygon(NewVector(0, 0), NewVector(1, 1), NewVector(2, 2)); err == nil {
+		t.Error("expected an error for collinear points")
+	}
+}
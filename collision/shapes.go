@@ -0,0 +1,277 @@
+// Package collision store the collision detection of the engine: shapes, overlap tests and contacts
+package collision
+
+import (
+	"errors"
+	"math"
+)
+
+// Vector represents a point or a direction in screen coordinates.
+type Vector struct {
+	X, Y float64 // The coordinates of the vector.
+}
+
+// NewVector creates a vector from its coordinates.
+// @param x, y float64: The coordinates.
+// @return Vector: The new vector.
+func NewVector(x, y float64) Vector {
+	return Vector{X: x, Y: y}
+}
+
+// Add returns the sum of two vectors.
+// @param other Vector: The vector to add.
+// @return Vector: The sum.
+func (v Vector) Add(other Vector) Vector {
+	return Vector{X: v.X + other.X, Y: v.Y + other.Y}
+}
+
+// Sub returns the difference of two vectors.
+// @param other Vector: The vector to subtract.
+// @return Vector: The difference.
+func (v Vector) Sub(other Vector) Vector {
+	return Vector{X: v.X - other.X, Y: v.Y - other.Y}
+}
+
+// Scale returns the vector multiplied by a factor.
+// @param factor float64: The factor.
+// @return Vector: The scaled vector.
+func (v Vector) Scale(factor float64) Vector {
+	return Vector{X: v.X * factor, Y: v.Y * factor}
+}
+
+// Dot returns the dot product of two vectors.
+// @param other Vector: The second vector.
+// @return float64: The dot product.
+func (v Vector) Dot(other Vector) float64 {
+	return v.X*other.X + v.Y*other.Y
+}
+
+// Length returns the length of the vector.
+// @return float64: The length.
+func (v Vector) Length() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns the vector with length 1, or the zero vector for the zero vector.
+// @return Vector: The unit vector.
+func (v Vector) Normalize() Vector {
+	length := v.Length()
+	if length == 0 {
+		return Vector{}
+	}
+	return v.Scale(1 / length)
+}
+
+// Perpendicular returns the vector rotated by 90 degrees.
+// @return Vector: The perpendicular vector.
+func (v Vector) Perpendicular() Vector {
+	return Vector{X: -v.Y, Y: v.X}
+}
+
+// Shape represents a convex shape which can be tested for overlaps with Collide.
+// The implementations are AABB, Circle, OBB and Polygon.
+type Shape interface {
+	// Bounds returns the axis-aligned box around the shape.
+	// @return AABB: The bounding box.
+	Bounds() AABB
+
+	// Center returns the center of the shape.
+	// @return Vector: The center.
+	Center() Vector
+
+	// vertices returns the corners of the shape in order, nil for circles.
+	// @return []Vector: The corners.
+	vertices() []Vector
+}
+
+// AABB is an axis-aligned rectangle.
+type AABB struct {
+	Min, Max Vector // The top-left and the bottom-right corners.
+}
+
+// NewAABB creates an axis-aligned rectangle from two corners in any order.
+// @param x1, y1, x2, y2 float64: The corners.
+// @return AABB: The new rectangle.
+func NewAABB(x1, y1, x2, y2 float64) AABB {
+	return AABB{
+		Min: Vector{X: math.Min(x1, x2), Y: math.Min(y1, y2)},
+		Max: Vector{X: math.Max(x1, x2), Y: math.Max(y1, y2)},
+	}
+}
+
+// Bounds returns the rectangle itself.
+// @return AABB: The bounding box.
+func (box AABB) Bounds() AABB {
+	return box
+}
+
+// Center returns the center of the rectangle.
+// @return Vector: The center.
+func (box AABB) Center() Vector {
+	return box.Min.Add(box.Max).Scale(0.5)
+}
+
+// Overlaps tests whether two rectangles overlap. Touching rectangles do not overlap.
+// @param other AABB: The second rectangle.
+// @return bool: True if the rectangles overlap.
+func (box AABB) Overlaps(other AABB) bool {
+	return box.Min.X < other.Max.X && other.Min.X < box.Max.X && box.Min.Y < other.Max.Y && other.Min.Y < box.Max.Y
+}
+
+// vertices returns the corners of the rectangle clockwise on screen.
+// @return []Vector: The corners.
+func (box AABB) vertices() []Vector {
+	return []Vector{box.Min, {X: box.Max.X, Y: box.Min.Y}, box.Max, {X: box.Min.X, Y: box.Max.Y}}
+}
+
+// Circle is a circle given by its center and radius.
+type Circle struct {
+	Position Vector  // The center of the circle.
+	Radius   float64 // The radius of the circle.
+}
+
+// NewCircle creates a circle.
+// @param x, y float64: The center of the circle.
+// @param radius float64: The radius of the circle.
+// @return Circle: The new circle.
+func NewCircle(x, y, radius float64) Circle {
+	return Circle{Position: Vector{X: x, Y: y}, Radius: radius}
+}
+
+// Bounds returns the axis-aligned box around the circle.
+// @return AABB: The bounding box.
+func (circle Circle) Bounds() AABB {
+	return NewAABB(circle.Position.X-circle.Radius, circle.Position.Y-circle.Radius, circle.Position.X+circle.Radius, circle.Position.Y+circle.Radius)
+}
+
+// Center returns the center of the circle.
+// @return Vector: The center.
+func (circle Circle) Center() Vector {
+	return circle.Position
+}
+
+// vertices returns nil, circles have no corners.
+// @return []Vector: Always nil.
+func (circle Circle) vertices() []Vector {
+	return nil
+}
+
+// OBB is a rectangle rotated around its center.
+type OBB struct {
+	Position   Vector  // The center of the rectangle.
+	HalfWidth  float64 // Half of the width of the rectangle.
+	HalfHeight float64 // Half of the height of the rectangle.
+	Angle      float64 // The rotation in degrees, clockwise on screen like TransformableObject.
+}
+
+// NewOBB creates a rotated rectangle.
+// @param x, y float64: The center of the rectangle.
+// @param width, height float64: The size of the rectangle.
+// @param angle float64: The rotation in degrees.
+// @return OBB: The new rectangle.
+func NewOBB(x, y, width, height, angle float64) OBB {
+	return OBB{Position: Vector{X: x, Y: y}, HalfWidth: width / 2, HalfHeight: height / 2, Angle: angle}
+}
+
+// Bounds returns the axis-aligned box around the rotated rectangle.
+// @return AABB: The bounding box.
+func (box OBB) Bounds() AABB {
+	return boundsOf(box.vertices())
+}
+
+// Center returns the center of the rectangle.
+// @return Vector: The center.
+func (box OBB) Center() Vector {
+	return box.Position
+}
+
+// vertices returns the corners of the rotated rectangle.
+// @return []Vector: The corners.
+func (box OBB) vertices() []Vector {
+	sin, cos := math.Sincos(box.Angle * math.Pi / 180)
+	axisX := Vector{X: cos, Y: sin}.Scale(box.HalfWidth)
+	axisY := Vector{X: -sin, Y: cos}.Scale(box.HalfHeight)
+	return []Vector{
+		box.Position.Sub(axisX).Sub(axisY),
+		box.Position.Add(axisX).Sub(axisY),
+		box.Position.Add(axisX).Add(axisY),
+		box.Position.Sub(axisX).Add(axisY),
+	}
+}
+
+// Polygon is a convex polygon.
+type Polygon struct {
+	points []Vector // The corners of the polygon in order.
+}
+
+// NewPolygon creates a convex polygon from its corners in order (clockwise or counterclockwise).
+// @param points ...Vector: The corners of the polygon, the first one is not repeated at the end.
+// @return Polygon: The new polygon.
+// @return error: Returns an error if there are less than 3 corners or the polygon is not convex.
+func NewPolygon(points ...Vector) (Polygon, error) {
+	if len(points) < 3 {
+		return Polygon{}, errors.New("collision error: polygon needs at least 3 points")
+	}
+	sign := 0.0
+	for i := range points {
+		a, b, c := points[i], points[(i+1)%len(points)], points[(i+2)%len(points)]
+		cross := b.Sub(a).X*c.Sub(b).Y - b.Sub(a).Y*c.Sub(b).X
+		if cross == 0 {
+			continue
+		}
+		if sign != 0 && (cross > 0) != (sign > 0) {
+			return Polygon{}, errors.New("collision error: polygon is not convex")
+		}
+		sign = cross
+	}
+	if sign == 0 {
+		return Polygon{}, errors.New("collision error: polygon has no area")
+	}
+	return Polygon{points: append([]Vector(nil), points...)}, nil
+}
+
+// Points returns the corners of the polygon.
+// @return []Vector: A copy of the corners.
+func (polygon Polygon) Points() []Vector {
+	return append([]Vector(nil), polygon.points...)
+}
+
+// Bounds returns the axis-aligned box around the polygon.
+// @return AABB: The bounding box.
+func (polygon Polygon) Bounds() AABB {
+	return boundsOf(polygon.points)
+}
+
+// Center returns the average of the corners of the polygon.
+// @return Vector: The center.
+func (polygon Polygon) Center() Vector {
+	center := Vector{}
+	for _, point := range polygon.points {
+		center = center.Add(point)
+	}
+	if len(polygon.points) == 0 {
+		return center
+	}
+	return center.Scale(1 / float64(len(polygon.points)))
+}
+
+// vertices returns the corners of the polygon.
+// @return []Vector: The corners.
+func (polygon Polygon) vertices() []Vector {
+	return polygon.points
+}
+
+// boundsOf returns the axis-aligned box around points.
+// @param points []Vector: The points.
+// @return AABB: The bounding box.
+func boundsOf(points []Vector) AABB {
+	if len(points) == 0 {
+		return AABB{}
+	}
+	box := AABB{Min: points[0], Max: points[0]}
+	for _, point := range points[1:] {
+		box.Min.X, box.Min.Y = math.Min(box.Min.X, point.X), math.Min(box.Min.Y, point.Y)
+		box.Max.X, box.Max.Y = math.Max(box.Max.X, point.X), math.Max(box.Max.Y, point.Y)
+	}
+	return box
+}
@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"Game_Engine/collision"
+	"Game_Engine/objects"
+	"errors"
+)
+
+// Transform places an entity on the screen. It is a TransformableObject of the objects package,
+// so the entities share the transformation model of the shapes and sprites, including parents.
+type Transform struct {
+	objects.TransformableObject // The transformation of the entity.
+}
+
+// NewTransform creates a transform translated to a position.
+// @param x, y float64: The position.
+// @return Transform: The transform.
+func NewTransform(x, y float64) Transform {
+	transformableObject := objects.NewTransformableObject(nil)
+	transformableObject.Translate(x, y)
+	return Transform{TransformableObject: transformableObject}
+}
+
+// Velocity moves the Transform of an entity with the movement system.
+type Velocity struct {
+	X, Y float64 // The speed in pixels per second.
+}
+
+// Shape draws a shape object (square, circle or line) for an entity.
+// The wrapped object is not copied, it can be attached to a scene node at the same time.
+type Shape struct {
+	Object objects.ShapeDrawer // The wrapped shape object.
+}
+
+// Draw draws the wrapped shape with its current transformations.
+// @return error: Returns nil if the drawing operation was successful.
+func (shape Shape) Draw() error {
+	return shape.Object.Draw()
+}
+
+// Sprite draws the current frame of a sprite object for an entity.
+// The wrapped object is not copied, it can be attached to a scene node at the same time.
+type Sprite struct {
+	Object    objects.SpriteObject // The wrapped sprite object.
+	BitmapNum int                  // The index of the BitmapHandler which holds the frames.
+}
+
+// Draw draws the current frame of the wrapped sprite.
+// @return error: Returns an error if the sprite has no frames.
+func (sprite Sprite) Draw() error {
+	name, err := sprite.Object.GetCurrentName()
+	if err != nil {
+		return err
+	}
+	return sprite.Object.GetBitmapObject().Draw(name, sprite.BitmapNum)
+}
+
+// Collider gives the shape of an entity to the collision system.
+type Collider struct {
+	Shape func() collision.Shape // Returns the shape in screen coordinates when collisions are tested, nil for none.
+}
+
+// NewCollider creates a collider with a fixed shape, for example a wall.
+// @param shape collision.Shape: The shape in screen coordinates.
+// @return Collider: The collider.
+func NewCollider(shape collision.Shape) Collider {
+	return Collider{Shape: func() collision.Shape { return shape }}
+}
+
+// AddShape wraps a shape object as the Shape and the Transform of an entity, the Transform is the
+// transformable object of the shape, so moving the entity moves the shape. Squares and circles also get a
+// Collider following their transformations.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @param shape objects.ShapeDrawer: The shape object.
+// @return error: Returns an error if the shape is nil or the entity does not exist.
+func AddShape(world World, entity Entity, shape objects.ShapeDrawer) error {
+	if shape == nil {
+		return errors.New("ecs error: shape is nil")
+	}
+	if err := Add(world, entity, Shape{Object: shape}); err != nil {
+		return err
+	}
+	Add(world, entity, Transform{TransformableObject: shape.GetShapeObject().GetTransformableObject()})
+	if collider, ok := shape.(interface{ Collider() collision.Shape }); ok {
+		Add(world, entity, Collider{Shape: collider.Collider})
+	}
+	return nil
+}
+
+// AddSprite wraps a sprite object as the Sprite and the Transform of an entity, the Transform is the
+// transformable object of the sprite's bitmaps. The entity also gets a Collider around the current frame.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @param sprite objects.SpriteObject: The sprite object.
+// @param bmNum int: The index of the BitmapHandler which holds the frames.
+// @return error: Returns an error if the sprite is nil or the entity does not exist.
+func AddSprite(world World, entity Entity, sprite objects.SpriteObject, bmNum int) error {
+	if sprite == nil {
+		return errors.New("ecs error: sprite is nil")
+	}
+	if err := Add(world, entity, Sprite{Object: sprite, BitmapNum: bmNum}); err != nil {
+		return err
+	}
+	Add(world, entity, Transform{TransformableObject: sprite.GetBitmapObject().GetTransformableObject()})
+	Add(world, entity, Collider{Shape: func() collision.Shape {
+		bounds, err := sprite.Bounds(bmNum)
+		if err != nil {
+			return nil
+		}
+		return collision.NewAABB(float64(bounds.Min.X), float64(bounds.Min.Y), float64(bounds.Max.X), float64(bounds.Max.Y))
+	}})
+	return nil
+}
@@ -0,0 +1,132 @@
+package ecs
+
+import "reflect"
+
+// Filter is a condition of a query on the components of an entity, created by With and Without.
+type Filter struct {
+	componentType reflect.Type // The type of the component.
+	exclude       bool         // True if the entity must not have the component.
+}
+
+// With creates a filter matching the entities which have a component of type T.
+// @return Filter: The filter.
+func With[T any]() Filter {
+	return Filter{componentType: componentType[T]()}
+}
+
+// Without creates a filter matching the entities which do not have a component of type T.
+// @return Filter: The filter.
+func Without[T any]() Filter {
+	return Filter{componentType: componentType[T](), exclude: true}
+}
+
+// Query returns the entities matching all filters.
+// The smallest storage of the required components is scanned, so a query for a rare component is cheap
+// even in a large world. Without any With filter all entities are scanned.
+// @param world World: The world.
+// @param filters ...Filter: The conditions.
+// @return []Entity: The matching entities in ascending order.
+func Query(world World, filters ...Filter) []Entity {
+	var candidates []Entity
+	scanned := -1
+	for i, filter := range filters {
+		if filter.exclude {
+			continue
+		}
+		store := world.storeOf(filter.componentType)
+		if store == nil {
+			return nil
+		}
+		if scanned < 0 || len(store.list()) < len(candidates) {
+			candidates, scanned = store.list(), i
+		}
+	}
+	if scanned < 0 {
+		candidates = world.Entities()
+	}
+	var entities []Entity
+	for _, entity := range candidates {
+		if matches(world, entity, filters, scanned) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// matches tests an entity against filters.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @param filters []Filter: The conditions.
+// @param skip int: The index of a filter known to match, -1 for none.
+// @return bool: True if the entity matches all filters.
+func matches(world World, entity Entity, filters []Filter, skip int) bool {
+	for i, filter := range filters {
+		if i == skip {
+			continue
+		}
+		store := world.storeOf(filter.componentType)
+		if has := store != nil && store.has(entity); has == filter.exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// Each calls a function for every entity having a component of type A and matching the filters.
+// The entities are found before the first call, so the function may add, remove and destroy entities.
+// Entities which lose the component before their turn are skipped.
+// @param world World: The world.
+// @param function func(entity Entity, a *A) error: The function, the pointer changes the stored component.
+// @param filters ...Filter: Further conditions.
+// @return error: Returns the first error of the function, the remaining entities are not visited.
+func Each[A any](world World, function func(entity Entity, a *A) error, filters ...Filter) error {
+	for _, entity := range Query(world, append([]Filter{With[A]()}, filters...)...) {
+		a, ok := Get[A](world, entity)
+		if !ok {
+			continue
+		}
+		if err := function(entity, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Each2 calls a function for every entity having components of types A and B and matching the filters.
+// @param world World: The world.
+// @param function func(entity Entity, a *A, b *B) error: The function, the pointers change the stored components.
+// @param filters ...Filter: Further conditions.
+// @return error: Returns the first error of the function, the remaining entities are not visited.
+func Each2[A, B any](world World, function func(entity Entity, a *A, b *B) error, filters ...Filter) error {
+	for _, entity := range Query(world, append([]Filter{With[A](), With[B]()}, filters...)...) {
+		a, okA := Get[A](world, entity)
+		b, okB := Get[B](world, entity)
+		if !okA || !okB {
+			continue
+		}
+		if err := function(entity, a, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Each3 calls a function for every entity having components of types A, B and C and matching the filters.
+// @param world World: The world.
+// @param function func(entity Entity, a *A, b *B, c *C) error: The function, the pointers change the stored components.
+// @param filters ...Filter: Further conditions.
+// @return error: Returns the first error of the function, the remaining entities are not visited.
+func Each3[A, B, C any](world World, function func(entity Entity, a *A, b *B, c *C) error, filters ...Filter) error {
+	for _, entity := range Query(world, append([]Filter{With[A](), With[B](), With[C]()}, filters...)...) {
+		a, okA := Get[A](world, entity)
+		b, okB := Get[B](world, entity)
+		c, okC := Get[C](world, entity)
+		if !okA || !okB || !okC {
+			continue
+		}
+		if err := function(entity, a, b, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
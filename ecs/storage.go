@@ -0,0 +1,145 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// componentStore is the part of the storage of a component type which does not depend on the type.
+type componentStore interface {
+	// has tells whether an entity has a component in the storage.
+	// @param entity Entity: The entity.
+	// @return bool: True if the entity has a component.
+	has(entity Entity) bool
+
+	// remove removes the component of an entity.
+	// @param entity Entity: The entity.
+	// @return bool: False if the entity had no component.
+	remove(entity Entity) bool
+
+	// list returns the entities having a component in the storage.
+	// @return []Entity: The entities in ascending order, the slice must not be changed.
+	list() []Entity
+}
+
+// storage stores the components of one type.
+// Components are kept behind pointers, so a pointer returned by Get stays valid until the component is removed.
+type storage[T any] struct {
+	components map[Entity]*T // The component of each entity.
+	entities   []Entity      // The entities having a component in ascending order.
+}
+
+// has tells whether an entity has a component in the storage.
+// @param entity Entity: The entity.
+// @return bool: True if the entity has a component.
+func (storage *storage[T]) has(entity Entity) bool {
+	_, ok := storage.components[entity]
+	return ok
+}
+
+// remove removes the component of an entity.
+// @param entity Entity: The entity.
+// @return bool: False if the entity had no component.
+func (storage *storage[T]) remove(entity Entity) bool {
+	if _, ok := storage.components[entity]; !ok {
+		return false
+	}
+	delete(storage.components, entity)
+	i := sort.Search(len(storage.entities), func(i int) bool { return storage.entities[i] >= entity })
+	// A new slice, so queries iterating over the old list are not disturbed.
+	storage.entities = append(storage.entities[:i:i], storage.entities[i+1:]...)
+	return true
+}
+
+// list returns the entities having a component in the storage.
+// @return []Entity: The entities in ascending order.
+func (storage *storage[T]) list() []Entity {
+	return storage.entities
+}
+
+// set adds or replaces the component of an entity.
+// @param entity Entity: The entity.
+// @param component T: The component.
+func (storage *storage[T]) set(entity Entity, component T) {
+	if existing, ok := storage.components[entity]; ok {
+		*existing = component
+		return
+	}
+	storage.components[entity] = &component
+	i := sort.Search(len(storage.entities), func(i int) bool { return storage.entities[i] >= entity })
+	entities := make([]Entity, 0, len(storage.entities)+1)
+	entities = append(append(append(entities, storage.entities[:i]...), entity), storage.entities[i:]...)
+	storage.entities = entities
+}
+
+// componentType returns the reflect.Type identifying the storage of a component type.
+// @return reflect.Type: The type T.
+func componentType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// storageOf returns the storage of the components of type T.
+// @param world World: The world.
+// @param create bool: True to register the storage if the world has none.
+// @return *storage[T]: The storage, nil if the world has none and create is false.
+func storageOf[T any](world World, create bool) *storage[T] {
+	key := componentType[T]()
+	if store := world.storeOf(key); store != nil {
+		return store.(*storage[T])
+	}
+	if !create {
+		return nil
+	}
+	store := &storage[T]{components: map[Entity]*T{}}
+	world.addStore(key, store)
+	return store
+}
+
+// Add adds a component to an entity, replacing the component of the same type the entity already has.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @param component T: The component.
+// @return error: Returns an error if the entity does not exist.
+func Add[T any](world World, entity Entity, component T) error {
+	if !world.Alive(entity) {
+		return fmt.Errorf("ecs error: entity %d does not exist", entity)
+	}
+	storageOf[T](world, true).set(entity, component)
+	return nil
+}
+
+// Get returns the component of an entity. Changes through the pointer change the stored component.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @return *T: The component, nil if the entity has no component of the type.
+// @return bool: True if the entity has a component of the type.
+func Get[T any](world World, entity Entity) (*T, bool) {
+	storage := storageOf[T](world, false)
+	if storage == nil {
+		return nil, false
+	}
+	component, ok := storage.components[entity]
+	return component, ok
+}
+
+// Has tells whether an entity has a component of a type.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @return bool: True if the entity has a component of the type.
+func Has[T any](world World, entity Entity) bool {
+	storage := storageOf[T](world, false)
+	return storage != nil && storage.has(entity)
+}
+
+// Remove removes the component of a type from an entity.
+// @param world World: The world.
+// @param entity Entity: The entity.
+// @return error: Returns an error if the entity has no component of the type.
+func Remove[T any](world World, entity Entity) error {
+	storage := storageOf[T](world, false)
+	if storage == nil || !storage.remove(entity) {
+		return fmt.Errorf("ecs error: entity %d has no %s component", entity, componentType[T]())
+	}
+	return nil
+}
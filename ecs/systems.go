@@ -0,0 +1,133 @@
+package ecs
+
+import (
+	"Game_Engine/collision"
+	"Game_Engine/spatial"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"time"
+)
+
+// System is a part of the game logic which runs at every World.Update, usually over the entities of a query.
+type System interface {
+	// Update runs the system once.
+	// @param world World: The world.
+	// @param dt time.Duration: The duration of the step.
+	// @return error: An error stops World.Update.
+	Update(world World, dt time.Duration) error
+}
+
+// SystemFunc is a function used as a System.
+type SystemFunc func(world World, dt time.Duration) error
+
+// Update calls the function.
+// @param world World: The world.
+// @param dt time.Duration: The duration of the step.
+// @return error: Returns the error of the function.
+func (systemFunc SystemFunc) Update(world World, dt time.Duration) error {
+	return systemFunc(world, dt)
+}
+
+// NewMovementSystem creates a system which moves the Transform of the entities by their Velocity.
+// @return System: The system.
+func NewMovementSystem() System {
+	return SystemFunc(func(world World, dt time.Duration) error {
+		return Each2(world, func(entity Entity, transform *Transform, velocity *Velocity) error {
+			if transform.TransformableObject == nil {
+				return fmt.Errorf("ecs error: entity %d has an empty transform", entity)
+			}
+			return transform.TranslateBy(velocity.X*dt.Seconds(), velocity.Y*dt.Seconds())
+		})
+	})
+}
+
+// ContactHandler is called by the collision system for every pair of overlapping entities.
+// @param world World: The world.
+// @param a, b Entity: The entities, a is less than b.
+// @param contact collision.Contact: The contact, moving b by Normal*Depth separates the entities.
+// @return error: An error stops the collision system.
+type ContactHandler func(world World, a, b Entity, contact collision.Contact) error
+
+// collisionSystem is a System which finds the overlapping Colliders.
+type collisionSystem struct {
+	cellSize  int            // The cell size of the broad-phase grid.
+	onContact ContactHandler // The function called for every overlapping pair.
+}
+
+// NewCollisionSystem creates a system which tests the Colliders of all entities against each other.
+// The candidates are found with a grid of the spatial package, then tested exactly with the collision package.
+// @param cellSize int: The cell size of the grid in pixels, about the size of the colliders.
+// @param onContact ContactHandler: The function called for every overlapping pair.
+// @return System: The system.
+// @return error: Returns an error if the cell size is not positive or onContact is nil.
+func NewCollisionSystem(cellSize int, onContact ContactHandler) (System, error) {
+	if cellSize <= 0 {
+		return nil, errors.New("ecs error: cell size must be positive")
+	}
+	if onContact == nil {
+		return nil, errors.New("ecs error: contact handler is nil")
+	}
+	return &collisionSystem{cellSize: cellSize, onContact: onContact}, nil
+}
+
+// Update tests the Colliders and calls the contact handler, pairs ordered by their entities.
+// Pairs with an entity destroyed or without Collider by an earlier call are skipped.
+// @param world World: The world.
+// @param dt time.Duration: The duration of the step, not used.
+// @return error: Returns the first error of the contact handler.
+func (collisionSystem *collisionSystem) Update(world World, dt time.Duration) error {
+	index, err := spatial.NewGrid(collisionSystem.cellSize)
+	if err != nil {
+		return err
+	}
+	shapes := map[Entity]collision.Shape{}
+	err = Each(world, func(entity Entity, collider *Collider) error {
+		if collider.Shape == nil {
+			return nil
+		}
+		shape := collider.Shape()
+		if shape == nil {
+			return nil
+		}
+		bounds := shape.Bounds()
+		shapes[entity] = shape
+		return index.Insert(int(entity), image.Rect(int(math.Floor(bounds.Min.X)), int(math.Floor(bounds.Min.Y)),
+			int(math.Ceil(bounds.Max.X))+1, int(math.Ceil(bounds.Max.Y))+1))
+	})
+	if err != nil {
+		return err
+	}
+	for _, pair := range index.Pairs() {
+		a, b := Entity(pair[0]), Entity(pair[1])
+		contact, ok := collision.Collide(shapes[a], shapes[b])
+		if !ok || !Has[Collider](world, a) || !Has[Collider](world, b) {
+			continue
+		}
+		if err := collisionSystem.onContact(world, a, b, contact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draw draws the Shape and the Sprite of every entity, by ascending entity, a Shape before a Sprite of the same entity.
+// It is called from Draw of the game, the systems run in its steps.
+// @param world World: The world.
+// @return error: Returns the first error of a drawing.
+func Draw(world World) error {
+	for _, entity := range Query(world) {
+		if shape, ok := Get[Shape](world, entity); ok {
+			if err := shape.Draw(); err != nil {
+				return err
+			}
+		}
+		if sprite, ok := Get[Sprite](world, entity); ok {
+			if err := sprite.Draw(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package ecs store the entity component system of the engine: entities are identifiers, their data are typed
+// components and the game logic runs in ordered systems which query the entities having a set of components
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Entity identifies an object of a World. Identifiers start at 1 and are never reused,
+// so a destroyed entity can not be confused with a newer one.
+type Entity int
+
+// NoEntity is the zero Entity, no entity of a world has it.
+const NoEntity Entity = 0
+
+// World holds the entities, their components and the systems updating them.
+// Components are stored by type with the generic functions Add, Get, Has and Remove, a type is registered
+// by adding its first component. Components are plain values, for example Velocity, or wrap the existing
+// objects, for example Shape, so a shape stays usable by the scene while it is a component.
+type World interface {
+	// NewEntity creates an entity without components.
+	// @return Entity: The new entity.
+	NewEntity() Entity
+
+	// Destroy removes an entity together with all its components.
+	// @param entity Entity: The entity to remove.
+	// @return error: Returns an error if the entity does not exist.
+	Destroy(entity Entity) error
+
+	// Alive tells whether an entity exists.
+	// @param entity Entity: The entity.
+	// @return bool: True if the entity was created and not destroyed.
+	Alive(entity Entity) bool
+
+	// Entities returns all entities.
+	// @return []Entity: The entities in ascending order.
+	Entities() []Entity
+
+	// Len returns the number of entities.
+	// @return int: The number of entities.
+	Len() int
+
+	// AddSystem adds a system which runs at every Update.
+	// Systems run by ascending order, systems of the same order in the order they were added.
+	// @param name string: The name of the system, used by RemoveSystem and in errors.
+	// @param order int: The position of the system, for example input 0, movement 10, collisions 20.
+	// @param system System: The system.
+	// @return error: Returns an error if the name is empty or already used, or the system is nil.
+	AddSystem(name string, order int, system System) error
+
+	// RemoveSystem removes a system.
+	// @param name string: The name of the system.
+	// @return error: Returns an error if there is no system with the name.
+	RemoveSystem(name string) error
+
+	// GetSystems returns the names of the systems.
+	// @return []string: The names in the order the systems run.
+	GetSystems() []string
+
+	// Update runs all systems once.
+	// @param dt time.Duration: The duration of the step, usually the fixed step of the game loop.
+	// @return error: Returns the first error of a system, the following systems do not run.
+	Update(dt time.Duration) error
+
+	// storeOf returns the storage of a component type.
+	// @param componentType reflect.Type: The type of the components.
+	// @return componentStore: The storage, nil if no component of the type was added yet.
+	storeOf(componentType reflect.Type) componentStore
+
+	// addStore registers the storage of a component type.
+	// @param componentType reflect.Type: The type of the components.
+	// @param store componentStore: The storage.
+	addStore(componentType reflect.Type, store componentStore)
+}
+
+// systemEntry stores a system of a world.
+type systemEntry struct {
+	name   string // The name of the system.
+	order  int    // The position of the system.
+	system System // The system.
+}
+
+// world is an internal implementation of the World interface.
+type world struct {
+	next     Entity                          // The identifier of the next entity.
+	entities map[Entity]bool                 // The existing entities.
+	stores   map[reflect.Type]componentStore // The storage of each component type.
+	systems  []systemEntry                   // The systems in the order they run.
+}
+
+// NewWorld creates an empty world.
+// @return World: The world.
+func NewWorld() World {
+	return &world{
+		next:     1,
+		entities: map[Entity]bool{},
+		stores:   map[reflect.Type]componentStore{},
+		systems:  nil,
+	}
+}
+
+// NewEntity creates an entity without components.
+// @return Entity: The new entity.
+func (world *world) NewEntity() Entity {
+	entity := world.next
+	world.next++
+	world.entities[entity] = true
+	return entity
+}
+
+// Destroy removes an entity together with all its components.
+// @param entity Entity: The entity to remove.
+// @return error: Returns an error if the entity does not exist.
+func (world *world) Destroy(entity Entity) error {
+	if !world.entities[entity] {
+		return fmt.Errorf("ecs error: entity %d does not exist", entity)
+	}
+	for _, store := range world.stores {
+		store.remove(entity)
+	}
+	delete(world.entities, entity)
+	return nil
+}
+
+// Alive tells whether an entity exists.
+// @param entity Entity: The entity.
+// @return bool: True if the entity exists.
+func (world *world) Alive(entity Entity) bool {
+	return world.entities[entity]
+}
+
+// Entities returns all entities.
+// @return []Entity: The entities in ascending order.
+func (world *world) Entities() []Entity {
+	entities := make([]Entity, 0, len(world.entities))
+	for entity := range world.entities {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
+
+// Len returns the number of entities.
+// @return int: The number of entities.
+func (world *world) Len() int {
+	return len(world.entities)
+}
+
+// AddSystem adds a system which runs at every Update.
+// @param name string: The name of the system.
+// @param order int: The position of the system.
+// @param system System: The system.
+// @return error: Returns an error if the name is empty or already used, or the system is nil.
+func (world *world) AddSystem(name string, order int, system System) error {
+	if name == "" {
+		return errors.New("ecs error: system without name")
+	}
+	if system == nil {
+		return fmt.Errorf("ecs error: system %q is nil", name)
+	}
+	for _, entry := range world.systems {
+		if entry.name == name {
+			return fmt.Errorf("ecs error: system %q already exists", name)
+		}
+	}
+	world.systems = append(world.systems, systemEntry{name: name, order: order, system: system})
+	sort.SliceStable(world.systems, func(i, j int) bool { return world.systems[i].order < world.systems[j].order })
+	return nil
+}
+
+// RemoveSystem removes a system.
+// @param name string: The name of the system.
+// @return error: Returns an error if there is no system with the name.
+func (world *world) RemoveSystem(name string) error {
+	for i, entry := range world.systems {
+		if entry.name == name {
+			world.systems = append(world.systems[:i], world.systems[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("ecs error: system %q does not exist", name)
+}
+
+// GetSystems returns the names of the systems.
+// @return []string: The names in the order the systems run.
+func (world *world) GetSystems() []string {
+	names := make([]string, len(world.systems))
+	for i, entry := range world.systems {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// Update runs all systems once.
+// Systems added or removed by a system take effect at the next Update.
+// @param dt time.Duration: The duration of the step.
+// @return error: Returns the first error of a system.
+func (world *world) Update(dt time.Duration) error {
+	for _, entry := range append([]systemEntry(nil), world.systems...) {
+		if err := entry.system.Update(world, dt); err != nil {
+			return fmt.Errorf("%w in system %q", err, entry.name)
+		}
+	}
+	return nil
+}
+
+// storeOf returns the storage of a component type.
+// @param componentType reflect.Type: The type of the components.
+// @return componentStore: The storage or nil.
+func (world *world) storeOf(componentType reflect.Type) componentStore {
+	return world.stores[componentType]
+}
+
+// addStore registers the storage of a component type.
+// @param componentType reflect.Type: The type of the components.
+// @param store componentStore: The storage.
+func (world *world) addStore(componentType reflect.Type, store componentStore) {
+	world.stores[componentType] = store
+}
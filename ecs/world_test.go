@@ -0,0 +1,141 @@
+package ecs
+
+import (
+	"Game_Engine/collision"
+	"Game_Engine/objects"
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorldComponentsAndQueries(t *testing.T) {
+	world := NewWorld()
+	moving, still, ghost := world.NewEntity(), world.NewEntity(), world.NewEntity()
+	for _, entity := range []Entity{moving, still, ghost} {
+		if err := Add(world, entity, NewTransform(float64(entity), 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Add(world, moving, Velocity{X: 60})
+	Add(world, ghost, Velocity{Y: 30})
+	Add(world, ghost, NewCollider(collision.NewCircle(0, 0, 1)))
+
+	if got, want := Query(world, With[Velocity]()), []Entity{moving, ghost}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entities with velocity = %v, want %v", got, want)
+	}
+	if got, want := Query(world, With[Transform](), Without[Collider]()), []Entity{moving, still}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entities without collider = %v, want %v", got, want)
+	}
+	if got := Query(world, With[Shape]()); len(got) != 0 {
+		t.Errorf("entities with an unused component type = %v, want none", got)
+	}
+
+	velocity, _ := Get[Velocity](world, moving)
+	velocity.X = 120
+	if got, _ := Get[Velocity](world, moving); got.X != 120 {
+		t.Error("changes through Get must change the stored component")
+	}
+	if err := Remove[Velocity](world, still); err == nil {
+		t.Error("expected an error removing a missing component")
+	}
+	if err := world.Destroy(ghost); err != nil {
+		t.Fatal(err)
+	}
+	if Has[Velocity](world, ghost) || Has[Collider](world, ghost) || world.Alive(ghost) {
+		t.Error("components of a destroyed entity must be removed")
+	}
+	if err := Add(world, ghost, Velocity{}); err == nil {
+		t.Error("expected an error adding a component to a destroyed entity")
+	}
+	if entity := world.NewEntity(); entity == ghost {
+		t.Error("identifiers must not be reused")
+	}
+}
+
+func TestWorldSystemOrder(t *testing.T) {
+	world := NewWorld()
+	var ran []string
+	record := func(name string) System {
+		return SystemFunc(func(world World, dt time.Duration) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+	world.AddSystem("render", 30, record("render"))
+	world.AddSystem("input", 0, record("input"))
+	world.AddSystem("movement", 10, record("movement"))
+	world.AddSystem("physics", 10, record("physics"))
+	if err := world.AddSystem("input", 5, record("input")); err == nil {
+		t.Error("expected an error for a duplicated system name")
+	}
+	want := []string{"input", "movement", "physics", "render"}
+	if got := world.GetSystems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("systems = %v, want %v", got, want)
+	}
+	if err := world.Update(time.Second / 60); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("systems ran in order %v, want %v", ran, want)
+	}
+
+	world.RemoveSystem("render")
+	world.AddSystem("broken", 20, SystemFunc(func(world World, dt time.Duration) error {
+		return Remove[Velocity](world, 1)
+	}))
+	if err := world.Update(time.Second / 60); err == nil || !strings.Contains(err.Error(), `in system "broken"`) {
+		t.Errorf("error = %v, want it to name the failing system", err)
+	}
+}
+
+func TestWrappedShapesMoveAndCollide(t *testing.T) {
+	canvas := objects.NewImageCanvas(64, 64)
+	background, line := color.RGBA{0, 0, 0, 255}, color.RGBA{150, 100, 200, 255}
+	square := objects.EnhancedNewSquareObject(canvas, background, 10, 0, 0, line)
+	circle := objects.EnhancedNewCircleObject(canvas, background, 40, 5, 4, line)
+
+	world := NewWorld()
+	player, target := world.NewEntity(), world.NewEntity()
+	if err := AddShape(world, player, square); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddShape(world, target, circle); err != nil {
+		t.Fatal(err)
+	}
+	Add(world, player, Velocity{X: 60})
+	var contacts [][2]Entity
+	collisions, err := NewCollisionSystem(16, func(world World, a, b Entity, contact collision.Contact) error {
+		contacts = append(contacts, [2]Entity{a, b})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	world.AddSystem("collisions", 20, collisions)
+	world.AddSystem("movement", 10, NewMovementSystem())
+
+	// The square moves 20 pixels in 20 steps, its right edge at 30 does not reach the circle from 36 to 44.
+	for step := 0; step < 20; step++ {
+		if err := world.Update(time.Second / 60); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := square.GetShapeObject().GetTransformableObject().GetTranslationX(); math.Abs(got-20) > 1e-6 {
+		t.Errorf("wrapped square moved by %v, want 20", got)
+	}
+	if len(contacts) != 0 {
+		t.Fatalf("contacts before touching = %v", contacts)
+	}
+	for step := 0; step < 10; step++ {
+		world.Update(time.Second / 60)
+	}
+	if len(contacts) == 0 || contacts[0] != [2]Entity{player, target} {
+		t.Errorf("contacts = %v, want the square and the circle", contacts)
+	}
+	if err := Draw(world); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package game store the game logic of one step, shared by the window, the replay and the headless runner,
+// so a recorded session can be replayed into it and its resulting state be checked in tests
+package game
+
+import (
+	"Game_Engine/input"
+	"Game_Engine/objects"
+	"math"
+	"time"
+)
+
+// Movement of the game in units per second, so the game runs at the same speed with every TPS and step
+const (
+	MinSpeed      = 60.0   // Slowest speed of the tank and the player in pixels per second
+	SpeedChange   = 3600.0 // Change of the speed in pixels per second per second while speed_up or slow_down is held
+	RotationSpeed = 60.0   // Rotation of the tank in degrees per second while rotate is held
+)
+
+// State is the state of the game which changes in the steps. The previous values are the ones
+// before the last step, Draw interpolates between them and the current ones.
+type State struct {
+	X, Y                 float64              // Translation of the tank and position of the player.
+	PreviousX, PreviousY float64              // Translation before the last step.
+	Speed                float64              // Speed of the movement in pixels per second.
+	Angle                float64              // Rotation of the tank in degrees.
+	PreviousAngle        float64              // Rotation before the last step.
+	Player               objects.PlayerObject // The player, which walks to the translation and stops at its obstacles.
+}
+
+// NewState creates the state at the start of the game.
+// @param player objects.PlayerObject: The player, its animation clips and obstacles are set by the caller.
+// @return *State: The state with the slowest speed.
+func NewState(player objects.PlayerObject) *State {
+	return &State{Speed: MinSpeed, Player: player}
+}
+
+// Step runs the game logic of one step with the actions sampled for this step.
+// @param actions input.ActionMap: The actions, updated for this step.
+// @param dt time.Duration: The duration of the step.
+// @return error: Returns an error if the player can not be updated.
+func (state *State) Step(actions input.ActionMap, dt time.Duration) error {
+	seconds := dt.Seconds()
+	state.PreviousX, state.PreviousY, state.PreviousAngle = state.X, state.Y, state.Angle
+	moveX, moveY := actions.Axis("move_x"), actions.Axis("move_y")
+	state.X = state.X + moveX*state.Speed*seconds
+	state.Y = state.Y + moveY*state.Speed*seconds
+	if actions.Pressed("slow_down") {
+		state.Speed = math.Max(MinSpeed, state.Speed-SpeedChange*seconds)
+	}
+	if actions.Pressed("speed_up") {
+		state.Speed = state.Speed + SpeedChange*seconds
+	}
+	if actions.Pressed("rotate") {
+		if state.Angle >= 360 {
+			state.Angle, state.PreviousAngle = state.Angle-360, state.PreviousAngle-360
+		}
+		state.Angle = state.Angle + RotationSpeed*seconds
+	}
+
+	animationInput := objects.AnimationInput{MoveX: moveX, MoveY: moveY, Attack: actions.JustPressed("attack")}
+	x, y := int(math.Round(state.X)), int(math.Round(state.Y))
+	if err := state.Player.Update(animationInput, x, y, dt); err != nil {
+		return err
+	}
+	// Keep the position where the player was stopped by an obstacle
+	if stoppedX, stoppedY := state.PlayerCords(); stoppedX != x || stoppedY != y {
+		state.X, state.Y = float64(stoppedX), float64(stoppedY)
+	}
+	return nil
+}
+
+// PlayerCords returns the position of the player after the last step.
+// @return (int, int): The position of the player.
+func (state *State) PlayerCords() (int, int) {
+	return state.Player.GetSpriteObject().GetBitmapObject().GetBitmapHandler(0).GetCords()
+}
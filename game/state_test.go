@@ -0,0 +1,99 @@
+package game
+
+import (
+	"Game_Engine/collision"
+	"Game_Engine/input"
+	"Game_Engine/objects"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// playerStep is what the player shows after a step.
+type playerStep struct {
+	animation string // The state of the animation state machine.
+	frame     string // The name of the current frame.
+}
+
+// replay runs the recording of a diagonal walk and an attack through the steps of a new state with the hero as the player.
+// @param t *testing.T: The test.
+// @param obstacles ...collision.Shape: The obstacles of the player.
+// @return *State: The state after the last step.
+// @return []playerStep: What the player showed after every step.
+func replay(t *testing.T, obstacles ...collision.Shape) (*State, []playerStep) {
+	t.Helper()
+	recording, err := input.LoadRecording("testdata/diagonal.rec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := objects.NewPlayerObject(nil, color.Black, color.White, 0, 0)
+	if err := player.LoadHeroAtlas("../Hero/hero.json"); err != nil {
+		t.Fatal(err)
+	}
+	// The atlas has no attack tag, two of its frames stand in for one
+	if err := player.SetAttack([]int{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SetObstacles(obstacles); err != nil {
+		t.Fatal(err)
+	}
+	state := NewState(player)
+	actions, _ := input.NewActionMap(input.NewMockDevice(), input.NewBindings())
+	var steps []playerStep
+	err = input.RunHeadless(recording, actions, func(dt time.Duration) error {
+		if err := state.Step(actions, dt); err != nil {
+			return err
+		}
+		frame, _ := player.GetSpriteObject().GetCurrentName()
+		steps = append(steps, playerStep{animation: player.GetAnimationStateMachine().GetState(), frame: frame})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != recording.Ticks {
+		t.Fatalf("replayed %d steps, want the %d ticks of the recording", len(steps), recording.Ticks)
+	}
+	return state, steps
+}
+
+// TestReplayDiagonalWalk replays walking diagonally, which once froze the sprite of the player, and an attack.
+func TestReplayDiagonalWalk(t *testing.T) {
+	state, steps := replay(t)
+	// 60 steps of 1/60 s at the slowest speed of 60 pixels per second in both directions
+	if x, y := state.PlayerCords(); x != 60 || y != 60 {
+		t.Errorf("player is at (%d, %d), want (60, 60)", x, y)
+	}
+	if state.Speed != MinSpeed || state.Angle != 0 {
+		t.Errorf("speed %v and angle %v changed without their actions", state.Speed, state.Angle)
+	}
+	frames := map[string]bool{}
+	for _, step := range steps[:60] {
+		frames[step.frame] = true
+	}
+	if len(frames) < 2 {
+		t.Errorf("walking diagonally only showed the frames %v", frames)
+	}
+	attacks := 0
+	for tick := 1; tick < len(steps); tick++ {
+		if steps[tick].animation == objects.ClipAttack && steps[tick-1].animation != objects.ClipAttack {
+			attacks++
+		}
+	}
+	if attacks != 1 {
+		t.Errorf("the player attacked %d times, want 1", attacks)
+	}
+}
+
+// TestReplayStopsAtObstacle replays the diagonal walk towards a wall, the translation stays where the player stopped.
+func TestReplayStopsAtObstacle(t *testing.T) {
+	state, _ := replay(t, collision.NewAABB(220, -1000, 1000, 1000))
+	x, y := state.PlayerCords()
+	if float64(x) != state.X || float64(y) != state.Y {
+		t.Errorf("translation (%v, %v) differs from the player at (%d, %d)", state.X, state.Y, x, y)
+	}
+	// The player is 192 pixels wide, it stops touching the wall and keeps walking down
+	if x != 28 || y != 60 {
+		t.Errorf("player is at (%d, %d), want (28, 60)", x, y)
+	}
+}
@@ -2,7 +2,10 @@ module Game_Engine
 
 go 1.23.2
 
-require github.com/hajimehoshi/ebiten/v2 v2.8.1
+require (
+	github.com/hajimehoshi/ebiten/v2 v2.8.1
+	golang.org/x/image v0.25.0
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240911145611-4856209ac325 // indirect
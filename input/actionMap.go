@@ -0,0 +1,381 @@
+package input
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PressThreshold is the value from which an input presses an action, it matters for half axes bound to actions.
+const PressThreshold = 0.5
+
+// ActionMap turns the physical inputs of a Device into the named actions and axes of the game.
+// The device is sampled once per tick by Update, so every query during the tick sees the same state
+// and the game logic does not depend on which keys, buttons or sticks are bound.
+type ActionMap interface {
+	// Update samples the device and advances the hold durations, it must be called once per tick.
+	// @param dt time.Duration: The time elapsed since the previous update.
+	Update(dt time.Duration)
+
+	// Pressed tells whether an action is held down.
+	// @param action string: The name of the action.
+	// @return bool: True while any input of the action is pressed, false for unknown actions.
+	Pressed(action string) bool
+
+	// JustPressed tells whether an action was pressed in the last tick.
+	// @param action string: The name of the action.
+	// @return bool: True only in the tick the action got pressed.
+	JustPressed(action string) bool
+
+	// JustReleased tells whether an action was released in the last tick.
+	// @param action string: The name of the action.
+	// @return bool: True only in the tick the action got released.
+	JustReleased(action string) bool
+
+	// HoldDuration returns how long an action is held, for example to charge an attack.
+	// @param action string: The name of the action.
+	// @return time.Duration: While pressed the time held including the tick of the press, the whole time held in the
+	// tick of the release, otherwise 0.
+	HoldDuration(action string) time.Duration
+
+	// Axis returns the value of a named axis, the sum of its inputs multiplied by their factors.
+	// @param axis string: The name of the axis.
+	// @return float64: The value from -1 to 1, 0 for unknown axes.
+	Axis(axis string) float64
+
+	// SetBindings replaces the bindings, the state of actions which stay bound is kept.
+	// @param bindings Bindings: The new bindings.
+	// @return error: Returns an error if the bindings are invalid.
+	SetBindings(bindings Bindings) error
+
+	// GetBindings returns a copy of the bindings, for example to save them after a rebinding.
+	// @return Bindings: The bindings.
+	GetBindings() Bindings
+
+	// Bind adds an input to an action, creating the action if needed.
+	// @param action string: The name of the action.
+	// @param source Source: The input.
+	// @return error: Returns an error if the name is empty or the source invalid.
+	Bind(action string, source Source) error
+
+	// Unbind removes an input from an action.
+	// @param action string: The name of the action.
+	// @param source Source: The input.
+	// @return error: Returns an error if the input is not bound to the action.
+	Unbind(action string, source Source) error
+
+	// BindAxis sets the factor of an input of an axis, creating the axis if needed.
+	// @param axis string: The name of the axis.
+	// @param source Source: The input.
+	// @param scale float64: The factor, usually 1 or -1, 0 removes the input from the axis.
+	// @return error: Returns an error if the name is empty, the source invalid or the factor not finite.
+	BindAxis(axis string, source Source, scale float64) error
+
+	// SetDevice replaces the device which is sampled by Update.
+	// @param device Device: The device.
+	// @return error: Returns an error if the device is nil.
+	SetDevice(device Device) error
+
+	// GetDevice returns the device which is sampled by Update.
+	// @return Device: The device.
+	GetDevice() Device
+}
+
+// actionState is the state of one action.
+type actionState struct {
+	pressed  bool          // True if the action is pressed in this tick.
+	previous bool          // True if the action was pressed in the previous tick.
+	held     time.Duration // The time the action is held.
+}
+
+// actionMap is an internal implementation of the ActionMap interface.
+type actionMap struct {
+	device   Device                  // The device sampled by Update.
+	bindings Bindings                // The inputs of the actions and axes.
+	actions  map[string]*actionState // The states of the actions.
+	axes     map[string]float64      // The values of the axes sampled by the last Update.
+	order    map[string][]Source     // The inputs of each axis in a fixed order, so the sums are deterministic.
+}
+
+// NewActionMap creates an action map with every action released.
+// @param device Device: The device to read, NewEbitenDevice for the real devices or a MockDevice in tests.
+// @param bindings Bindings: The inputs of the actions and axes.
+// @return ActionMap: The action map.
+// @return error: Returns an error if the device is nil or the bindings are invalid.
+func NewActionMap(device Device, bindings Bindings) (ActionMap, error) {
+	if device == nil {
+		return nil, errors.New("input error: device is nil")
+	}
+	actionMap := &actionMap{
+		device:  device,
+		actions: map[string]*actionState{},
+		axes:    map[string]float64{},
+		order:   map[string][]Source{},
+	}
+	if err := actionMap.SetBindings(bindings); err != nil {
+		return nil, err
+	}
+	return actionMap, nil
+}
+
+// Update samples the device and advances the hold durations.
+// @param dt time.Duration: The time elapsed since the previous update.
+func (actionMap *actionMap) Update(dt time.Duration) {
+	for action, state := range actionMap.actions {
+		state.previous = state.pressed
+		state.pressed = false
+		for _, source := range actionMap.bindings.Actions[action] {
+			if actionMap.value(source) >= PressThreshold {
+				state.pressed = true
+				break
+			}
+		}
+		switch {
+		case state.pressed && !state.previous:
+			state.held = dt
+		case state.pressed:
+			state.held += dt
+		case !state.previous:
+			state.held = 0
+		}
+	}
+	for axis, sources := range actionMap.bindings.Axes {
+		sum := 0.0
+		for _, source := range actionMap.order[axis] {
+			sum += actionMap.value(source) * sources[source]
+		}
+		actionMap.axes[axis] = math.Max(-1, math.Min(1, sum))
+	}
+}
+
+// value reads an input from the device, applying the dead zone and the direction of half axes.
+// @param source Source: The input.
+// @return float64: The value of the input.
+func (actionMap *actionMap) value(source Source) float64 {
+	value := actionMap.device.Value(source)
+	if source.Kind != SourceGamepadAxis {
+		return value
+	}
+	if math.Abs(value) < actionMap.bindings.DeadZone {
+		return 0
+	}
+	if source.Direction != 0 {
+		return math.Max(0, value*float64(source.Direction))
+	}
+	return value
+}
+
+// Pressed tells whether an action is held down.
+// @param action string: The name of the action.
+// @return bool: True while any input of the action is pressed.
+func (actionMap *actionMap) Pressed(action string) bool {
+	state, ok := actionMap.actions[action]
+	return ok && state.pressed
+}
+
+// JustPressed tells whether an action was pressed in the last tick.
+// @param action string: The name of the action.
+// @return bool: True only in the tick the action got pressed.
+func (actionMap *actionMap) JustPressed(action string) bool {
+	state, ok := actionMap.actions[action]
+	return ok && state.pressed && !state.previous
+}
+
+// JustReleased tells whether an action was released in the last tick.
+// @param action string: The name of the action.
+// @return bool: True only in the tick the action got released.
+func (actionMap *actionMap) JustReleased(action string) bool {
+	state, ok := actionMap.actions[action]
+	return ok && !state.pressed && state.previous
+}
+
+// HoldDuration returns how long an action is held.
+// @param action string: The name of the action.
+// @return time.Duration: The time held, 0 for unknown actions.
+func (actionMap *actionMap) HoldDuration(action string) time.Duration {
+	state, ok := actionMap.actions[action]
+	if !ok {
+		return 0
+	}
+	return state.held
+}
+
+// Axis returns the value of a named axis.
+// @param axis string: The name of the axis.
+// @return float64: The value from -1 to 1.
+func (actionMap *actionMap) Axis(axis string) float64 {
+	return actionMap.axes[axis]
+}
+
+// SetBindings replaces the bindings.
+// @param bindings Bindings: The new bindings.
+// @return error: Returns an error if the bindings are invalid.
+func (actionMap *actionMap) SetBindings(bindings Bindings) error {
+	if err := bindings.Validate(); err != nil {
+		return err
+	}
+	actionMap.bindings = bindings.clone()
+	for action := range actionMap.actions {
+		if _, ok := actionMap.bindings.Actions[action]; !ok {
+			delete(actionMap.actions, action)
+		}
+	}
+	for action := range actionMap.bindings.Actions {
+		if _, ok := actionMap.actions[action]; !ok {
+			actionMap.actions[action] = &actionState{}
+		}
+	}
+	for axis := range actionMap.axes {
+		if _, ok := actionMap.bindings.Axes[axis]; !ok {
+			delete(actionMap.axes, axis)
+		}
+	}
+	clear(actionMap.order)
+	for axis := range actionMap.bindings.Axes {
+		actionMap.sortAxis(axis)
+	}
+	return nil
+}
+
+// sortAxis orders the inputs of an axis by kind, code and direction.
+// Floating point additions depend on their order, so a replay only gives the same axis values with a fixed order.
+// @param axis string: The name of the axis.
+func (actionMap *actionMap) sortAxis(axis string) {
+	sources := make([]Source, 0, len(actionMap.bindings.Axes[axis]))
+	for source := range actionMap.bindings.Axes[axis] {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Kind != sources[j].Kind {
+			return sources[i].Kind < sources[j].Kind
+		}
+		if sources[i].Code != sources[j].Code {
+			return sources[i].Code < sources[j].Code
+		}
+		return sources[i].Direction < sources[j].Direction
+	})
+	actionMap.order[axis] = sources
+}
+
+// GetBindings returns a copy of the bindings.
+// @return Bindings: The bindings.
+func (actionMap *actionMap) GetBindings() Bindings {
+	return actionMap.bindings.clone()
+}
+
+// Bind adds an input to an action.
+// @param action string: The name of the action.
+// @param source Source: The input.
+// @return error: Returns an error if the name is empty or the source invalid.
+func (actionMap *actionMap) Bind(action string, source Source) error {
+	if action == "" {
+		return errors.New("input error: action without name")
+	}
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	for _, bound := range actionMap.bindings.Actions[action] {
+		if bound == source {
+			return nil
+		}
+	}
+	actionMap.bindings.Actions[action] = append(actionMap.bindings.Actions[action], source)
+	if _, ok := actionMap.actions[action]; !ok {
+		actionMap.actions[action] = &actionState{}
+	}
+	return nil
+}
+
+// Unbind removes an input from an action, the action stays known even without inputs.
+// @param action string: The name of the action.
+// @param source Source: The input.
+// @return error: Returns an error if the input is not bound to the action.
+func (actionMap *actionMap) Unbind(action string, source Source) error {
+	sources := actionMap.bindings.Actions[action]
+	for i, bound := range sources {
+		if bound == source {
+			actionMap.bindings.Actions[action] = append(sources[:i:i], sources[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("input error: source is not bound to the action")
+}
+
+// BindAxis sets the factor of an input of an axis.
+// @param axis string: The name of the axis.
+// @param source Source: The input.
+// @param scale float64: The factor, 0 removes the input from the axis.
+// @return error: Returns an error if the name is empty, the source invalid or the factor not finite.
+func (actionMap *actionMap) BindAxis(axis string, source Source, scale float64) error {
+	if axis == "" {
+		return errors.New("input error: axis without name")
+	}
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	if math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return errors.New("input error: axis factor must be finite")
+	}
+	if scale == 0 {
+		delete(actionMap.bindings.Axes[axis], source)
+	} else {
+		if actionMap.bindings.Axes[axis] == nil {
+			actionMap.bindings.Axes[axis] = map[Source]float64{}
+		}
+		actionMap.bindings.Axes[axis][source] = scale
+	}
+	actionMap.sortAxis(axis)
+	return nil
+}
+
+// SetDevice replaces the device which is sampled by Update.
+// @param device Device: The device.
+// @return error: Returns an error if the device is nil.
+func (actionMap *actionMap) SetDevice(device Device) error {
+	if device == nil {
+		return errors.New("input error: device is nil")
+	}
+	actionMap.device = device
+	return nil
+}
+
+// GetDevice returns the device which is sampled by Update.
+// @return Device: The device.
+func (actionMap *actionMap) GetDevice() Device {
+	return actionMap.device
+}
+
+// Capture returns the first input which is pressed on a device, to rebind an action to the next input of the player.
+// Axes count in the direction they are pushed, beyond PressThreshold.
+// @param device Device: The device.
+// @return Source: The pressed input.
+// @return bool: False if no input is pressed.
+func Capture(device Device) (Source, bool) {
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if device.Value(Key(key)) >= PressThreshold {
+			return Key(key), true
+		}
+	}
+	for button := ebiten.MouseButton(0); button <= ebiten.MouseButtonMax; button++ {
+		if device.Value(MouseButton(button)) >= PressThreshold {
+			return MouseButton(button), true
+		}
+	}
+	for button := ebiten.StandardGamepadButton(0); button <= ebiten.StandardGamepadButtonMax; button++ {
+		if device.Value(GamepadButton(button)) >= PressThreshold {
+			return GamepadButton(button), true
+		}
+	}
+	for axis := ebiten.StandardGamepadAxis(0); axis <= ebiten.StandardGamepadAxisMax; axis++ {
+		if value := device.Value(GamepadAxis(axis, 0)); math.Abs(value) >= PressThreshold {
+			if value > 0 {
+				return GamepadAxis(axis, 1), true
+			}
+			return GamepadAxis(axis, -1), true
+		}
+	}
+	return Source{}, false
+}
@@ -0,0 +1,135 @@
+package input
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const tick = time.Second / 60
+
+func TestActionMapActions(t *testing.T) {
+	bindings, err := ParseBindings([]byte(`{
+		"actions": {"attack": ["key:Space", "mouse:left", "axis:RightStickHorizontal+"]},
+		"axes": {"move_x": {"key:ArrowLeft": -1, "key:ArrowRight": 1, "axis:LeftStickHorizontal": 1}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := NewMockDevice()
+	actions, err := NewActionMap(device, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device.Set(Key(ebiten.KeySpace), 1)
+	actions.Update(tick)
+	if !actions.Pressed("attack") || !actions.JustPressed("attack") || actions.HoldDuration("attack") != tick {
+		t.Errorf("attack after the press: pressed %v, just pressed %v, held %v", actions.Pressed("attack"), actions.JustPressed("attack"), actions.HoldDuration("attack"))
+	}
+	actions.Update(tick)
+	if actions.JustPressed("attack") || actions.HoldDuration("attack") != 2*tick {
+		t.Errorf("attack while held: just pressed %v, held %v", actions.JustPressed("attack"), actions.HoldDuration("attack"))
+	}
+	device.Reset()
+	actions.Update(tick)
+	if actions.Pressed("attack") || !actions.JustReleased("attack") || actions.HoldDuration("attack") != 2*tick {
+		t.Errorf("attack in the tick of the release: released %v, held %v", actions.JustReleased("attack"), actions.HoldDuration("attack"))
+	}
+	actions.Update(tick)
+	if actions.JustReleased("attack") || actions.HoldDuration("attack") != 0 {
+		t.Error("attack was not forgotten after the release")
+	}
+
+	// Only the positive half of the stick presses the attack.
+	device.Set(GamepadAxis(ebiten.StandardGamepadAxisRightStickHorizontal, 0), -0.9)
+	actions.Update(tick)
+	if actions.Pressed("attack") {
+		t.Error("the negative half of the stick pressed the attack")
+	}
+	device.Set(GamepadAxis(ebiten.StandardGamepadAxisRightStickHorizontal, 0), 0.9)
+	actions.Update(tick)
+	if !actions.Pressed("attack") {
+		t.Error("the positive half of the stick did not press the attack")
+	}
+
+	tests := []struct {
+		name  string
+		left  float64
+		right float64
+		stick float64
+		want  float64
+	}{
+		{"released", 0, 0, 0, 0},
+		{"left", 1, 0, 0, -1},
+		{"opposite keys cancel", 1, 1, 0, 0},
+		{"stick", 0, 0, 0.5, 0.5},
+		{"stick drift", 0, 0, 0.1, 0},
+		{"clamped", 0, 1, 0.5, 1},
+	}
+	for _, test := range tests {
+		device.Set(Key(ebiten.KeyArrowLeft), test.left)
+		device.Set(Key(ebiten.KeyArrowRight), test.right)
+		device.Set(GamepadAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, 0), test.stick)
+		actions.Update(tick)
+		if got := actions.Axis("move_x"); got != test.want {
+			t.Errorf("%s: move_x = %v, want %v", test.name, got, test.want)
+		}
+	}
+	if actions.Pressed("unknown") || actions.Axis("unknown") != 0 {
+		t.Error("unknown actions and axes must be released")
+	}
+}
+
+func TestRebinding(t *testing.T) {
+	device := NewMockDevice()
+	actions, err := NewActionMap(device, NewBindings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	device.Set(GamepadButton(ebiten.StandardGamepadButtonRightBottom), 1)
+	source, ok := Capture(device)
+	if !ok || source != GamepadButton(ebiten.StandardGamepadButtonRightBottom) {
+		t.Fatalf("captured %v, %v", source, ok)
+	}
+	if err := actions.Bind("jump", source); err != nil {
+		t.Fatal(err)
+	}
+	if err := actions.BindAxis("move_y", Key(ebiten.KeyW), -1); err != nil {
+		t.Fatal(err)
+	}
+	actions.Update(tick)
+	if !actions.Pressed("jump") {
+		t.Error("the captured button does not press the action")
+	}
+
+	filePath := filepath.Join(t.TempDir(), "bindings.json")
+	if err := actions.GetBindings().Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBindings(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources := loaded.Actions["jump"]; len(sources) != 1 || sources[0] != source || loaded.Axes["move_y"][Key(ebiten.KeyW)] != -1 {
+		t.Errorf("loaded bindings %+v", loaded)
+	}
+	if err := actions.Unbind("jump", source); err != nil {
+		t.Fatal(err)
+	}
+	actions.Update(tick)
+	if actions.Pressed("jump") || !actions.JustReleased("jump") {
+		t.Error("the unbound button still presses the action")
+	}
+
+	for _, text := range []string{"key:Nope", "pad:A", "Space", "axis:LeftStickHorizontal*"} {
+		if _, err := ParseSource(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+	if _, err := ParseBindings([]byte(`{"deadzone": 1}`)); err == nil {
+		t.Error("expected an error for a dead zone of 1")
+	}
+}
@@ -0,0 +1,126 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Bindings maps the physical inputs to the named actions and axes of the game.
+// It is stored as JSON so players can rebind the controls:
+//
+//	{
+//	  "deadzone": 0.2,
+//	  "actions": {"attack": ["key:Space", "mouse:Left", "gamepad:RightBottom"]},
+//	  "axes": {"move_x": {"key:ArrowLeft": -1, "key:ArrowRight": 1, "axis:LeftStickHorizontal": 1}}
+//	}
+type Bindings struct {
+	DeadZone float64                       `json:"deadzone"` // Axis values closer to 0 count as 0, against the drift of the sticks.
+	Actions  map[string][]Source           `json:"actions"`  // The inputs which press each action, any of them is enough.
+	Axes     map[string]map[Source]float64 `json:"axes"`     // The inputs of each axis with the factor their value is added with.
+}
+
+// NewBindings creates empty bindings with a dead zone of 0.2.
+// @return Bindings: The bindings.
+func NewBindings() Bindings {
+	return Bindings{
+		DeadZone: 0.2,
+		Actions:  map[string][]Source{},
+		Axes:     map[string]map[Source]float64{},
+	}
+}
+
+// ParseBindings reads bindings stored as JSON.
+// @param data []byte: The JSON document.
+// @return Bindings: The bindings.
+// @return error: Returns an error if the document is malformed or the bindings are invalid.
+func ParseBindings(data []byte) (Bindings, error) {
+	bindings := NewBindings()
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return Bindings{}, fmt.Errorf("input error: %w", err)
+	}
+	if err := bindings.Validate(); err != nil {
+		return Bindings{}, err
+	}
+	return bindings, nil
+}
+
+// LoadBindings reads bindings from a JSON file.
+// @param filePath string: The path of the file.
+// @return Bindings: The bindings.
+// @return error: Returns an error wrapping fs.ErrNotExist if there is no file, or if the file is invalid.
+func LoadBindings(filePath string) (Bindings, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Bindings{}, err
+	}
+	return ParseBindings(data)
+}
+
+// Save writes the bindings to a JSON file, replacing it.
+// @param filePath string: The path of the file.
+// @return error: Returns an error if the bindings are invalid or the file can not be written.
+func (bindings Bindings) Save(filePath string) error {
+	if err := bindings.Validate(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(data, '\n'), 0666)
+}
+
+// Validate checks the dead zone, the names and the inputs of the bindings.
+// @return error: Returns an error describing the first problem found.
+func (bindings Bindings) Validate() error {
+	if !(bindings.DeadZone >= 0 && bindings.DeadZone < 1) {
+		return errors.New("input error: dead zone must be at least 0 and less than 1")
+	}
+	for action, sources := range bindings.Actions {
+		if action == "" {
+			return errors.New("input error: action without name")
+		}
+		for _, source := range sources {
+			if err := source.Validate(); err != nil {
+				return fmt.Errorf("%w in action %q", err, action)
+			}
+		}
+	}
+	for axis, sources := range bindings.Axes {
+		if axis == "" {
+			return errors.New("input error: axis without name")
+		}
+		for source, scale := range sources {
+			if err := source.Validate(); err != nil {
+				return fmt.Errorf("%w in axis %q", err, axis)
+			}
+			if math.IsNaN(scale) || math.IsInf(scale, 0) {
+				return fmt.Errorf("input error: axis %q has an invalid factor for %s", axis, source)
+			}
+		}
+	}
+	return nil
+}
+
+// clone copies the bindings, so changing the copy does not change the original.
+// @return Bindings: The copy.
+func (bindings Bindings) clone() Bindings {
+	copied := Bindings{
+		DeadZone: bindings.DeadZone,
+		Actions:  make(map[string][]Source, len(bindings.Actions)),
+		Axes:     make(map[string]map[Source]float64, len(bindings.Axes)),
+	}
+	for action, sources := range bindings.Actions {
+		copied.Actions[action] = append([]Source(nil), sources...)
+	}
+	for axis, sources := range bindings.Axes {
+		copied.Axes[axis] = make(map[Source]float64, len(sources))
+		for source, scale := range sources {
+			copied.Axes[axis][source] = scale
+		}
+	}
+	return copied
+}
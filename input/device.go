@@ -0,0 +1,130 @@
+package input
+
+import (
+	"errors"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Device reads the state of physical inputs. The ActionMap only talks to a Device, so the real
+// devices can be replaced by a MockDevice in tests or by recorded input.
+type Device interface {
+	// Value returns the state of a physical input, the direction of axis sources is ignored.
+	// @param source Source: The input.
+	// @return float64: 0 or 1 for keys and buttons, -1 to 1 for axes.
+	Value(source Source) float64
+}
+
+// ebitenDevice is the Device of the keyboard, mouse and gamepads read through ebiten.
+type ebitenDevice struct {
+	gamepads []ebiten.GamepadID // The buffer of the connected gamepads.
+}
+
+// NewEbitenDevice creates the device of the real keyboard, mouse and gamepads.
+// Like the input functions of ebiten it can only be read while the game runs.
+// @return Device: The device.
+func NewEbitenDevice() Device {
+	return &ebitenDevice{}
+}
+
+// Value returns the state of a physical input, buttons and axes of all gamepads with the standard layout are combined.
+// @param source Source: The input.
+// @return float64: 0 or 1 for keys and buttons, -1 to 1 for axes.
+func (ebitenDevice *ebitenDevice) Value(source Source) float64 {
+	switch source.Kind {
+	case SourceKey:
+		return boolValue(ebiten.IsKeyPressed(ebiten.Key(source.Code)))
+	case SourceMouseButton:
+		return boolValue(ebiten.IsMouseButtonPressed(ebiten.MouseButton(source.Code)))
+	}
+	value := 0.0
+	ebitenDevice.gamepads = ebiten.AppendGamepadIDs(ebitenDevice.gamepads[:0])
+	for _, id := range ebitenDevice.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		switch source.Kind {
+		case SourceGamepadButton:
+			value = math.Max(value, boolValue(ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButton(source.Code))))
+		case SourceGamepadAxis:
+			// The gamepad which is moved the most wins.
+			if axis := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxis(source.Code)); math.Abs(axis) > math.Abs(value) {
+				value = axis
+			}
+		}
+	}
+	return value
+}
+
+// MockDevice is a Device whose inputs are set by the program, to test gameplay without real devices.
+type MockDevice interface {
+	Device
+
+	// Set changes the state of a physical input, the direction of axis sources is ignored.
+	// @param source Source: The input.
+	// @param value float64: 0 or 1 for keys and buttons, -1 to 1 for axes.
+	// @return error: Returns an error if the source is invalid or the value out of range.
+	Set(source Source, value float64) error
+
+	// Reset releases every input.
+	Reset()
+}
+
+// mockDevice is an internal implementation of the MockDevice interface.
+type mockDevice struct {
+	values map[Source]float64 // The states of the inputs which are not released.
+}
+
+// NewMockDevice creates a device with every input released.
+// @return MockDevice: The device.
+func NewMockDevice() MockDevice {
+	return &mockDevice{values: map[Source]float64{}}
+}
+
+// Value returns the state of a physical input.
+// @param source Source: The input.
+// @return float64: The value set last, 0 if it was never set.
+func (mockDevice *mockDevice) Value(source Source) float64 {
+	source.Direction = 0
+	return mockDevice.values[source]
+}
+
+// Set changes the state of a physical input.
+// @param source Source: The input.
+// @param value float64: 0 or 1 for keys and buttons, -1 to 1 for axes.
+// @return error: Returns an error if the source is invalid or the value out of range.
+func (mockDevice *mockDevice) Set(source Source, value float64) error {
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	source.Direction = 0
+	minimum := 0.0
+	if source.Kind == SourceGamepadAxis {
+		minimum = -1
+	}
+	if !(value >= minimum && value <= 1) {
+		return errors.New("input error: value out of range")
+	}
+	if value == 0 {
+		delete(mockDevice.values, source)
+	} else {
+		mockDevice.values[source] = value
+	}
+	return nil
+}
+
+// Reset releases every input.
+func (mockDevice *mockDevice) Reset() {
+	clear(mockDevice.values)
+}
+
+// boolValue converts the state of a button to a value.
+// @param pressed bool: True if the button is pressed.
+// @return float64: 1 if pressed, otherwise 0.
+func boolValue(pressed bool) float64 {
+	if pressed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// InputEvent is a change of one physical input in a Recording.
+type InputEvent struct {
+	Tick   int     // The tick from which the input has the value.
+	Source Source  // The input, always with direction 0.
+	Value  float64 // The new value of the input.
+}
+
+// Recording is the input of a session, tick by tick, to replay it deterministically.
+// Only the changes of the inputs are stored, so holding a key for a minute costs two events.
+// It is written as text, one line per tick with changes:
+//
+//	input recording
+//	tps 60
+//	ticks 600
+//	bindings {"deadzone":0.2,"actions":{...},"axes":{...}}
+//	0 key:ArrowRight=1
+//	12 key:ArrowRight=0 key:ArrowDown=1
+type Recording struct {
+	TPS      int          // The ticks per second of the session, the replay uses the same tick duration.
+	Ticks    int          // The number of recorded ticks.
+	Bindings Bindings     // The bindings of the session, the replay maps the inputs the same way.
+	Events   []InputEvent // The changes of the inputs sorted by tick.
+}
+
+// recordingHeader is the first line of a recording file.
+const recordingHeader = "input recording"
+
+// Validate checks the tick rate, the bindings and the order of the events.
+// @return error: Returns an error describing the first problem found.
+func (recording Recording) Validate() error {
+	if recording.TPS <= 0 {
+		return errors.New("input error: ticks per second must be positive")
+	}
+	if err := recording.Bindings.Validate(); err != nil {
+		return err
+	}
+	previous := 0
+	for _, event := range recording.Events {
+		if event.Tick < previous || event.Tick >= recording.Ticks {
+			return fmt.Errorf("input error: event at tick %d out of order", event.Tick)
+		}
+		if err := event.Source.Validate(); err != nil {
+			return err
+		}
+		previous = event.Tick
+	}
+	return nil
+}
+
+// Write writes the recording as text.
+// @param writer io.Writer: The destination.
+// @return error: Returns an error if the recording is invalid or can not be written.
+func (recording Recording) Write(writer io.Writer) error {
+	if err := recording.Validate(); err != nil {
+		return err
+	}
+	bindings, err := json.Marshal(recording.Bindings)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(writer)
+	fmt.Fprintf(buffered, "%s\ntps %d\nticks %d\nbindings %s\n", recordingHeader, recording.TPS, recording.Ticks, bindings)
+	for i, event := range recording.Events {
+		if i == 0 || event.Tick != recording.Events[i-1].Tick {
+			if i > 0 {
+				buffered.WriteByte('\n')
+			}
+			buffered.WriteString(strconv.Itoa(event.Tick))
+		}
+		fmt.Fprintf(buffered, " %s=%s", event.Source, strconv.FormatFloat(event.Value, 'g', -1, 64))
+	}
+	if len(recording.Events) > 0 {
+		buffered.WriteByte('\n')
+	}
+	return buffered.Flush()
+}
+
+// Save writes the recording to a file, replacing it.
+// @param filePath string: The path of the file.
+// @return error: Returns an error if the recording is invalid or the file can not be written.
+func (recording Recording) Save(filePath string) error {
+	var buffer bytes.Buffer
+	if err := recording.Write(&buffer); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, buffer.Bytes(), 0666)
+}
+
+// ReadRecording reads a recording written by Recording.Write.
+// @param reader io.Reader: The source.
+// @return Recording: The recording.
+// @return error: Returns an error if the text is malformed or the recording is invalid.
+func ReadRecording(reader io.Reader) (Recording, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<20)
+	line := 0
+	scan := func() bool {
+		line++
+		return scanner.Scan()
+	}
+	var recording Recording
+	if !scan() || scanner.Text() != recordingHeader {
+		return Recording{}, errors.New("input error: not an input recording")
+	}
+	for _, field := range []string{"tps", "ticks", "bindings"} {
+		if !scan() {
+			return Recording{}, fmt.Errorf("input error: recording has no %s", field)
+		}
+		value, found := strings.CutPrefix(scanner.Text(), field+" ")
+		if !found {
+			return Recording{}, fmt.Errorf("input error: line %d: expected %s", line, field)
+		}
+		var err error
+		switch field {
+		case "tps":
+			recording.TPS, err = strconv.Atoi(value)
+		case "ticks":
+			recording.Ticks, err = strconv.Atoi(value)
+		case "bindings":
+			if recording.Bindings, err = ParseBindings([]byte(value)); err != nil {
+				return Recording{}, fmt.Errorf("%w on line %d", err, line)
+			}
+		}
+		if err != nil {
+			return Recording{}, fmt.Errorf("input error: line %d: %w", line, err)
+		}
+	}
+	for scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		tick, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return Recording{}, fmt.Errorf("input error: line %d: %w", line, err)
+		}
+		for _, change := range fields[1:] {
+			text, number, found := strings.Cut(change, "=")
+			if !found {
+				return Recording{}, fmt.Errorf("input error: line %d: %q has no value", line, change)
+			}
+			source, err := ParseSource(text)
+			if err != nil {
+				return Recording{}, fmt.Errorf("%w on line %d", err, line)
+			}
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return Recording{}, fmt.Errorf("input error: line %d: %w", line, err)
+			}
+			recording.Events = append(recording.Events, InputEvent{Tick: tick, Source: source, Value: value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Recording{}, err
+	}
+	if err := recording.Validate(); err != nil {
+		return Recording{}, err
+	}
+	return recording, nil
+}
+
+// LoadRecording reads a recording from a file.
+// @param filePath string: The path of the file.
+// @return Recording: The recording.
+// @return error: Returns an error if the file can not be read or is not a valid recording.
+func LoadRecording(filePath string) (Recording, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Recording{}, err
+	}
+	defer file.Close()
+	return ReadRecording(file)
+}
@@ -0,0 +1,158 @@
+package input
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// TickDevice is a Device whose state advances tick by tick, Next must be called once per tick
+// before ActionMap.Update.
+type TickDevice interface {
+	Device
+
+	// Next starts the next tick, the first call starts tick 0.
+	// @return bool: False if there is no further tick.
+	Next() bool
+}
+
+// Recorder is a TickDevice which passes another device through and records every change of the inputs read from it.
+type Recorder interface {
+	TickDevice
+
+	// Recording returns the inputs recorded so far.
+	// @return Recording: The recording up to and including the current tick.
+	Recording() Recording
+}
+
+// recorder is an internal implementation of the Recorder interface.
+type recorder struct {
+	device    Device             // The recorded device.
+	recording Recording          // The recorded inputs.
+	values    map[Source]float64 // The last recorded value of each input.
+}
+
+// NewRecorder creates a recorder of a device.
+// Rebinding the controls while recording is not recorded, the bindings are stored once.
+// @param device Device: The device, usually NewEbitenDevice.
+// @param bindings Bindings: The bindings of the ActionMap reading the recorder.
+// @param tps int: The ticks per second of the game loop.
+// @return Recorder: The recorder.
+// @return error: Returns an error if the device is nil, the bindings invalid or tps not positive.
+func NewRecorder(device Device, bindings Bindings, tps int) (Recorder, error) {
+	if device == nil {
+		return nil, errors.New("input error: device is nil")
+	}
+	if tps <= 0 {
+		return nil, errors.New("input error: ticks per second must be positive")
+	}
+	if err := bindings.Validate(); err != nil {
+		return nil, err
+	}
+	return &recorder{
+		device:    device,
+		recording: Recording{TPS: tps, Bindings: bindings.clone()},
+		values:    map[Source]float64{},
+	}, nil
+}
+
+// Value reads an input from the device and records it if it changed.
+// @param source Source: The input.
+// @return float64: The value of the device.
+func (recorder *recorder) Value(source Source) float64 {
+	value := recorder.device.Value(source)
+	source.Direction = 0
+	if recorder.recording.Ticks > 0 && recorder.values[source] != value {
+		recorder.values[source] = value
+		recorder.recording.Events = append(recorder.recording.Events, InputEvent{Tick: recorder.recording.Ticks - 1, Source: source, Value: value})
+	}
+	return value
+}
+
+// Next starts the next tick.
+// @return bool: Always true, a recording has no end.
+func (recorder *recorder) Next() bool {
+	recorder.recording.Ticks++
+	return true
+}
+
+// Recording returns the inputs recorded so far.
+// @return Recording: The recording.
+func (recorder *recorder) Recording() Recording {
+	recording := recorder.recording
+	recording.Bindings = recording.Bindings.clone()
+	recording.Events = append([]InputEvent(nil), recording.Events...)
+	return recording
+}
+
+// replayer is a TickDevice which returns the inputs of a recording.
+type replayer struct {
+	recording Recording          // The replayed recording.
+	tick      int                // The current tick, -1 before the first call of Next.
+	next      int                // The index of the first event which is not applied yet.
+	values    map[Source]float64 // The values of the inputs in the current tick.
+}
+
+// NewReplayer creates a device which returns the inputs of a recording, tick by tick.
+// @param recording Recording: The recording.
+// @return TickDevice: The device, Next returns false after the last recorded tick.
+// @return error: Returns an error if the recording is invalid.
+func NewReplayer(recording Recording) (TickDevice, error) {
+	if err := recording.Validate(); err != nil {
+		return nil, err
+	}
+	events := append([]InputEvent(nil), recording.Events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Tick < events[j].Tick })
+	recording.Events = events
+	return &replayer{recording: recording, tick: -1, values: map[Source]float64{}}, nil
+}
+
+// Value returns the recorded value of an input in the current tick.
+// @param source Source: The input.
+// @return float64: The value, 0 if the input was never recorded.
+func (replayer *replayer) Value(source Source) float64 {
+	source.Direction = 0
+	return replayer.values[source]
+}
+
+// Next applies the changes of the next tick.
+// @return bool: False if the recording has no further tick.
+func (replayer *replayer) Next() bool {
+	if replayer.tick+1 >= replayer.recording.Ticks {
+		return false
+	}
+	replayer.tick++
+	events := replayer.recording.Events
+	for ; replayer.next < len(events) && events[replayer.next].Tick <= replayer.tick; replayer.next++ {
+		replayer.values[events[replayer.next].Source] = events[replayer.next].Value
+	}
+	return true
+}
+
+// RunHeadless replays a recording without a window or real devices, for tests and bug reproduction.
+// The action map reads the recording with the recorded bindings, and each tick calls Update of the
+// action map and then step with the fixed tick duration of the recording, like the game loop does.
+// @param recording Recording: The recording.
+// @param actions ActionMap: The action map of the game, its device and bindings are replaced.
+// @param step func(dt time.Duration) error: The game logic of one tick, reading the action map.
+// @return error: Returns the first error of step, or an error if the recording is invalid.
+func RunHeadless(recording Recording, actions ActionMap, step func(dt time.Duration) error) error {
+	device, err := NewReplayer(recording)
+	if err != nil {
+		return err
+	}
+	if err := actions.SetBindings(recording.Bindings); err != nil {
+		return err
+	}
+	if err := actions.SetDevice(device); err != nil {
+		return err
+	}
+	dt := time.Second / time.Duration(recording.TPS)
+	for device.Next() {
+		actions.Update(dt)
+		if err := step(dt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
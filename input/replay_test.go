@@ -0,0 +1,73 @@
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// sample describes the actions and axes of one tick.
+func sample(actions ActionMap) string {
+	return fmt.Sprint(actions.Pressed("attack"), actions.JustReleased("attack"), actions.HoldDuration("attack"), actions.Axis("move_x"))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	bindings := NewBindings()
+	bindings.Actions["attack"] = []Source{Key(ebiten.KeySpace), GamepadAxis(ebiten.StandardGamepadAxisRightStickVertical, 1)}
+	bindings.Axes["move_x"] = map[Source]float64{Key(ebiten.KeyArrowLeft): -1, GamepadAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, 0): 1}
+	device := NewMockDevice()
+	recorder, err := NewRecorder(device, bindings, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := NewActionMap(recorder, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var live []string
+	for tick := 0; tick < 20; tick++ {
+		device.Set(Key(ebiten.KeySpace), float64(tick/3%2))
+		device.Set(GamepadAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, 0), math.Sin(float64(tick)))
+		device.Set(GamepadAxis(ebiten.StandardGamepadAxisRightStickVertical, 0), float64(tick%7)/7)
+		recorder.Next()
+		actions.Update(time.Second / 30)
+		live = append(live, sample(actions))
+	}
+
+	var buffer bytes.Buffer
+	if err := recorder.Recording().Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	recording, err := ReadRecording(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recording, recorder.Recording()) {
+		t.Errorf("read recording differs from the written one")
+	}
+
+	var replayed []string
+	other, _ := NewActionMap(NewMockDevice(), NewBindings())
+	err = RunHeadless(recording, other, func(dt time.Duration) error {
+		if dt != time.Second/30 {
+			t.Errorf("tick lasts %v, want the tick of the recording", dt)
+		}
+		replayed = append(replayed, sample(other))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(live, replayed) {
+		t.Errorf("replay differs from the session:\nlive     %v\nreplayed %v", live, replayed)
+	}
+
+	if _, err := ReadRecording(bytes.NewBufferString("input recording\ntps 60\nticks 1\nbindings {}\n3 key:Space=1\n")); err == nil {
+		t.Error("expected an error for an event after the last tick")
+	}
+}
@@ -0,0 +1,235 @@
+// Package input store the mapping of physical inputs (keys, mouse and gamepad buttons, gamepad axes) to the actions of the game
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SourceKind tells which kind of physical input a Source is.
+type SourceKind int
+
+const (
+	SourceKey           SourceKind = iota // A key of the keyboard.
+	SourceMouseButton                     // A button of the mouse.
+	SourceGamepadButton                   // A button of a gamepad with the standard layout.
+	SourceGamepadAxis                     // An axis of a gamepad with the standard layout.
+)
+
+// Source is one physical input. In the configuration files it is written as text:
+// "key:ArrowLeft", "mouse:Left", "gamepad:RightBottom", "axis:LeftStickHorizontal",
+// and "axis:LeftStickHorizontal+" or "axis:LeftStickHorizontal-" for one half of an axis.
+type Source struct {
+	Kind      SourceKind // The kind of the input.
+	Code      int        // The ebiten.Key, ebiten.MouseButton, ebiten.StandardGamepadButton or ebiten.StandardGamepadAxis.
+	Direction int        // For axes 1 or -1 to use only the positive or negative half, 0 for the whole axis.
+}
+
+// Names of the sources in the configuration files, ebiten itself only names the keys.
+var (
+	mouseButtonNames = map[string]ebiten.MouseButton{
+		"Left":    ebiten.MouseButtonLeft,
+		"Middle":  ebiten.MouseButtonMiddle,
+		"Right":   ebiten.MouseButtonRight,
+		"Back":    ebiten.MouseButton3,
+		"Forward": ebiten.MouseButton4,
+	}
+	gamepadButtonNames = map[string]ebiten.StandardGamepadButton{
+		"RightBottom":      ebiten.StandardGamepadButtonRightBottom,
+		"RightRight":       ebiten.StandardGamepadButtonRightRight,
+		"RightLeft":        ebiten.StandardGamepadButtonRightLeft,
+		"RightTop":         ebiten.StandardGamepadButtonRightTop,
+		"FrontTopLeft":     ebiten.StandardGamepadButtonFrontTopLeft,
+		"FrontTopRight":    ebiten.StandardGamepadButtonFrontTopRight,
+		"FrontBottomLeft":  ebiten.StandardGamepadButtonFrontBottomLeft,
+		"FrontBottomRight": ebiten.StandardGamepadButtonFrontBottomRight,
+		"CenterLeft":       ebiten.StandardGamepadButtonCenterLeft,
+		"CenterRight":      ebiten.StandardGamepadButtonCenterRight,
+		"LeftStick":        ebiten.StandardGamepadButtonLeftStick,
+		"RightStick":       ebiten.StandardGamepadButtonRightStick,
+		"LeftTop":          ebiten.StandardGamepadButtonLeftTop,
+		"LeftBottom":       ebiten.StandardGamepadButtonLeftBottom,
+		"LeftLeft":         ebiten.StandardGamepadButtonLeftLeft,
+		"LeftRight":        ebiten.StandardGamepadButtonLeftRight,
+		"CenterCenter":     ebiten.StandardGamepadButtonCenterCenter,
+	}
+	gamepadAxisNames = map[string]ebiten.StandardGamepadAxis{
+		"LeftStickHorizontal":  ebiten.StandardGamepadAxisLeftStickHorizontal,
+		"LeftStickVertical":    ebiten.StandardGamepadAxisLeftStickVertical,
+		"RightStickHorizontal": ebiten.StandardGamepadAxisRightStickHorizontal,
+		"RightStickVertical":   ebiten.StandardGamepadAxisRightStickVertical,
+	}
+)
+
+// Key creates the source of a key.
+// @param key ebiten.Key: The key.
+// @return Source: The source.
+func Key(key ebiten.Key) Source {
+	return Source{Kind: SourceKey, Code: int(key)}
+}
+
+// MouseButton creates the source of a mouse button.
+// @param button ebiten.MouseButton: The button.
+// @return Source: The source.
+func MouseButton(button ebiten.MouseButton) Source {
+	return Source{Kind: SourceMouseButton, Code: int(button)}
+}
+
+// GamepadButton creates the source of a gamepad button, any connected gamepad can press it.
+// @param button ebiten.StandardGamepadButton: The button in the standard layout.
+// @return Source: The source.
+func GamepadButton(button ebiten.StandardGamepadButton) Source {
+	return Source{Kind: SourceGamepadButton, Code: int(button)}
+}
+
+// GamepadAxis creates the source of a gamepad axis, the axis of any connected gamepad moves it.
+// @param axis ebiten.StandardGamepadAxis: The axis in the standard layout.
+// @param direction int: 1 or -1 to use only the positive or negative half, 0 for the whole axis.
+// @return Source: The source.
+func GamepadAxis(axis ebiten.StandardGamepadAxis, direction int) Source {
+	return Source{Kind: SourceGamepadAxis, Code: int(axis), Direction: direction}
+}
+
+// Validate checks that the source names an existing input.
+// @return error: Returns an error if the kind, the code or the direction is out of range.
+func (source Source) Validate() error {
+	var max int
+	switch source.Kind {
+	case SourceKey:
+		max = int(ebiten.KeyMax)
+	case SourceMouseButton:
+		max = int(ebiten.MouseButtonMax)
+	case SourceGamepadButton:
+		max = int(ebiten.StandardGamepadButtonMax)
+	case SourceGamepadAxis:
+		max = int(ebiten.StandardGamepadAxisMax)
+	default:
+		return errors.New("input error: unknown source kind")
+	}
+	if source.Code < 0 || source.Code > max {
+		return fmt.Errorf("input error: code %d out of range", source.Code)
+	}
+	if source.Direction < -1 || source.Direction > 1 || source.Direction != 0 && source.Kind != SourceGamepadAxis {
+		return errors.New("input error: only axes have a direction")
+	}
+	return nil
+}
+
+// String returns the source in the form of the configuration files.
+// @return string: The text, for example "key:Space".
+func (source Source) String() string {
+	switch source.Kind {
+	case SourceKey:
+		return "key:" + ebiten.Key(source.Code).String()
+	case SourceMouseButton:
+		return "mouse:" + nameOf(mouseButtonNames, ebiten.MouseButton(source.Code))
+	case SourceGamepadButton:
+		return "gamepad:" + nameOf(gamepadButtonNames, ebiten.StandardGamepadButton(source.Code))
+	case SourceGamepadAxis:
+		text := "axis:" + nameOf(gamepadAxisNames, ebiten.StandardGamepadAxis(source.Code))
+		switch source.Direction {
+		case 1:
+			text += "+"
+		case -1:
+			text += "-"
+		}
+		return text
+	}
+	return fmt.Sprintf("unknown:%d", source.Code)
+}
+
+// MarshalText writes the source like String, so sources can be keys and values of JSON objects.
+// @return []byte: The text.
+// @return error: Returns an error if the source is invalid.
+func (source Source) MarshalText() ([]byte, error) {
+	if err := source.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(source.String()), nil
+}
+
+// UnmarshalText reads a source written by MarshalText, the names are not case sensitive.
+// @param text []byte: The text, for example "mouse:Left".
+// @return error: Returns an error if the text names no input.
+func (source *Source) UnmarshalText(text []byte) error {
+	kind, name, found := strings.Cut(string(text), ":")
+	if !found {
+		return fmt.Errorf("input error: source %q has no kind", text)
+	}
+	var parsed Source
+	var ok bool
+	switch strings.ToLower(kind) {
+	case "key":
+		var key ebiten.Key
+		if err := key.UnmarshalText([]byte(name)); err != nil {
+			return fmt.Errorf("input error: unknown key %q", name)
+		}
+		parsed, ok = Key(key), true
+	case "mouse":
+		var button ebiten.MouseButton
+		button, ok = lookup(mouseButtonNames, name)
+		parsed = MouseButton(button)
+	case "gamepad":
+		var button ebiten.StandardGamepadButton
+		button, ok = lookup(gamepadButtonNames, name)
+		parsed = GamepadButton(button)
+	case "axis":
+		direction := 0
+		if trimmed, cut := strings.CutSuffix(name, "+"); cut {
+			name, direction = trimmed, 1
+		} else if trimmed, cut := strings.CutSuffix(name, "-"); cut {
+			name, direction = trimmed, -1
+		}
+		var axis ebiten.StandardGamepadAxis
+		axis, ok = lookup(gamepadAxisNames, name)
+		parsed = GamepadAxis(axis, direction)
+	default:
+		return fmt.Errorf("input error: unknown source kind %q", kind)
+	}
+	if !ok {
+		return fmt.Errorf("input error: unknown %s %q", kind, name)
+	}
+	*source = parsed
+	return nil
+}
+
+// ParseSource reads a source in the form of the configuration files.
+// @param text string: The text, for example "gamepad:RightBottom".
+// @return Source: The source.
+// @return error: Returns an error if the text names no input.
+func ParseSource(text string) (Source, error) {
+	var source Source
+	err := source.UnmarshalText([]byte(text))
+	return source, err
+}
+
+// lookup finds a value by its name, ignoring the case.
+// @param names map[string]T: The values by name.
+// @param name string: The name.
+// @return T: The value.
+// @return bool: False if no value has the name.
+func lookup[T comparable](names map[string]T, name string) (T, bool) {
+	for candidate, value := range names {
+		if strings.EqualFold(candidate, name) {
+			return value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// nameOf finds the name of a value.
+// @param names map[string]T: The values by name.
+// @param value T: The value.
+// @return string: The name, or the number of the value if it has no name.
+func nameOf[T comparable](names map[string]T, value T) string {
+	for name, candidate := range names {
+		if candidate == value {
+			return name
+		}
+	}
+	return fmt.Sprint(value)
+}
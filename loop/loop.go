@@ -0,0 +1,352 @@
+// Package loop store the fixed-timestep game loop of the engine, which makes the speed of the game independent of the TPS
+package loop
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Simulation is a game driven by a Loop. Its logic advances in steps of a fixed duration,
+// so it behaves the same whatever the TPS and the frame rate are.
+type Simulation interface {
+	// Step advances the game by one fixed step.
+	// @param dt time.Duration: The duration of the step, the same for every call while the step is not changed.
+	// @return error: An error stops the game like an error of ebiten.Game.Update.
+	Step(dt time.Duration) error
+
+	// Draw renders the game between two steps.
+	// @param screen *ebiten.Image: The screen.
+	// @param alpha float64: The part of the next step which has already elapsed, from 0 to 1, to draw moving
+	// objects at previous + (current - previous) * alpha.
+	Draw(screen *ebiten.Image, alpha float64)
+
+	// Layout returns the size of the screen like ebiten.Game.Layout.
+	// @param outsideWidth, outsideHeight int: The size of the window.
+	// @return (int, int): The size of the screen.
+	Layout(outsideWidth, outsideHeight int) (int, int)
+}
+
+// Controller can be implemented by a Simulation to control the loop. Control is called once per Update
+// before the steps, also while the loop is paused, for example to read the pause key.
+type Controller interface {
+	// Control is called once per Update before the steps.
+	// @param loop Loop: The loop running the simulation.
+	// @return error: An error stops the game.
+	Control(loop Loop) error
+}
+
+// Loop is an ebiten.Game which runs a Simulation with a fixed step.
+// Every Update adds 1/TPS of time (the measured time since the previous Update when the TPS is not fixed),
+// multiplied by the time scale, to an accumulator and runs as many steps as fit into it,
+// so raising the TPS makes the game smoother but not faster.
+// The rest of the accumulator gives the interpolation alpha passed to Draw.
+type Loop interface {
+	ebiten.Game
+
+	// GetSimulation returns the simulation run by the loop.
+	// @return Simulation: The simulation.
+	GetSimulation() Simulation
+
+	// Advance adds game time and runs the steps which fit into the accumulated time, Update calls it with GetTick.
+	// It can be called directly to run the simulation without a window.
+	// @param elapsed time.Duration: The elapsed real time, it is multiplied by the time scale.
+	// @return error: Returns the first error of a step.
+	Advance(elapsed time.Duration) error
+
+	// SetStep sets the duration of a simulation step.
+	// @param step time.Duration: The duration, for example time.Second / 60.
+	// @return error: Returns an error if the step is not positive.
+	SetStep(step time.Duration) error
+
+	// GetStep returns the duration of a simulation step.
+	// @return time.Duration: The duration.
+	GetStep() time.Duration
+
+	// SetMaxSteps limits the steps of one Advance, so a slow simulation does not fall further and further behind.
+	// The time which does not fit is dropped and the game slows down instead.
+	// @param maxSteps int: The number of steps, at least 1.
+	// @return error: Returns an error if maxSteps is less than 1.
+	SetMaxSteps(maxSteps int) error
+
+	// GetMaxSteps returns the maximal number of steps of one Advance.
+	// @return int: The number of steps.
+	GetMaxSteps() int
+
+	// SetPaused pauses or resumes the simulation, Draw is still called while paused.
+	// @param paused bool: True to pause.
+	// @return error: Returns nil if the state is set successfully.
+	SetPaused(paused bool) error
+
+	// IsPaused tells whether the simulation is paused.
+	// @return bool: True while paused.
+	IsPaused() bool
+
+	// StepOnce runs exactly one step at the next Advance while the simulation is paused, to debug frame by frame.
+	// @return error: Returns an error if the simulation is not paused.
+	StepOnce() error
+
+	// SetTimeScale sets the speed of the game time, less than 1 for slow motion and more than 1 for fast forward.
+	// @param scale float64: The factor of the game time, 1 for real time.
+	// @return error: Returns an error if the scale is not positive and finite.
+	SetTimeScale(scale float64) error
+
+	// GetTimeScale returns the speed of the game time.
+	// @return float64: The factor of the game time.
+	GetTimeScale() float64
+
+	// GetAlpha returns the part of the next step which has elapsed after the last Advance.
+	// @return float64: The interpolation factor from 0 to 1.
+	GetAlpha() float64
+
+	// GetSteps returns the number of steps run since the loop was created.
+	// @return int: The number of steps.
+	GetSteps() int
+
+	// GetTime returns the simulated time, the sum of the durations of all steps.
+	// @return time.Duration: The simulated time.
+	GetTime() time.Duration
+
+	// GetTick returns the real time of the current Update, 1/TPS or the measured time when the TPS is not fixed.
+	// It is set before Control is called, so a controller can update its input with it.
+	// @return time.Duration: The duration of the tick, 0 before the first Update.
+	GetTick() time.Duration
+}
+
+// gameLoop is an internal implementation of the Loop interface.
+type gameLoop struct {
+	simulation  Simulation       // The game run by the loop.
+	step        time.Duration    // The duration of a simulation step.
+	maxSteps    int              // The maximal number of steps of one Advance.
+	accumulator time.Duration    // The game time which is not simulated yet.
+	timeScale   float64          // The factor of the game time.
+	paused      bool             // True while the simulation is paused.
+	pending     int              // The steps requested by StepOnce.
+	steps       int              // The number of steps run.
+	time        time.Duration    // The simulated time.
+	lastUpdate  time.Time        // The real time of the last Update, to interpolate frames drawn between updates.
+	tick        time.Duration    // The real time of the last Update since the previous one.
+	now         func() time.Time // The clock of the loop.
+}
+
+// NewLoop creates a loop which runs a simulation with a fixed step, at most 8 steps per Advance.
+// @param simulation Simulation: The game.
+// @param step time.Duration: The duration of a step, for example time.Second / 60.
+// @return Loop: The loop, to pass to ebiten.RunGame.
+// @return error: Returns an error if the simulation is nil or the step is not positive.
+func NewLoop(simulation Simulation, step time.Duration) (Loop, error) {
+	if simulation == nil {
+		return nil, errors.New("loop error: simulation is nil")
+	}
+	if step <= 0 {
+		return nil, errors.New("loop error: step must be positive")
+	}
+	return &gameLoop{
+		simulation: simulation,
+		step:       step,
+		maxSteps:   8,
+		timeScale:  1,
+		now:        time.Now,
+	}, nil
+}
+
+// GetSimulation returns the simulation run by the loop.
+// @return Simulation: The simulation.
+func (gameLoop *gameLoop) GetSimulation() Simulation {
+	return gameLoop.simulation
+}
+
+// Update lets the simulation control the loop and advances it by one tick of ebiten, it implements ebiten.Game.
+// @return error: Returns the error of the controller or of a step.
+func (gameLoop *gameLoop) Update() error {
+	now := gameLoop.now()
+	gameLoop.tick = gameLoop.measureTick(now)
+	gameLoop.lastUpdate = now
+	if controller, ok := gameLoop.simulation.(Controller); ok {
+		if err := controller.Control(gameLoop); err != nil {
+			return err
+		}
+	}
+	return gameLoop.Advance(gameLoop.tick)
+}
+
+// measureTick returns the duration of a tick of ebiten. It is 1/TPS when the TPS is fixed, otherwise
+// (ebiten.SyncWithFPS) it is the real time since the last Update, 1/ebiten.DefaultTPS for the first one.
+// @param now time.Time: The real time of the Update.
+// @return time.Duration: The duration of the tick, never negative.
+func (gameLoop *gameLoop) measureTick(now time.Time) time.Duration {
+	if tps := ebiten.TPS(); tps > 0 {
+		return time.Second / time.Duration(tps)
+	}
+	if gameLoop.lastUpdate.IsZero() {
+		return time.Second / ebiten.DefaultTPS
+	}
+	return max(now.Sub(gameLoop.lastUpdate), 0)
+}
+
+// Advance adds game time and runs the steps which fit into the accumulated time.
+// @param elapsed time.Duration: The elapsed real time.
+// @return error: Returns the first error of a step.
+func (gameLoop *gameLoop) Advance(elapsed time.Duration) error {
+	if gameLoop.paused {
+		for ; gameLoop.pending > 0; gameLoop.pending-- {
+			if err := gameLoop.runStep(); err != nil {
+				gameLoop.pending--
+				return err
+			}
+		}
+		return nil
+	}
+	gameLoop.accumulator += time.Duration(float64(elapsed) * gameLoop.timeScale)
+	for steps := 0; gameLoop.accumulator >= gameLoop.step; steps++ {
+		if steps == gameLoop.maxSteps {
+			// Drop the time which does not fit, but keep the alpha.
+			gameLoop.accumulator %= gameLoop.step
+			break
+		}
+		gameLoop.accumulator -= gameLoop.step
+		if err := gameLoop.runStep(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runStep runs one step of the simulation.
+// @return error: Returns the error of the step.
+func (gameLoop *gameLoop) runStep() error {
+	gameLoop.steps++
+	gameLoop.time += gameLoop.step
+	return gameLoop.simulation.Step(gameLoop.step)
+}
+
+// Draw draws the simulation with the interpolation alpha, it implements ebiten.Game.
+// When the frame rate is above the TPS, the real time since the last Update is added to the alpha.
+// @param screen *ebiten.Image: The screen.
+func (gameLoop *gameLoop) Draw(screen *ebiten.Image) {
+	alpha := gameLoop.GetAlpha()
+	if !gameLoop.paused && !gameLoop.lastUpdate.IsZero() {
+		since := min(max(gameLoop.now().Sub(gameLoop.lastUpdate), 0), gameLoop.tick)
+		alpha = math.Min(1, alpha+float64(since)*gameLoop.timeScale/float64(gameLoop.step))
+	}
+	gameLoop.simulation.Draw(screen, alpha)
+}
+
+// Layout returns the layout of the simulation, it implements ebiten.Game.
+// @param outsideWidth, outsideHeight int: The size of the window.
+// @return (int, int): The size of the screen.
+func (gameLoop *gameLoop) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return gameLoop.simulation.Layout(outsideWidth, outsideHeight)
+}
+
+// SetStep sets the duration of a simulation step.
+// @param step time.Duration: The duration.
+// @return error: Returns an error if the step is not positive.
+func (gameLoop *gameLoop) SetStep(step time.Duration) error {
+	if step <= 0 {
+		return errors.New("loop error: step must be positive")
+	}
+	gameLoop.step = step
+	return nil
+}
+
+// GetStep returns the duration of a simulation step.
+// @return time.Duration: The duration.
+func (gameLoop *gameLoop) GetStep() time.Duration {
+	return gameLoop.step
+}
+
+// SetMaxSteps limits the steps of one Advance.
+// @param maxSteps int: The number of steps.
+// @return error: Returns an error if maxSteps is less than 1.
+func (gameLoop *gameLoop) SetMaxSteps(maxSteps int) error {
+	if maxSteps < 1 {
+		return errors.New("loop error: at least one step per update is needed")
+	}
+	gameLoop.maxSteps = maxSteps
+	return nil
+}
+
+// GetMaxSteps returns the maximal number of steps of one Advance.
+// @return int: The number of steps.
+func (gameLoop *gameLoop) GetMaxSteps() int {
+	return gameLoop.maxSteps
+}
+
+// SetPaused pauses or resumes the simulation, resuming drops the single steps which did not run yet.
+// @param paused bool: True to pause.
+// @return error: Returns nil if the state is set successfully.
+func (gameLoop *gameLoop) SetPaused(paused bool) error {
+	if !paused {
+		gameLoop.pending = 0
+	}
+	gameLoop.paused = paused
+	return nil
+}
+
+// IsPaused tells whether the simulation is paused.
+// @return bool: True while paused.
+func (gameLoop *gameLoop) IsPaused() bool {
+	return gameLoop.paused
+}
+
+// StepOnce runs exactly one step at the next Advance while the simulation is paused.
+// @return error: Returns an error if the simulation is not paused.
+func (gameLoop *gameLoop) StepOnce() error {
+	if !gameLoop.paused {
+		return errors.New("loop error: single steps need a paused simulation")
+	}
+	gameLoop.pending++
+	return nil
+}
+
+// SetTimeScale sets the speed of the game time.
+// @param scale float64: The factor of the game time.
+// @return error: Returns an error if the scale is not positive and finite.
+func (gameLoop *gameLoop) SetTimeScale(scale float64) error {
+	if !(scale > 0) || math.IsInf(scale, 1) {
+		return errors.New("loop error: time scale must be positive")
+	}
+	gameLoop.timeScale = scale
+	return nil
+}
+
+// GetTimeScale returns the speed of the game time.
+// @return float64: The factor of the game time.
+func (gameLoop *gameLoop) GetTimeScale() float64 {
+	return gameLoop.timeScale
+}
+
+// GetAlpha returns the part of the next step which has elapsed after the last Advance.
+// @return float64: The interpolation factor from 0 to 1.
+func (gameLoop *gameLoop) GetAlpha() float64 {
+	return math.Min(1, float64(gameLoop.accumulator)/float64(gameLoop.step))
+}
+
+// GetSteps returns the number of steps run since the loop was created.
+// @return int: The number of steps.
+func (gameLoop *gameLoop) GetSteps() int {
+	return gameLoop.steps
+}
+
+// GetTime returns the simulated time.
+// @return time.Duration: The simulated time.
+func (gameLoop *gameLoop) GetTime() time.Duration {
+	return gameLoop.time
+}
+
+// GetTick returns the real time of the current Update.
+// @return time.Duration: The duration of the tick.
+func (gameLoop *gameLoop) GetTick() time.Duration {
+	return gameLoop.tick
+}
+
+// Lerp interpolates between the state of the previous and of the current step.
+// @param previous, current float64: The values after the previous and the current step.
+// @param alpha float64: The interpolation factor passed to Simulation.Draw.
+// @return float64: The value to draw.
+func Lerp(previous, current, alpha float64) float64 {
+	return previous + (current-previous)*alpha
+}
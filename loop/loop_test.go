@@ -0,0 +1,155 @@
+package loop
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// countingSimulation records the steps and the alpha of the last drawing.
+type countingSimulation struct {
+	steps    []time.Duration
+	alpha    float64
+	paused   bool
+	failStep int
+}
+
+func (simulation *countingSimulation) Step(dt time.Duration) error {
+	simulation.steps = append(simulation.steps, dt)
+	if len(simulation.steps) == simulation.failStep {
+		return errors.New("step failed")
+	}
+	return nil
+}
+
+func (simulation *countingSimulation) Draw(screen *ebiten.Image, alpha float64) {
+	simulation.alpha = alpha
+}
+
+func (simulation *countingSimulation) Layout(int, int) (int, int) {
+	return 320, 240
+}
+
+func (simulation *countingSimulation) Control(loop Loop) error {
+	return loop.SetPaused(simulation.paused)
+}
+
+func TestLoopFixedStep(t *testing.T) {
+	simulation := &countingSimulation{}
+	loop, err := NewLoop(simulation, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		steps   int
+		alpha   float64
+	}{
+		{"less than a step", 4 * time.Millisecond, 0, 0.4},
+		{"rest completes a step", 8 * time.Millisecond, 1, 0.2},
+		{"two steps at once", 20 * time.Millisecond, 3, 0.2},
+		{"stall is limited", time.Second, 11, 0.2},
+	}
+	for _, test := range tests {
+		if err := loop.Advance(test.elapsed); err != nil {
+			t.Fatal(err)
+		}
+		if len(simulation.steps) != test.steps || loop.GetAlpha() < test.alpha-1e-9 || loop.GetAlpha() > test.alpha+1e-9 {
+			t.Errorf("%s: %d steps with alpha %v, want %d with %v", test.name, len(simulation.steps), loop.GetAlpha(), test.steps, test.alpha)
+		}
+	}
+	if loop.GetSteps() != 11 || loop.GetTime() != 110*time.Millisecond || simulation.steps[0] != 10*time.Millisecond {
+		t.Errorf("%d steps simulated %v", loop.GetSteps(), loop.GetTime())
+	}
+
+	// Slow motion needs twice the real time for a step.
+	loop.SetTimeScale(0.5)
+	loop.Advance(16 * time.Millisecond)
+	if loop.GetSteps() != 12 {
+		t.Errorf("slow motion ran %d steps, want 12", loop.GetSteps())
+	}
+	if err := loop.SetTimeScale(0); err == nil {
+		t.Error("expected an error for a time scale of 0")
+	}
+}
+
+func TestLoopPause(t *testing.T) {
+	simulation := &countingSimulation{}
+	loop, _ := NewLoop(simulation, time.Second/60)
+	if err := loop.StepOnce(); err == nil {
+		t.Error("expected an error for a single step while running")
+	}
+
+	// Update runs one tick of ebiten, which is one step at 60 TPS.
+	loop.Update()
+	simulation.paused = true
+	loop.Update()
+	loop.Update()
+	if loop.GetSteps() != 1 || !loop.IsPaused() {
+		t.Errorf("paused loop ran %d steps, want 1", loop.GetSteps())
+	}
+	loop.StepOnce()
+	loop.StepOnce()
+	loop.Update()
+	if loop.GetSteps() != 3 {
+		t.Errorf("single steps ran %d steps, want 3", loop.GetSteps())
+	}
+	loop.Update()
+	if loop.GetSteps() != 3 {
+		t.Error("single steps were repeated")
+	}
+
+	// Frames drawn between two updates move the alpha forward.
+	simulation.paused = false
+	loop.Update()
+	now := time.Now()
+	loop.(*gameLoop).lastUpdate = now
+	loop.(*gameLoop).now = func() time.Time { return now.Add(time.Second / 120) }
+	loop.Draw(nil)
+	if simulation.alpha < 0.49 || simulation.alpha > 0.51 {
+		t.Errorf("alpha half a step after the update is %v", simulation.alpha)
+	}
+
+	simulation.failStep = loop.GetSteps() + 1
+	if err := loop.Advance(time.Second); err == nil || loop.GetSteps() != simulation.failStep {
+		t.Errorf("the loop continued after a failed step: %v", err)
+	}
+}
+
+func TestLoopWithoutFixedTPS(t *testing.T) {
+	defer ebiten.SetTPS(ebiten.TPS())
+	simulation := &countingSimulation{}
+	loop, _ := NewLoop(simulation, time.Second/60)
+	now := time.Now()
+	loop.(*gameLoop).now = func() time.Time { return now }
+
+	// Without a fixed TPS the first tick lasts 1/DefaultTPS, the next ones the time since the previous Update.
+	ebiten.SetTPS(ebiten.SyncWithFPS)
+	if err := loop.Update(); err != nil || loop.GetSteps() != 1 || loop.GetTick() != time.Second/ebiten.DefaultTPS {
+		t.Fatalf("first update ran %d steps in a tick of %v: %v", loop.GetSteps(), loop.GetTick(), err)
+	}
+	now = now.Add(time.Second / 20)
+	loop.Update()
+	if loop.GetSteps() != 4 || loop.GetTick() != time.Second/20 {
+		t.Errorf("an update after 1/20 s ran %d steps in a tick of %v, want 3 more", loop.GetSteps(), loop.GetTick())
+	}
+	now = now.Add(-time.Second)
+	loop.Update()
+	if loop.GetSteps() != 4 || loop.GetTick() != 0 {
+		t.Errorf("a clock going back ran %d steps in a tick of %v", loop.GetSteps(), loop.GetTick())
+	}
+
+	ebiten.SetTPS(0)
+	now = now.Add(time.Second / 10)
+	loop.Update()
+	if loop.GetSteps() != 10 || loop.GetTick() != time.Second/10 {
+		t.Errorf("an update after 1/10 s with a TPS of 0 ran %d steps in a tick of %v, want 6 more", loop.GetSteps(), loop.GetTick())
+	}
+	loop.Draw(nil)
+	if simulation.alpha < 0 || simulation.alpha > 1 {
+		t.Errorf("alpha without a fixed TPS is %v", simulation.alpha)
+	}
+}
@@ -2,16 +2,39 @@
 package main
 
 import (
+	"Game_Engine/game"
+	"Game_Engine/input"
+	"Game_Engine/loop"
 	"Game_Engine/objects"
+	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"image"
 	"image/color"
+	"io/fs"
 	"log"
 	"os"
+	"runtime"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// Switches Draw between the scene with the player and the test of the primitives
+const tumbler = false
+
+// Images of the game compiled into the binary, so it runs from every working directory
+//
+//go:embed Hero
+var assetFiles embed.FS
+
+// File with the controls of the player, created with the default bindings when it is missing
+const bindingsFile = "bindings.json"
+
+// Time scale of the slow motion
+const slowMotion = 0.25
+
 // Variable which stores error logger
 var errorLogger *log.Logger
 
@@ -26,14 +49,81 @@ func init() {
 
 // Struct which holds crusial for engine objects
 type Game struct {
-	buttonImage                              *ebiten.Image
-	backgroundColor                          color.Color
-	IsPressed                                bool
-	xTranslate                               int
-	yTranslate                               int
-	translationSpeed                         int
-	angle                                    int
-	isRight, isLeft, isTop, isDown, isAttack bool
+	buttonImage     *ebiten.Image
+	backgroundColor color.Color
+	IsPressed       bool
+	state           *game.State
+	actions         input.ActionMap
+	controls        input.ActionMap
+	ticker          input.TickDevice
+	recorder        input.Recorder
+	renderer        objects.Renderer
+	tank            objects.Node
+	wall            objects.SquareObject
+	player          objects.PlayerObject
+	assets          objects.AssetManager
+	loader          objects.AssetLoader
+	loaded          bool
+}
+
+// Function which returns the controls used when the player has not rebound them:
+// arrows, WASD or the left stick to move, space, the left mouse button or the bottom face button to attack
+func defaultBindings() input.Bindings {
+	bindings := input.NewBindings()
+	bindings.Axes["move_x"] = map[input.Source]float64{
+		input.Key(ebiten.KeyArrowLeft):  -1,
+		input.Key(ebiten.KeyArrowRight): 1,
+		input.Key(ebiten.KeyA):          -1,
+		input.Key(ebiten.KeyD):          1,
+		input.GamepadAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, 0): 1,
+	}
+	bindings.Axes["move_y"] = map[input.Source]float64{
+		input.Key(ebiten.KeyArrowUp):   -1,
+		input.Key(ebiten.KeyArrowDown): 1,
+		input.Key(ebiten.KeyW):         -1,
+		input.Key(ebiten.KeyS):         1,
+		input.GamepadAxis(ebiten.StandardGamepadAxisLeftStickVertical, 0): 1,
+	}
+	bindings.Actions["attack"] = []input.Source{
+		input.Key(ebiten.KeySpace),
+		input.MouseButton(ebiten.MouseButtonLeft),
+		input.GamepadButton(ebiten.StandardGamepadButtonRightBottom),
+	}
+	bindings.Actions["speed_up"] = []input.Source{input.Key(ebiten.KeyZ), input.GamepadButton(ebiten.StandardGamepadButtonFrontTopRight)}
+	bindings.Actions["slow_down"] = []input.Source{input.Key(ebiten.KeyX), input.GamepadButton(ebiten.StandardGamepadButtonFrontTopLeft)}
+	bindings.Actions["rotate"] = []input.Source{input.Key(ebiten.KeyE), input.GamepadButton(ebiten.StandardGamepadButtonRightTop)}
+	bindings.Actions["pause"] = []input.Source{input.Key(ebiten.KeyP), input.GamepadButton(ebiten.StandardGamepadButtonCenterRight)}
+	bindings.Actions["single_step"] = []input.Source{input.Key(ebiten.KeyO)}
+	bindings.Actions["slow_motion"] = []input.Source{input.Key(ebiten.KeyM)}
+	return bindings
+}
+
+// Function which loads the controls from the bindings file, so the player can rebind them by editing it
+func loadBindings() input.Bindings {
+	defaults := defaultBindings()
+	bindings, err := input.LoadBindings(bindingsFile)
+	if err == nil {
+		// Actions added to the game after the file was written keep their default keys
+		for action, sources := range defaults.Actions {
+			if _, ok := bindings.Actions[action]; !ok {
+				bindings.Actions[action] = sources
+			}
+		}
+		for axis, sources := range defaults.Axes {
+			if _, ok := bindings.Axes[axis]; !ok {
+				bindings.Axes[axis] = sources
+			}
+		}
+		return bindings
+	}
+	bindings = defaults
+	if errors.Is(err, fs.ErrNotExist) {
+		err = bindings.Save(bindingsFile)
+	}
+	if err != nil {
+		logError(err)
+	}
+	return bindings
 }
 
 // Initalisation of Game with
@@ -42,19 +132,78 @@ func NewGame(screenWidth, screenHeight int) *Game {
 	buttonImage := ebiten.NewImage(200, 100)
 	buttonImage.Fill(color.RGBA{220, 220, 220, 255})
 
+	// Scene which is built once and redrawn by the renderer every frame: a line (turret) rides on a square (tank)
+	backgroundColor := color.Black
+	col := color.RGBA{150, 100, 200, 255}
+	scene := objects.NewScene(backgroundColor)
+	tank := objects.NewShapeNode(objects.EnhancedNewSquareObject(nil, backgroundColor, 100, 100, 100, col))
+	tank.GetTransformableObject().Scale(2)
+	turret := objects.NewShapeNode(objects.EnhancedNewLineObject(nil, backgroundColor, 150, 150, 250, 150, col))
+	err := tank.AddChild(turret)
+	if err != nil {
+		logError(err)
+	}
+	err = scene.AddNode(tank)
+	if err != nil {
+		logError(err)
+	}
+	err = scene.AddNode(objects.NewShapeNode(objects.EnhancedNewCircleObject(nil, backgroundColor, 100, 600, 40, col)))
+	if err != nil {
+		logError(err)
+	}
+	// Wall which the player can not walk through
+	wall := objects.EnhancedNewSquareObject(nil, backgroundColor, 150, 400, 250, col)
+	err = scene.AddNode(objects.NewShapeNode(wall))
+	if err != nil {
+		logError(err)
+	}
+
+	// Images are decoded once and shared by every object which loads the same file,
+	// the loader decodes them in the background while the loading screen is drawn
+	assets := objects.NewAssetManagerFS(assetFiles)
+	loader, err := objects.NewAssetLoader(assets, runtime.NumCPU())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loader.Start("Hero/hero.png")
+	if err != nil {
+		logError(err)
+	}
+
+	// Player which is created once, so its animation state carries over between frames
+	player := objects.NewPlayerObject(nil, backgroundColor, col, 100, 100)
+	err = player.GetSpriteObject().GetBitmapObject().GetBitmapHandler(0).SetAssetManager(assets)
+	if err != nil {
+		logError(err)
+	}
+	player.AddObstacle(wall.Collider())
+
+	// The game reads named actions, the keys, buttons and sticks behind them come from the bindings file
+	bindings := loadBindings()
+	actions, err := input.NewActionMap(input.NewEbitenDevice(), bindings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Pause and slow motion are read from the real devices also during a replay
+	controls, err := input.NewActionMap(input.NewEbitenDevice(), bindings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return &Game{
-		buttonImage:      buttonImage,
-		backgroundColor:  color.Black,
-		IsPressed:        false,
-		xTranslate:       0,
-		yTranslate:       0,
-		translationSpeed: 1,
-		angle:            0,
-		isRight:          false,
-		isTop:            false,
-		isAttack:         false,
-		isLeft:           false,
-		isDown:           false,
+		buttonImage:     buttonImage,
+		backgroundColor: color.Black,
+		IsPressed:       false,
+		state:           game.NewState(player),
+		actions:         actions,
+		controls:        controls,
+		renderer:        objects.NewRenderer(scene, backgroundColor),
+		tank:            tank,
+		wall:            wall,
+		player:          player,
+		assets:          assets,
+		loader:          loader,
+		loaded:          false,
 	}
 }
 
@@ -66,50 +215,100 @@ func (g *Game) setBackgroundColor(R int, G int, B int, A int) {
 	g.backgroundColor = color.RGBA{uint8(R), uint8(G), uint8(B), uint8(A)}
 }
 
-// Function which is beeing runned every tick to update information about game
-func (g *Game) Update() error {
+// Function which is called once the loader has preloaded the images, so loading the hero takes them from the cache
+func (g *Game) loadAssets() {
+	if err := g.loader.Err(); err != nil {
+		logError(err)
+	}
+	// The tags of the atlas (walk_right, idle, ...) become the animation clips of the player
+	err := g.player.LoadHeroAtlas("Hero/hero.json")
+	if err != nil {
+		logError(err)
+	}
+	g.loaded = true
+}
+
+// Function which waits for the preloaded images without a window, for the headless replay
+func (g *Game) waitForAssets() {
+	for !g.loaded {
+		g.loader.Update()
+		select {
+		case <-g.loader.Done():
+			g.loadAssets()
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
 
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		g.yTranslate = g.yTranslate - g.translationSpeed
-		g.isTop = true
-	} else {
-		g.isTop = false
+// Function which records the input of every step of the session, the recording is saved when the game ends
+func (g *Game) startRecording(steps int) error {
+	recorder, err := input.NewRecorder(g.actions.GetDevice(), g.actions.GetBindings(), steps)
+	if err != nil {
+		return err
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		g.yTranslate = g.yTranslate + g.translationSpeed
-		g.isDown = true
-	} else {
-		g.isDown = false
+	g.recorder, g.ticker = recorder, recorder
+	return g.actions.SetDevice(recorder)
+}
+
+// Function which replays a recording in the window instead of the real input, the game ends with the recording
+func (g *Game) startReplay(recording input.Recording) error {
+	replayer, err := input.NewReplayer(recording)
+	if err != nil {
+		return err
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		g.xTranslate = g.xTranslate - g.translationSpeed
-		g.isLeft = true
-	} else {
-		g.isLeft = false
+	if err := g.actions.SetBindings(recording.Bindings); err != nil {
+		return err
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		g.xTranslate = g.xTranslate + g.translationSpeed
-		g.isRight = true
-	} else {
-		g.isRight = false
+	g.ticker = replayer
+	return g.actions.SetDevice(replayer)
+}
+
+// Function which is called by the game loop once per tick before the steps, also while the game is paused
+func (g *Game) Control(gameLoop loop.Loop) error {
+	g.controls.Update(gameLoop.GetTick())
+	if g.controls.JustPressed("pause") {
+		gameLoop.SetPaused(!gameLoop.IsPaused())
+	}
+	if g.controls.JustPressed("single_step") && gameLoop.IsPaused() {
+		gameLoop.StepOnce()
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyX) {
-		if g.translationSpeed <= 1 {
-			g.translationSpeed = 1
+	if g.controls.JustPressed("slow_motion") {
+		if gameLoop.GetTimeScale() == 1 {
+			gameLoop.SetTimeScale(slowMotion)
 		} else {
-			g.translationSpeed = g.translationSpeed - 1
+			gameLoop.SetTimeScale(1)
 		}
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyZ) {
-		g.translationSpeed = g.translationSpeed + 1
-	}
+	return nil
+}
 
-	if ebiten.IsKeyPressed(ebiten.KeyE) {
-		if g.angle >= 360 {
-			g.angle = g.angle - 360
+// Function which is called by the game loop for every fixed step of the simulation with the duration of the step
+func (g *Game) Step(dt time.Duration) error {
+	if !g.loaded {
+		g.loader.Update()
+		select {
+		case <-g.loader.Done():
+			g.loadAssets()
+		default:
+			return nil
 		}
+	}
+
+	// A replay ends the game after its last step
+	if g.ticker != nil && !g.ticker.Next() {
+		return ebiten.Termination
+	}
+	g.actions.Update(dt)
+	return g.step(dt)
+}
 
-		g.angle = g.angle + 1
+// Function which runs the game logic of one step with the actions sampled for this step,
+// the headless replay calls it without a window
+func (g *Game) step(dt time.Duration) error {
+	err := g.state.Step(g.actions, dt)
+	if err != nil {
+		logError(err)
 	}
 	/*
 		IsCurPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
@@ -152,59 +351,41 @@ func (g *Game) Update() error {
 	return nil
 }
 
-// Draw function draws everything on screen, which is given as paramiter
-func (g *Game) Draw(screen *ebiten.Image) {
+// Draw function draws everything on screen, which is given as paramiter,
+// moving objects are drawn between their previous and current step by the alpha of the game loop
+func (g *Game) Draw(screen *ebiten.Image, alpha float64) {
 	ebiten.SetWindowTitle("Game Engine")
 	screenWidth, screenHeight := ebiten.WindowSize()
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(screenWidth)/2-100, float64(screenHeight)/2-50)
 	col := color.RGBA{150, 100, 200, 255}
+	// Loading screen with a bar which fills while the images are decoded
+	if !g.loaded {
+		x, y := screenWidth/2-100, screenHeight/2-10
+		screen.SubImage(image.Rect(x, y, x+200, y+20)).(*ebiten.Image).Fill(color.RGBA{70, 70, 70, 255})
+		width := int(200 * g.loader.Progress().Fraction())
+		if width > 0 {
+			screen.SubImage(image.Rect(x, y, x+width, y+20)).(*ebiten.Image).Fill(col)
+		}
+		return
+	}
 	//Test full layer of constructors
-	tumbler := false
 	if tumbler {
-		gmOb := objects.NewGameObject(screen, g.backgroundColor)
-		drawOb := objects.NewDrawableObject(gmOb)
-		tranOb := objects.NewTransformableObject(gmOb)
-		shapOb := objects.NewShapeObject(drawOb, tranOb)
-
-		squaOb1 := objects.NewSquareObject(shapOb, 100, 100, 100, col)
-		squaOb1.Draw()
-		squaOb1.Scale(2)
-		squaOb1.Rotate(-30)
-		squaOb1.Translate(200, 200)
-		squaOb2 := objects.EnhancedNewSquareObject(screen, g.backgroundColor, 100, 100, 100, col)
-		squaOb2.Draw()
-		squaOb2.Rotate(g.angle)
-		squaOb2.Scale(2)
-		squaOb2.Translate(100, 100)
-
-		lineOb1 := objects.EnhancedNewLineObject(screen, g.backgroundColor, 500, 500, 600, 600, col)
-
-		lineOb1.Draw()
-		lineOb1.Translate(-300, -400)
-		lineOb1.Scale(4)
-
-		lineOb1.Rotate(g.angle)
-		lineOb2 := objects.EnhancedNewLineObject(screen, g.backgroundColor, 500, 500, 600, 600, col)
-		lineOb2.Draw()
-		lineOb2.Translate(-400, -300)
-		lineOb2.Scale(4)
-
-		circOb1 := objects.EnhancedNewCircleObject(screen, g.backgroundColor, 100, 600, 40, col)
-		circOb1.Draw()
-		circOb1.Scale(2)
-		circOb1.Translate(0, -200)
-
-		x, y := 100, 100
-		player := objects.NewPlayerObject(screen, g.backgroundColor, col, x+g.xTranslate, y+g.yTranslate)
-		err := player.LoadHero("Movement")
-
-		player.SetRightMovement(createRange(12, 17))
-		player.SetLeftMovement(createRange(6, 11))
-		player.SetTopMovement(createRange(0, 5))
-		player.SetDownMovement(createRange(18, 23))
-		player.SetCalm(18)
-		err = player.Move(g.isRight, g.isLeft, g.isTop, g.isDown, g.isAttack, g.xTranslate, g.yTranslate)
+		state := g.state
+		x, y := loop.Lerp(state.PreviousX, state.X, alpha), loop.Lerp(state.PreviousY, state.Y, alpha)
+		g.tank.GetTransformableObject().Rotate(loop.Lerp(state.PreviousAngle, state.Angle, alpha))
+		g.tank.GetTransformableObject().Translate(x, y)
+		err := g.renderer.Render(screen)
+		if err != nil {
+			logError(err)
+		}
+
+		// The player stands at its position of the current step, it is drawn shifted back to the interpolated one
+		bitmapObject := g.player.GetSpriteObject().GetBitmapObject()
+		currentX, currentY := bitmapObject.GetBitmapHandler(0).GetCords()
+		bitmapObject.GetTransformableObject().Translate(x-float64(currentX), y-float64(currentY))
+		bitmapObject.GetDrawableObject().GetGameObject().SetScreen(screen)
+		err = g.player.Draw()
 		if err != nil {
 			logError(err)
 		}
@@ -261,13 +442,19 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		testEllipse := objects.NewPrimitiveRendererclass(screen, g.backgroundColor)
 		testEllipse.DrawEllipse(centerEllipse, 100, 50, col)
 		testBorderFill := objects.NewPrimitiveRendererclass(screen, g.backgroundColor)
-		testBorderFill.BorderFill(101, 102, col2, col)
+		err = testBorderFill.BorderFill(101, 102, col2, col)
+		if err != nil {
+			logError(err)
+		}
 
 		testFillSquare := objects.NewPrimitiveRendererclass(screen, g.backgroundColor)
 		testFillSquare.FillSquare(50, 200, 200, col)
 
 		testFloodFill := objects.NewPrimitiveRendererclass(screen, g.backgroundColor)
-		testFloodFill.FloodFill(951, 201, col, g.backgroundColor)
+		err = testFloodFill.FloodFill(951, 201, col, g.backgroundColor)
+		if err != nil {
+			logError(err)
+		}
 	}
 }
 
@@ -283,10 +470,56 @@ func logError(err error) {
 
 // Main function which create game and handle other functions so everything can work fine
 func main() {
-	tps := flag.Int("tps", 60, "Number of ticks per second (TPS)")
+	tps := flag.Int("tps", 60, "Number of ticks per second (TPS), -1 for a tick per frame, more ticks make the game smoother but not faster")
+	steps := flag.Int("steps", 60, "Number of fixed simulation steps per second")
+	dirty := flag.Bool("dirty", false, "Redraw only the changed areas of the scene")
+	record := flag.String("record", "", "File to record the input of the session to, for reproducing bugs")
+	replay := flag.String("replay", "", "File with a recorded input to replay instead of the real input")
+	headless := flag.Bool("headless", false, "Replay without a window as fast as possible and print the final state")
 	flag.Parse()
 	width, height := 800, 600
+	if *steps <= 0 {
+		log.Fatalf("invalid number of steps per second: %d", *steps)
+	}
+	// ebiten.SyncWithFPS (-1) ties the ticks to the frames, 0 would stop them
+	if *tps == 0 {
+		log.Fatalf("invalid number of ticks per second: %d", *tps)
+	}
 	game := NewGame(800, 600)
+	if *replay != "" {
+		recording, err := input.LoadRecording(*replay)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *headless {
+			// Every step lasts as long as a step of the recorded session, so the result is the same as in the window
+			game.waitForAssets()
+			err = input.RunHeadless(recording, game.actions, game.step)
+			if err != nil {
+				log.Fatal(err)
+			}
+			state := game.state
+			x, y := state.PlayerCords()
+			fmt.Printf("steps %d: translation (%.1f, %.1f), speed %.1f, angle %.1f, player at (%d, %d)\n",
+				recording.Ticks, state.X, state.Y, state.Speed, state.Angle, x, y)
+			return
+		}
+		err = game.startReplay(recording)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*steps = recording.TPS
+	} else if *record != "" {
+		err := game.startRecording(*steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *dirty {
+		// Partial redraw needs the pixels of the previous frame
+		ebiten.SetScreenClearedEveryFrame(false)
+		game.renderer.SetDirtyTracking(true)
+	}
 	if width <= 0 || height <= 0 {
 		logError(fmt.Errorf("invalid window size: %d x %d", width, height))
 	} else {
@@ -294,17 +527,19 @@ func main() {
 	}
 	ebiten.SetTPS(*tps)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
-	if err := ebiten.RunGame(game); err != nil {
+	// The game loop runs the fixed steps of the game, independent of the TPS
+	gameLoop, err := loop.NewLoop(game, time.Second/time.Duration(*steps))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ebiten.RunGame(gameLoop); err != nil {
 		logError(err)
 	}
-
-}
-
-// Help function for creating lists in range of given numbers
-func createRange(start, end int) []int {
-	var result []int
-	for i := start; i <= end; i++ {
-		result = append(result, i)
+	if game.recorder != nil {
+		err := game.recorder.Recording().Save(*record)
+		if err != nil {
+			logError(err)
+		}
 	}
-	return result
+
 }
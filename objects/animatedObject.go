@@ -2,9 +2,8 @@
 package objects
 
 import (
+	"encoding/json"
 	"errors"
-	"io/ioutil"
-	"strconv"
 )
 
 // AnimatedObject represents an object that can be animated by changing its frame.
@@ -24,34 +23,45 @@ type AnimatedObject interface {
 	// GetCurrentFrame returns the current frame of the animation.
 	// @return int: The current frame number of the animation.
 	GetCurrentFrame() int
+
+	// SaveState returns the current frame as JSON for a game-state snapshot.
+	// @return []byte: The saved state.
+	// @return error: Returns an error if the state can not be encoded.
+	SaveState() ([]byte, error)
+
+	// RestoreState sets the current frame saved by SaveState.
+	// @param data []byte: The saved state.
+	// @return error: Returns an error if the data is not a saved state or the frame is out of range.
+	RestoreState(data []byte) error
 }
 
 // AnimatedObject is an internal implementation of the AnimatedObject interface.
 // It contains a reference to a game object, the number of frames in the animation,
-// the current frame of the animation, and the name used to store animation data.
+// the current frame of the animation, and the name of the animation.
 type animatedObject struct {
 	gameObject     GameObject // The associated game object.
 	numberOfFrames int        // The total number of frames in the animation.
 	currentFrame   int        // The current frame of the animation.
-	name           string     // The name used to store and load animation data.
+	name           string     // The name of the animation.
+}
+
+// animatedState is the saved state of an animated object.
+type animatedState struct {
+	Frame int `json:"frame"` // The current frame.
 }
 
 // NewAnimatedObject creates a new instance of an animated object with the specified game object,
-// number of frames in the animation, and the name for storing animation data.
-// It also reads the current frame from a file based on the provided name.
+// number of frames in the animation, and the name of the animation.
+// The animation starts at frame 0, its state lives in memory and is only saved through SaveState.
 // @param gameObject GameObject: The game object to be associated with the animated object.
 // @param numberOfFrames int: The total number of frames in the animation.
-// @param name string: The name used to store the animation state in a file.
+// @param name string: The name of the animation.
 // @return AnimatedObject: A new instance of the animated object with the provided parameters.
 func NewAnimatedObject(gameObject GameObject, numberOfFrames int, name string) AnimatedObject {
-	// Load the current frame from a file
-	filename := name + ".txt"
-	data, _ := ioutil.ReadFile(filename)
-	current_f, _ := strconv.Atoi(string(data))
 	return &animatedObject{
 		gameObject:     gameObject,
 		numberOfFrames: numberOfFrames,
-		currentFrame:   current_f,
+		currentFrame:   0,
 		name:           name,
 	}
 }
@@ -63,20 +73,14 @@ func (animatedObject *animatedObject) GetGameObject() GameObject {
 }
 
 // Animate animates the object by changing its frame to the specified frame number.
-// If the frame number is valid (within the range of frames), it writes the new frame number to a file
-// and updates the current frame of the object.
+// If the frame number is valid (within the range of frames), it updates the current frame of the object.
 // @param numerOfFrame int: The frame number to animate to.
-// @return error: Returns an error if the frame number is invalid (greater than or equal to the maximum number of frames).
+// @return error: Returns an error if the frame number is invalid (negative or not less than the number of frames).
 func (animatedObject *animatedObject) Animate(numerOfFrame int) error {
-	if 0 <= numerOfFrame && numerOfFrame < animatedObject.numberOfFrames {
-		// Write the new frame number to a file
-		writeToFile(animatedObject.name+".txt", strconv.Itoa(numerOfFrame))
-		animatedObject.currentFrame = numerOfFrame
-	} else {
+	if numerOfFrame < 0 || numerOfFrame >= animatedObject.numberOfFrames {
 		// Return an error if the frame number is invalid
 		return errors.New("animation error: frame>maximum")
 	}
-	writeToFile(animatedObject.name+".txt", strconv.Itoa(numerOfFrame))
 	animatedObject.currentFrame = numerOfFrame
 	return nil
 }
@@ -86,3 +90,21 @@ func (animatedObject *animatedObject) Animate(numerOfFrame int) error {
 func (animatedObject *animatedObject) GetCurrentFrame() int {
 	return animatedObject.currentFrame
 }
+
+// SaveState returns the current frame as JSON for a game-state snapshot.
+// @return []byte: The saved state.
+// @return error: Returns an error if the state can not be encoded.
+func (animatedObject *animatedObject) SaveState() ([]byte, error) {
+	return json.Marshal(animatedState{Frame: animatedObject.currentFrame})
+}
+
+// RestoreState sets the current frame saved by SaveState.
+// @param data []byte: The saved state.
+// @return error: Returns an error if the data is not a saved state or the frame is out of range.
+func (animatedObject *animatedObject) RestoreState(data []byte) error {
+	var state animatedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	return animatedObject.Animate(state.Frame)
+}
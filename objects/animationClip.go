@@ -0,0 +1,77 @@
+package objects
+
+import (
+	"errors"
+	"time"
+)
+
+// PlaybackMode tells what an animation clip does after its last frame.
+type PlaybackMode int
+
+const (
+	PlayLoop     PlaybackMode = iota // Starts again from the first frame.
+	PlayOnce                         // Stays on the last frame and finishes.
+	PlayPingPong                     // Plays backwards to the first frame, then forwards again.
+)
+
+// DefaultAnimationFPS is the speed of clips which set neither FPS nor Durations.
+const DefaultAnimationFPS = 12
+
+// AnimationClip is a named sequence of sprite frames, the configuration of one animation (walk_right, attack, idle).
+type AnimationClip struct {
+	Name      string          // The name of the clip, also the name of its state in an AnimationStateMachine.
+	Frames    []int           // The indices of the sprite frames in playing order.
+	Durations []time.Duration // How long each frame is shown, nil to use FPS.
+	FPS       float64         // The frames shown per second when Durations is nil, 0 for DefaultAnimationFPS.
+	Mode      PlaybackMode    // What happens after the last frame.
+}
+
+// NewAnimationClip creates a clip playing at DefaultAnimationFPS.
+// @param name string: The name of the clip.
+// @param mode PlaybackMode: What happens after the last frame.
+// @param frames ...int: The indices of the sprite frames in playing order.
+// @return AnimationClip: The new clip.
+func NewAnimationClip(name string, mode PlaybackMode, frames ...int) AnimationClip {
+	return AnimationClip{Name: name, Frames: frames, Mode: mode}
+}
+
+// Validate checks that the clip can be played.
+// @return error: Returns an error if the clip has no name or frames, a non-positive duration, a negative FPS,
+// a number of durations different from the number of frames or an unknown mode.
+func (clip AnimationClip) Validate() error {
+	if clip.Name == "" {
+		return errors.New("animation error: clip name is empty")
+	}
+	if len(clip.Frames) == 0 {
+		return errors.New("animation error: clip has no frames")
+	}
+	if clip.Durations != nil && len(clip.Durations) != len(clip.Frames) {
+		return errors.New("animation error: clip durations do not match its frames")
+	}
+	for _, duration := range clip.Durations {
+		if duration <= 0 {
+			return errors.New("animation error: frame duration must be positive")
+		}
+	}
+	if clip.FPS < 0 {
+		return errors.New("animation error: clip FPS is negative")
+	}
+	if clip.Mode < PlayLoop || clip.Mode > PlayPingPong {
+		return errors.New("animation error: unknown playback mode")
+	}
+	return nil
+}
+
+// frameDuration returns how long a frame of the clip is shown.
+// @param index int: The position of the frame in the clip.
+// @return time.Duration: The duration of the frame.
+func (clip AnimationClip) frameDuration(index int) time.Duration {
+	if clip.Durations != nil {
+		return clip.Durations[index]
+	}
+	fps := clip.FPS
+	if fps == 0 {
+		fps = DefaultAnimationFPS
+	}
+	return max(time.Duration(float64(time.Second)/fps), 1)
+}
@@ -0,0 +1,302 @@
+package objects
+
+import (
+	"errors"
+	"time"
+)
+
+// AnimationEvent describes a moment of the playback of a clip.
+type AnimationEvent struct {
+	Clip  string // The name of the clip.
+	Index int    // The position of the frame in the clip.
+	Frame int    // The index of the sprite frame.
+}
+
+// AnimationPlayback is the position of the playback in a clip, saved with game-state snapshots.
+type AnimationPlayback struct {
+	Index    int           `json:"index"`              // The position of the current frame in the clip.
+	Elapsed  time.Duration `json:"elapsed,omitempty"`  // How long the current frame has been shown.
+	Backward bool          `json:"backward,omitempty"` // True while a ping-pong clip plays backwards.
+	Finished bool          `json:"finished,omitempty"` // True after a once clip has finished.
+}
+
+// AnimationPlayer plays an animation clip by elapsed time, so the speed of the animation depends neither
+// on the TPS nor on the refresh rate of the display.
+// Listeners registered with OnFrame, OnLoop and OnFinish are called during Play and Update,
+// for example to apply damage on the hit frame of an attack.
+type AnimationPlayer interface {
+	// Play starts a clip from its first frame, the OnFrame listeners are called for the first frame.
+	// @param clip AnimationClip: The clip to play.
+	// @return error: Returns an error if the clip is not valid.
+	Play(clip AnimationClip) error
+
+	// GetClip returns the clip being played.
+	// @return AnimationClip: The clip, empty before Play.
+	GetClip() AnimationClip
+
+	// Update advances the playback by the time elapsed since the previous update. A long time can skip
+	// several frames, the listeners are called for each of them in order.
+	// @param dt time.Duration: The elapsed time.
+	// @return error: Returns an error if no clip is played or the time is negative.
+	Update(dt time.Duration) error
+
+	// GetFrame returns the sprite frame currently shown.
+	// @return int: The index of the sprite frame.
+	// @return error: Returns an error if no clip is played.
+	GetFrame() (int, error)
+
+	// GetIndex returns the position of the current frame in the clip.
+	// @return int: The position of the frame.
+	GetIndex() int
+
+	// Seek shows a frame of the clip from its start, without calling the listeners. Used to restore a saved state.
+	// @param index int: The position of the frame in the clip.
+	// @return error: Returns an error if no clip is played or the position is out of range.
+	Seek(index int) error
+
+	// GetPlayback returns the position of the playback, to save it.
+	// @return AnimationPlayback: The position of the playback.
+	GetPlayback() AnimationPlayback
+
+	// SetPlayback continues the playback from a saved position, without calling the listeners.
+	// @param playback AnimationPlayback: The position returned by GetPlayback for the same clip.
+	// @return error: Returns an error if no clip is played or the position does not fit the clip.
+	SetPlayback(playback AnimationPlayback) error
+
+	// IsFinished tells whether a once clip has shown its last frame for its whole duration.
+	// @return bool: True if the clip has finished.
+	IsFinished() bool
+
+	// OnFrame registers a function called every time a frame starts to be shown.
+	// @param listener func(event AnimationEvent): The function to call.
+	// @return error: Returns an error if the listener is nil.
+	OnFrame(listener func(event AnimationEvent)) error
+
+	// OnLoop registers a function called every time a loop or ping-pong clip starts again from its first frame.
+	// @param listener func(event AnimationEvent): The function to call.
+	// @return error: Returns an error if the listener is nil.
+	OnLoop(listener func(event AnimationEvent)) error
+
+	// OnFinish registers a function called when a once clip finishes.
+	// @param listener func(event AnimationEvent): The function to call.
+	// @return error: Returns an error if the listener is nil.
+	OnFinish(listener func(event AnimationEvent)) error
+}
+
+// animationPlayer is an internal implementation of the AnimationPlayer interface.
+type animationPlayer struct {
+	clip     AnimationClip                // The clip being played.
+	playing  bool                         // True after the first call of Play.
+	index    int                          // The position of the current frame in the clip.
+	elapsed  time.Duration                // How long the current frame has been shown.
+	backward bool                         // True while a ping-pong clip plays backwards.
+	finished bool                         // True after a once clip has finished.
+	onFrame  []func(event AnimationEvent) // The listeners called when a frame starts.
+	onLoop   []func(event AnimationEvent) // The listeners called when a clip starts again.
+	onFinish []func(event AnimationEvent) // The listeners called when a once clip finishes.
+}
+
+// NewAnimationPlayer creates a player without a clip.
+// @return AnimationPlayer: A new animation player.
+func NewAnimationPlayer() AnimationPlayer {
+	return &animationPlayer{}
+}
+
+// Play starts a clip from its first frame.
+// @param clip AnimationClip: The clip to play.
+// @return error: Returns an error if the clip is not valid.
+func (animationPlayer *animationPlayer) Play(clip AnimationClip) error {
+	if err := clip.Validate(); err != nil {
+		return err
+	}
+	animationPlayer.clip = clip
+	animationPlayer.playing = true
+	animationPlayer.index = 0
+	animationPlayer.elapsed = 0
+	animationPlayer.backward = false
+	animationPlayer.finished = false
+	animationPlayer.emit(animationPlayer.onFrame)
+	return nil
+}
+
+// GetClip returns the clip being played.
+// @return AnimationClip: The clip.
+func (animationPlayer *animationPlayer) GetClip() AnimationClip {
+	return animationPlayer.clip
+}
+
+// Update advances the playback by the time elapsed since the previous update.
+// @param dt time.Duration: The elapsed time.
+// @return error: Returns an error if no clip is played or the time is negative.
+func (animationPlayer *animationPlayer) Update(dt time.Duration) error {
+	if !animationPlayer.playing {
+		return errors.New("animation error: no clip is played")
+	}
+	if dt < 0 {
+		return errors.New("animation error: elapsed time is negative")
+	}
+	if animationPlayer.finished {
+		return nil
+	}
+	animationPlayer.elapsed += dt
+	for !animationPlayer.finished {
+		duration := animationPlayer.clip.frameDuration(animationPlayer.index)
+		if animationPlayer.elapsed < duration {
+			break
+		}
+		animationPlayer.elapsed -= duration
+		animationPlayer.step()
+	}
+	return nil
+}
+
+// GetFrame returns the sprite frame currently shown.
+// @return int: The index of the sprite frame.
+// @return error: Returns an error if no clip is played.
+func (animationPlayer *animationPlayer) GetFrame() (int, error) {
+	if !animationPlayer.playing {
+		return 0, errors.New("animation error: no clip is played")
+	}
+	return animationPlayer.clip.Frames[animationPlayer.index], nil
+}
+
+// GetIndex returns the position of the current frame in the clip.
+// @return int: The position of the frame.
+func (animationPlayer *animationPlayer) GetIndex() int {
+	return animationPlayer.index
+}
+
+// Seek shows a frame of the clip from its start, without calling the listeners.
+// @param index int: The position of the frame in the clip.
+// @return error: Returns an error if no clip is played or the position is out of range.
+func (animationPlayer *animationPlayer) Seek(index int) error {
+	return animationPlayer.SetPlayback(AnimationPlayback{Index: index})
+}
+
+// GetPlayback returns the position of the playback.
+// @return AnimationPlayback: The position of the playback.
+func (animationPlayer *animationPlayer) GetPlayback() AnimationPlayback {
+	return AnimationPlayback{
+		Index:    animationPlayer.index,
+		Elapsed:  animationPlayer.elapsed,
+		Backward: animationPlayer.backward,
+		Finished: animationPlayer.finished,
+	}
+}
+
+// SetPlayback continues the playback from a saved position, without calling the listeners.
+// @param playback AnimationPlayback: The saved position.
+// @return error: Returns an error if no clip is played or the position does not fit the clip.
+func (animationPlayer *animationPlayer) SetPlayback(playback AnimationPlayback) error {
+	if !animationPlayer.playing {
+		return errors.New("animation error: no clip is played")
+	}
+	if playback.Index < 0 || playback.Index >= len(animationPlayer.clip.Frames) {
+		return errors.New("animation error: frame position out of range")
+	}
+	if playback.Elapsed < 0 {
+		return errors.New("animation error: elapsed time is negative")
+	}
+	if playback.Backward && animationPlayer.clip.Mode != PlayPingPong {
+		return errors.New("animation error: only ping-pong clips play backwards")
+	}
+	if playback.Finished && animationPlayer.clip.Mode != PlayOnce {
+		return errors.New("animation error: only once clips finish")
+	}
+	animationPlayer.index = playback.Index
+	animationPlayer.elapsed = playback.Elapsed
+	animationPlayer.backward = playback.Backward
+	animationPlayer.finished = playback.Finished
+	return nil
+}
+
+// IsFinished tells whether a once clip has finished.
+// @return bool: True if the clip has finished.
+func (animationPlayer *animationPlayer) IsFinished() bool {
+	return animationPlayer.finished
+}
+
+// OnFrame registers a function called every time a frame starts to be shown.
+// @param listener func(event AnimationEvent): The function to call.
+// @return error: Returns an error if the listener is nil.
+func (animationPlayer *animationPlayer) OnFrame(listener func(event AnimationEvent)) error {
+	if listener == nil {
+		return errors.New("animation error: listener is nil")
+	}
+	animationPlayer.onFrame = append(animationPlayer.onFrame, listener)
+	return nil
+}
+
+// OnLoop registers a function called every time a clip starts again from its first frame.
+// @param listener func(event AnimationEvent): The function to call.
+// @return error: Returns an error if the listener is nil.
+func (animationPlayer *animationPlayer) OnLoop(listener func(event AnimationEvent)) error {
+	if listener == nil {
+		return errors.New("animation error: listener is nil")
+	}
+	animationPlayer.onLoop = append(animationPlayer.onLoop, listener)
+	return nil
+}
+
+// OnFinish registers a function called when a once clip finishes.
+// @param listener func(event AnimationEvent): The function to call.
+// @return error: Returns an error if the listener is nil.
+func (animationPlayer *animationPlayer) OnFinish(listener func(event AnimationEvent)) error {
+	if listener == nil {
+		return errors.New("animation error: listener is nil")
+	}
+	animationPlayer.onFinish = append(animationPlayer.onFinish, listener)
+	return nil
+}
+
+// step moves to the frame following the current one according to the playback mode.
+func (animationPlayer *animationPlayer) step() {
+	last := len(animationPlayer.clip.Frames) - 1
+	switch animationPlayer.clip.Mode {
+	case PlayLoop:
+		if animationPlayer.index == last {
+			animationPlayer.index = 0
+			animationPlayer.emit(animationPlayer.onLoop)
+		} else {
+			animationPlayer.index++
+		}
+	case PlayOnce:
+		if animationPlayer.index == last {
+			animationPlayer.finished = true
+			animationPlayer.elapsed = 0
+			animationPlayer.emit(animationPlayer.onFinish)
+			return
+		}
+		animationPlayer.index++
+	case PlayPingPong:
+		if last == 0 {
+			animationPlayer.emit(animationPlayer.onLoop)
+			break
+		}
+		if animationPlayer.backward && animationPlayer.index == 0 || !animationPlayer.backward && animationPlayer.index == last {
+			animationPlayer.backward = !animationPlayer.backward
+		}
+		if animationPlayer.backward {
+			animationPlayer.index--
+		} else {
+			animationPlayer.index++
+		}
+		if animationPlayer.index == 0 {
+			animationPlayer.emit(animationPlayer.onLoop)
+		}
+	}
+	animationPlayer.emit(animationPlayer.onFrame)
+}
+
+// emit calls listeners with the current frame.
+// @param listeners []func(event AnimationEvent): The listeners to call.
+func (animationPlayer *animationPlayer) emit(listeners []func(event AnimationEvent)) {
+	event := AnimationEvent{
+		Clip:  animationPlayer.clip.Name,
+		Index: animationPlayer.index,
+		Frame: animationPlayer.clip.Frames[animationPlayer.index],
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
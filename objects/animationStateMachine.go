@@ -0,0 +1,297 @@
+package objects
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// AnyState is the source state of transitions which can be taken from every state.
+const AnyState = "*"
+
+// AnimationInput is what the player wants to do, it drives an AnimationStateMachine.
+type AnimationInput struct {
+	MoveX, MoveY float64 // The movement direction, x grows to the right and y downwards, (0, 0) when standing.
+	Attack       bool    // True while the attack is requested.
+}
+
+// NewAnimationInput converts pressed direction buttons to an input vector.
+// Opposite buttons cancel each other.
+// @param isRight, isLeft, isTop, isDown bool: The pressed direction buttons.
+// @param isAttack bool: The pressed attack button.
+// @return AnimationInput: The input.
+func NewAnimationInput(isRight, isLeft, isTop, isDown, isAttack bool) AnimationInput {
+	input := AnimationInput{Attack: isAttack}
+	if isRight {
+		input.MoveX++
+	}
+	if isLeft {
+		input.MoveX--
+	}
+	if isDown {
+		input.MoveY++
+	}
+	if isTop {
+		input.MoveY--
+	}
+	return input
+}
+
+// AnimationCondition decides whether a transition is taken for an input.
+type AnimationCondition func(input AnimationInput) bool
+
+// InputIdle is true when the input neither moves nor attacks.
+// @return AnimationCondition: The condition.
+func InputIdle() AnimationCondition {
+	return func(input AnimationInput) bool {
+		return input.MoveX == 0 && input.MoveY == 0 && !input.Attack
+	}
+}
+
+// InputAttack is true when the input requests an attack.
+// @return AnimationCondition: The condition.
+func InputAttack() AnimationCondition {
+	return func(input AnimationInput) bool {
+		return input.Attack
+	}
+}
+
+// InputDirection is true when the input moves exactly in one of the eight directions.
+// @param dx, dy int: The signs of the direction, -1, 0 or 1 (1, -1 is up-right).
+// @return AnimationCondition: The condition.
+func InputDirection(dx, dy int) AnimationCondition {
+	return func(input AnimationInput) bool {
+		return (dx != 0 || dy != 0) && sign(input.MoveX) == dx && sign(input.MoveY) == dy
+	}
+}
+
+// InputMoving is true when the input moves towards a direction, a zero component accepts any value
+// (InputMoving(1, 0) is true for right, up-right and down-right).
+// @param dx, dy int: The signs of the direction, -1, 0 or 1.
+// @return AnimationCondition: The condition.
+func InputMoving(dx, dy int) AnimationCondition {
+	return func(input AnimationInput) bool {
+		if input.MoveX == 0 && input.MoveY == 0 {
+			return false
+		}
+		return (dx == 0 || sign(input.MoveX) == dx) && (dy == 0 || sign(input.MoveY) == dy)
+	}
+}
+
+// AnimationTransition moves an AnimationStateMachine from one state to another.
+type AnimationTransition struct {
+	From      string             // The state the transition leaves, AnyState for every state.
+	To        string             // The state the transition enters, transitions to states without a clip are skipped.
+	Condition AnimationCondition // Must be true to take the transition, nil to take it always.
+	OnFinish  bool               // True to wait until the once clip of the From state has finished.
+}
+
+// AnimationStateMachine selects the animation clip to play from the input, so directions, attacks and
+// idle poses are configuration instead of code. Every clip is a state named after the clip.
+// Transitions are tested in the order they were added and the first one which matches is taken.
+// A state playing a once clip can only be left by its own transitions until the clip finishes,
+// so an attack is not interrupted by walking.
+type AnimationStateMachine interface {
+	// SetClip adds a clip as a state, or replaces the clip of an existing state.
+	// The first clip added becomes the current state.
+	// @param clip AnimationClip: The clip.
+	// @return error: Returns an error if the clip is not valid.
+	SetClip(clip AnimationClip) error
+
+	// GetClip returns the clip of a state.
+	// @param name string: The name of the state.
+	// @return AnimationClip: The clip.
+	// @return bool: False if there is no such state.
+	GetClip(name string) (AnimationClip, bool)
+
+	// AddTransition adds a transition tested after the existing ones.
+	// @param transition AnimationTransition: The transition.
+	// @return error: Returns an error if the From or To state is empty.
+	AddTransition(transition AnimationTransition) error
+
+	// SetState switches to a state and plays its clip from the start.
+	// @param name string: The name of the state.
+	// @return error: Returns an error if there is no such state.
+	SetState(name string) error
+
+	// GetState returns the name of the current state.
+	// @return string: The current state, empty before any clip is added.
+	GetState() string
+
+	// GetFrame returns the sprite frame of the current state.
+	// @return int: The index of the sprite frame.
+	// @return error: Returns an error if no clip was added.
+	GetFrame() (int, error)
+
+	// IsFinished tells whether the once clip of the current state has finished.
+	// @return bool: True if the clip has finished.
+	IsFinished() bool
+
+	// GetPlayer returns the player of the clips, used to listen to OnFrame, OnLoop and OnFinish events.
+	// @return AnimationPlayer: The animation player.
+	GetPlayer() AnimationPlayer
+
+	// Update takes the first matching transition for the input and advances the clip of the current state
+	// by the elapsed time. Entering a new state shows the first frame of its clip.
+	// @param input AnimationInput: The input.
+	// @param dt time.Duration: The time elapsed since the previous update.
+	// @return int: The sprite frame to show.
+	// @return error: Returns an error if no clip was added or the time is negative.
+	Update(input AnimationInput, dt time.Duration) (int, error)
+}
+
+// animationStateMachine is an internal implementation of the AnimationStateMachine interface.
+type animationStateMachine struct {
+	clips       map[string]AnimationClip // The clips by state name.
+	transitions []AnimationTransition    // The transitions in testing order.
+	state       string                   // The name of the current state.
+	player      AnimationPlayer          // The player of the clip of the current state.
+}
+
+// NewAnimationStateMachine creates a state machine without clips and transitions.
+// @return AnimationStateMachine: A new state machine.
+func NewAnimationStateMachine() AnimationStateMachine {
+	return &animationStateMachine{
+		clips:       make(map[string]AnimationClip),
+		transitions: nil,
+		state:       "",
+		player:      NewAnimationPlayer(),
+	}
+}
+
+// SetClip adds a clip as a state, or replaces the clip of an existing state.
+// @param clip AnimationClip: The clip.
+// @return error: Returns an error if the clip is not valid.
+func (machine *animationStateMachine) SetClip(clip AnimationClip) error {
+	if err := clip.Validate(); err != nil {
+		return err
+	}
+	clip.Frames = append([]int(nil), clip.Frames...)
+	if clip.Durations != nil {
+		clip.Durations = append([]time.Duration(nil), clip.Durations...)
+	}
+	machine.clips[clip.Name] = clip
+	if machine.state == "" || machine.state == clip.Name {
+		machine.state = clip.Name
+		return machine.player.Play(clip)
+	}
+	return nil
+}
+
+// GetClip returns the clip of a state.
+// @param name string: The name of the state.
+// @return AnimationClip: The clip.
+// @return bool: False if there is no such state.
+func (machine *animationStateMachine) GetClip(name string) (AnimationClip, bool) {
+	clip, ok := machine.clips[name]
+	return clip, ok
+}
+
+// AddTransition adds a transition tested after the existing ones.
+// @param transition AnimationTransition: The transition.
+// @return error: Returns an error if the From or To state is empty.
+func (machine *animationStateMachine) AddTransition(transition AnimationTransition) error {
+	if transition.From == "" || transition.To == "" {
+		return errors.New("animation error: transition needs a source and a target state")
+	}
+	machine.transitions = append(machine.transitions, transition)
+	return nil
+}
+
+// SetState switches to a state and plays its clip from the start.
+// @param name string: The name of the state.
+// @return error: Returns an error if there is no such state.
+func (machine *animationStateMachine) SetState(name string) error {
+	clip, ok := machine.clips[name]
+	if !ok {
+		return errors.New("animation error: unknown state " + name)
+	}
+	machine.state = name
+	return machine.player.Play(clip)
+}
+
+// GetState returns the name of the current state.
+// @return string: The current state.
+func (machine *animationStateMachine) GetState() string {
+	return machine.state
+}
+
+// GetFrame returns the sprite frame of the current state.
+// @return int: The index of the sprite frame.
+// @return error: Returns an error if no clip was added.
+func (machine *animationStateMachine) GetFrame() (int, error) {
+	if machine.state == "" {
+		return 0, errors.New("animation error: no clips")
+	}
+	return machine.player.GetFrame()
+}
+
+// IsFinished tells whether the once clip of the current state has finished.
+// @return bool: True if the clip has finished.
+func (machine *animationStateMachine) IsFinished() bool {
+	return machine.player.IsFinished()
+}
+
+// GetPlayer returns the player of the clips.
+// @return AnimationPlayer: The animation player.
+func (machine *animationStateMachine) GetPlayer() AnimationPlayer {
+	return machine.player
+}
+
+// Update takes the first matching transition for the input and advances the clip of the current state.
+// @param input AnimationInput: The input.
+// @param dt time.Duration: The time elapsed since the previous update.
+// @return int: The sprite frame to show.
+// @return error: Returns an error if no clip was added or the time is negative.
+func (machine *animationStateMachine) Update(input AnimationInput, dt time.Duration) (int, error) {
+	if machine.state == "" {
+		return 0, errors.New("animation error: no clips")
+	}
+	if next, ok := machine.nextState(input); ok && next != machine.state {
+		if err := machine.SetState(next); err != nil {
+			return 0, err
+		}
+		return machine.player.GetFrame()
+	}
+	if err := machine.player.Update(dt); err != nil {
+		return 0, err
+	}
+	return machine.player.GetFrame()
+}
+
+// nextState finds the first transition which can be taken from the current state.
+// @param input AnimationInput: The input.
+// @return string: The target state.
+// @return bool: False if no transition matches.
+func (machine *animationStateMachine) nextState(input AnimationInput) (string, bool) {
+	finished := machine.player.IsFinished()
+	locked := machine.clips[machine.state].Mode == PlayOnce && !finished
+	for _, transition := range machine.transitions {
+		if transition.From != machine.state && (transition.From != AnyState || locked) {
+			continue
+		}
+		if transition.OnFinish && !finished {
+			continue
+		}
+		if _, ok := machine.clips[transition.To]; !ok {
+			continue
+		}
+		if transition.Condition == nil || transition.Condition(input) {
+			return transition.To, true
+		}
+	}
+	return "", false
+}
+
+// sign returns -1, 0 or 1 for the sign of a number.
+// @param value float64: The number.
+// @return int: The sign.
+func sign(value float64) int {
+	if value == 0 || math.IsNaN(value) {
+		return 0
+	}
+	if value < 0 {
+		return -1
+	}
+	return 1
+}
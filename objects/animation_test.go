@@ -0,0 +1,158 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// tick is the duration of a frame of clips playing at DefaultAnimationFPS.
+const tick = time.Second / DefaultAnimationFPS
+
+// playFrames updates a state machine with the same input every tick and collects the frames.
+func playFrames(t *testing.T, machine AnimationStateMachine, input AnimationInput, updates int) []int {
+	t.Helper()
+	frames := make([]int, updates)
+	for i := range frames {
+		frame, err := machine.Update(input, tick)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames[i] = frame
+	}
+	return frames
+}
+
+func TestAnimationClipModes(t *testing.T) {
+	cases := []struct {
+		name string
+		clip AnimationClip
+		want []int
+	}{
+		{"loop", NewAnimationClip("a", PlayLoop, 1, 2, 3), []int{1, 2, 3, 1, 2, 3, 1}},
+		{"once", NewAnimationClip("a", PlayOnce, 1, 2, 3), []int{1, 2, 3, 3, 3, 3, 3}},
+		{"ping-pong", NewAnimationClip("a", PlayPingPong, 1, 2, 3), []int{1, 2, 3, 2, 1, 2, 3}},
+		{"durations", AnimationClip{Name: "a", Frames: []int{1, 2}, Durations: []time.Duration{3 * tick, tick}}, []int{1, 1, 1, 2, 1, 1, 1}},
+	}
+	for _, tc := range cases {
+		machine := NewAnimationStateMachine()
+		if err := machine.SetClip(tc.clip); err != nil {
+			t.Fatal(err)
+		}
+		first, _ := machine.GetFrame()
+		got := append([]int{first}, playFrames(t, machine, AnimationInput{}, len(tc.want)-1)...)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: frames = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAnimationClipValidate(t *testing.T) {
+	invalid := []AnimationClip{
+		{Frames: []int{1}},
+		{Name: "a"},
+		{Name: "a", Frames: []int{1, 2}, Durations: []time.Duration{tick}},
+		{Name: "a", Frames: []int{1}, Durations: []time.Duration{0}},
+		{Name: "a", Frames: []int{1}, FPS: -1},
+		{Name: "a", Frames: []int{1}, Mode: PlaybackMode(7)},
+	}
+	for _, clip := range invalid {
+		if err := NewAnimationStateMachine().SetClip(clip); err == nil {
+			t.Errorf("expected an error for %+v", clip)
+		}
+	}
+}
+
+func TestPlayerAnimationStateMachine(t *testing.T) {
+	machine := newPlayerAnimation()
+	for _, clip := range []AnimationClip{
+		NewAnimationClip(ClipIdle, PlayLoop, 18),
+		NewAnimationClip(ClipWalkRight, PlayLoop, 12, 13, 14),
+		NewAnimationClip(ClipWalkUp, PlayLoop, 0, 1),
+		NewAnimationClip(ClipAttack, PlayOnce, 24, 25),
+	} {
+		if err := machine.SetClip(clip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	right := NewAnimationInput(true, false, false, false, false)
+	if got := playFrames(t, machine, right, 4); !reflect.DeepEqual(got, []int{12, 13, 14, 12}) {
+		t.Errorf("walking right frames = %v", got)
+	}
+	// Without an up-right clip the diagonal keeps walking right instead of falling back to idle.
+	upRight := NewAnimationInput(true, false, true, false, false)
+	if got := playFrames(t, machine, upRight, 1); got[0] != 13 || machine.GetState() != ClipWalkRight {
+		t.Errorf("diagonal without its clip: state %q frame %v", machine.GetState(), got)
+	}
+	machine.SetClip(NewAnimationClip(ClipWalkUpRight, PlayLoop, 30, 31))
+	if got := playFrames(t, machine, upRight, 2); !reflect.DeepEqual(got, []int{30, 31}) {
+		t.Errorf("diagonal with its clip frames = %v", got)
+	}
+
+	// The attack is played to its end even if the player walks, then returns to idle.
+	attack := NewAnimationInput(false, false, false, false, true)
+	if got := playFrames(t, machine, attack, 1); got[0] != 24 {
+		t.Errorf("attack starts with frame %d, want 24", got[0])
+	}
+	if got := playFrames(t, machine, right, 4); !reflect.DeepEqual(got, []int{25, 25, 18, 12}) {
+		t.Errorf("attack interrupted or not finished: frames = %v", got)
+	}
+	if got := playFrames(t, machine, AnimationInput{}, 1); got[0] != 18 {
+		t.Errorf("standing still shows frame %d, want the idle frame 18", got[0])
+	}
+}
+
+func TestAnimationPlayerEvents(t *testing.T) {
+	player := NewAnimationPlayer()
+	var frames, loops, finishes []int
+	player.OnFrame(func(event AnimationEvent) { frames = append(frames, event.Index) })
+	player.OnLoop(func(event AnimationEvent) { loops = append(loops, event.Frame) })
+	player.OnFinish(func(event AnimationEvent) { finishes = append(finishes, event.Frame) })
+
+	attack := AnimationClip{Name: "attack", Frames: []int{7, 8, 9}, Durations: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond}, Mode: PlayOnce}
+	if err := player.Play(attack); err != nil {
+		t.Fatal(err)
+	}
+	// One long update skips the second frame but still reports it.
+	player.Update(120 * time.Millisecond)
+	if !reflect.DeepEqual(frames, []int{0, 1, 2}) || player.IsFinished() {
+		t.Errorf("frames after 120ms = %v, finished %v", frames, player.IsFinished())
+	}
+	player.Update(80 * time.Millisecond)
+	if !reflect.DeepEqual(finishes, []int{9}) || !player.IsFinished() {
+		t.Errorf("finish events = %v, want [9]", finishes)
+	}
+
+	// The speed depends on the clip FPS, not on how often Update is called.
+	walk := AnimationClip{Name: "walk", Frames: []int{1, 2}, FPS: 10}
+	player.Play(walk)
+	for i := 0; i < 100; i++ {
+		player.Update(10 * time.Millisecond)
+	}
+	if frame, _ := player.GetFrame(); len(loops) != 5 || frame != 1 {
+		t.Errorf("after one second at 10 FPS: %d loops, frame %d, want 5 loops on frame 1", len(loops), frame)
+	}
+	if err := player.Update(-time.Millisecond); err == nil {
+		t.Error("expected an error for a negative time")
+	}
+	if err := NewAnimationPlayer().Update(time.Millisecond); err == nil {
+		t.Error("expected an error without a clip")
+	}
+}
+
+func TestTickDurationWithoutFixedTPS(t *testing.T) {
+	defer ebiten.SetTPS(ebiten.TPS())
+	for _, tps := range []int{ebiten.SyncWithFPS, 0} {
+		ebiten.SetTPS(tps)
+		if got, want := tickDuration(), time.Second/ebiten.DefaultTPS; got != want {
+			t.Errorf("tick with TPS %d = %v, want %v", tps, got, want)
+		}
+	}
+	ebiten.SetTPS(120)
+	if got, want := tickDuration(), time.Second/120; got != want {
+		t.Errorf("tick with TPS 120 = %v, want %v", got, want)
+	}
+}
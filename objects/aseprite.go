@@ -0,0 +1,52 @@
+package objects
+
+import "slices"
+
+// Directions of the frame tags written by the JSON export of Aseprite.
+const (
+	AsepriteForward         = "forward"          // Plays the frames from the first to the last.
+	AsepriteReverse         = "reverse"          // Plays the frames from the last to the first.
+	AsepritePingPong        = "pingpong"         // Plays forwards, then backwards.
+	AsepritePingPongReverse = "pingpong_reverse" // Plays backwards, then forwards.
+)
+
+// Clips converts the tags of an atlas into animation clips named after the tags, the way Aseprite plays them.
+// The frames of a clip are the positions of the atlas frames, which are the indices of the bitmaps
+// of a SpriteObject loaded with LoadAtlas. A tag played once (repeat 1) becomes a PlayOnce clip,
+// every other repeat count loops for ever. The durations of the frames are used when every frame of the tag has one.
+// @return []AnimationClip: The clips in the order of the tags.
+func (atlas Atlas) Clips() []AnimationClip {
+	clips := make([]AnimationClip, 0, len(atlas.Tags))
+	for _, tag := range atlas.Tags {
+		var frames []int
+		for i := tag.From; i <= tag.To; i++ {
+			frames = append(frames, i)
+		}
+		if tag.Direction == AsepriteReverse || tag.Direction == AsepritePingPongReverse {
+			slices.Reverse(frames)
+		}
+		mode := PlayLoop
+		if tag.Direction == AsepritePingPong || tag.Direction == AsepritePingPongReverse {
+			mode = PlayPingPong
+		}
+		if tag.Repeat == 1 {
+			if mode == PlayPingPong {
+				// A single ping-pong goes there and back, without showing the turning frame twice.
+				for i := len(frames) - 2; i >= 0; i-- {
+					frames = append(frames, frames[i])
+				}
+			}
+			mode = PlayOnce
+		}
+		clip := AnimationClip{Name: tag.Name, Frames: frames, Mode: mode}
+		for _, frame := range frames {
+			if atlas.Frames[frame].Duration <= 0 {
+				clip.Durations = nil
+				break
+			}
+			clip.Durations = append(clip.Durations, atlas.Frames[frame].Duration)
+		}
+		clips = append(clips, clip)
+	}
+	return clips
+}
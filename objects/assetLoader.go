@@ -0,0 +1,198 @@
+package objects
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io/fs"
+)
+
+// LoadProgress tells how far an AssetLoader is, for example to draw a loading bar.
+type LoadProgress struct {
+	Loaded     int   // The number of images handed to the AssetManager.
+	Failed     int   // The number of files which could not be read or decoded.
+	Total      int   // The number of files to load.
+	Bytes      int64 // The size of the files read so far.
+	TotalBytes int64 // The size of all files, files which can not be found count as 0.
+}
+
+// Fraction returns the part of the files which are finished, loaded or failed.
+// @return float64: A number from 0 to 1, 1 when there is nothing to load.
+func (progress LoadProgress) Fraction() float64 {
+	if progress.Total == 0 {
+		return 1
+	}
+	return float64(progress.Loaded+progress.Failed) / float64(progress.Total)
+}
+
+// AssetLoader preloads images into an AssetManager without blocking the game loop.
+// The files are read and decoded by background goroutines, which only touch CPU memory, and the
+// decoded images are turned into *ebiten.Image by Update on the game loop, so a loading screen
+// can be drawn while the assets stream in. The files are read from the file system of the AssetManager.
+type AssetLoader interface {
+	// Start begins to load files in the background, it can be called once.
+	// @param paths ...string: The paths of the image files.
+	// @return error: Returns an error if the loader was already started.
+	Start(paths ...string) error
+
+	// Update hands the images decoded since the previous call to the AssetManager.
+	// It must be called from the game loop, for example once per tick in Game.Update.
+	// @return LoadProgress: The progress after the update.
+	Update() LoadProgress
+
+	// Progress returns the progress of the last Update.
+	// @return LoadProgress: The progress.
+	Progress() LoadProgress
+
+	// Done returns a channel which is closed when every file was handed over or failed.
+	// @return <-chan struct{}: The completion channel.
+	Done() <-chan struct{}
+
+	// Err returns the errors of the files which failed so far.
+	// @return error: The joined *AssetError of the failed files, nil if none failed.
+	Err() error
+}
+
+// decodedAsset is the result of a background goroutine for one file.
+type decodedAsset struct {
+	path  string      // The path of the file.
+	image image.Image // The decoded image, nil if the file failed.
+	size  int64       // The number of bytes read.
+	err   error       // The reason of the failure.
+}
+
+// assetLoader is an internal implementation of the AssetLoader interface.
+type assetLoader struct {
+	assets   AssetManager      // The manager receiving the images.
+	workers  int               // The number of goroutines decoding files.
+	started  bool              // True after Start.
+	results  chan decodedAsset // The decoded files waiting for Update.
+	done     chan struct{}     // Closed when every file is finished.
+	progress LoadProgress      // The progress of the last Update.
+	errs     []error           // The errors of the failed files.
+}
+
+// NewAssetLoader creates a loader which fills an asset manager.
+// @param assets AssetManager: The manager receiving the images.
+// @param workers int: The number of goroutines decoding files at the same time.
+// @return AssetLoader: A new asset loader.
+// @return error: Returns an error if the manager is nil or workers is not positive.
+func NewAssetLoader(assets AssetManager, workers int) (AssetLoader, error) {
+	if assets == nil {
+		return nil, errors.New("asset error: asset manager is nil")
+	}
+	if workers <= 0 {
+		return nil, errors.New("asset error: number of workers must be positive")
+	}
+	return &assetLoader{
+		assets:  assets,
+		workers: workers,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Start begins to load files in the background.
+// @param paths ...string: The paths of the image files.
+// @return error: Returns an error if the loader was already started.
+func (assetLoader *assetLoader) Start(paths ...string) error {
+	if assetLoader.started {
+		return errors.New("asset error: loader is already started")
+	}
+	assetLoader.started = true
+	assetLoader.progress.Total = len(paths)
+	for _, path := range paths {
+		if info, err := fs.Stat(assetLoader.assets.GetFileSystem(), assetPath(path)); err == nil {
+			assetLoader.progress.TotalBytes += info.Size()
+		}
+	}
+	if len(paths) == 0 {
+		close(assetLoader.done)
+		return nil
+	}
+
+	// The buffer holds every result, so the goroutines never wait for Update.
+	assetLoader.results = make(chan decodedAsset, len(paths))
+	jobs := make(chan string, len(paths))
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	fsys := assetLoader.assets.GetFileSystem()
+	for i := 0; i < min(assetLoader.workers, len(paths)); i++ {
+		go func() {
+			for path := range jobs {
+				assetLoader.results <- decodeAsset(fsys, path)
+			}
+		}()
+	}
+	return nil
+}
+
+// Update hands the images decoded since the previous call to the AssetManager.
+// @return LoadProgress: The progress after the update.
+func (assetLoader *assetLoader) Update() LoadProgress {
+	for assetLoader.results != nil {
+		select {
+		case result := <-assetLoader.results:
+			assetLoader.finish(result)
+		default:
+			return assetLoader.progress
+		}
+	}
+	return assetLoader.progress
+}
+
+// Progress returns the progress of the last Update.
+// @return LoadProgress: The progress.
+func (assetLoader *assetLoader) Progress() LoadProgress {
+	return assetLoader.progress
+}
+
+// Done returns a channel which is closed when every file was handed over or failed.
+// @return <-chan struct{}: The completion channel.
+func (assetLoader *assetLoader) Done() <-chan struct{} {
+	return assetLoader.done
+}
+
+// Err returns the errors of the files which failed so far.
+// @return error: The joined errors, nil if none failed.
+func (assetLoader *assetLoader) Err() error {
+	return errors.Join(assetLoader.errs...)
+}
+
+// finish records the result of one file and closes the completion channel after the last one.
+// @param result decodedAsset: The result of a background goroutine.
+func (assetLoader *assetLoader) finish(result decodedAsset) {
+	assetLoader.progress.Bytes += result.size
+	err := result.err
+	if err == nil {
+		err = assetLoader.assets.Add(result.path, result.image)
+	}
+	if err != nil {
+		assetLoader.progress.Failed++
+		assetLoader.errs = append(assetLoader.errs, err)
+	} else {
+		assetLoader.progress.Loaded++
+	}
+	if assetLoader.progress.Loaded+assetLoader.progress.Failed == assetLoader.progress.Total {
+		assetLoader.results = nil
+		close(assetLoader.done)
+	}
+}
+
+// decodeAsset reads and decodes an image file, it only uses CPU memory and is safe in any goroutine
+// as long as the file system is.
+// @param fsys fs.FS: The file system holding the file.
+// @param path string: The path of the image file.
+// @return decodedAsset: The decoded image or the failure.
+func decodeAsset(fsys fs.FS, path string) decodedAsset {
+	data, err := fs.ReadFile(fsys, assetPath(path))
+	if err != nil {
+		return decodedAsset{path: path, err: &AssetError{Op: "load", Path: path, Err: err}}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return decodedAsset{path: path, size: int64(len(data)), err: &AssetError{Op: "load", Path: path, Err: err}}
+	}
+	return decodedAsset{path: path, image: img, size: int64(len(data))}
+}
@@ -0,0 +1,82 @@
+package objects
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAssetLoaderPreloads(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		paths = append(paths, filepath.Join(dir, name))
+		writeImage(t, paths[len(paths)-1], 4)
+	}
+	broken := filepath.Join(dir, "broken.png")
+	if err := os.WriteFile(broken, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, broken, filepath.Join(dir, "missing.png"))
+
+	assets := NewAssetManager()
+	loader, err := NewAssetLoader(assets, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Start(paths...); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Start(paths...); err == nil {
+		t.Error("expected an error when starting twice")
+	}
+
+	// The game loop keeps running while the files are decoded.
+	waitLoader(t, loader)
+
+	progress := loader.Progress()
+	if progress.Loaded != 3 || progress.Failed != 2 || progress.Total != 5 || progress.Fraction() != 1 {
+		t.Errorf("progress = %+v", progress)
+	}
+	if progress.Bytes == 0 || progress.Bytes != progress.TotalBytes {
+		t.Errorf("read %d of %d bytes", progress.Bytes, progress.TotalBytes)
+	}
+	if err := loader.Err(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("errors = %v, want the missing file", err)
+	}
+	if assets.Len() != 3 || assets.RefCount(paths[0]) != 0 {
+		t.Errorf("manager has %d images with %d references", assets.Len(), assets.RefCount(paths[0]))
+	}
+	if _, err := assets.Load(paths[0]); err != nil || assets.Len() != 3 {
+		t.Errorf("a preloaded image must be taken from the cache: %v", err)
+	}
+
+	if _, err := NewAssetLoader(nil, 1); err == nil {
+		t.Error("expected an error without a manager")
+	}
+	empty, _ := NewAssetLoader(assets, 1)
+	empty.Start()
+	if _, open := <-empty.Done(); open || empty.Progress().Fraction() != 1 {
+		t.Error("a loader without files must be done at once")
+	}
+}
+
+// waitLoader updates a loader until it is done, the test fails if it takes longer than 5 seconds.
+func waitLoader(t *testing.T, loader AssetLoader) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		loader.Update()
+		select {
+		case <-loader.Done():
+			return
+		case <-timeout:
+			t.Fatalf("loading did not finish: %+v", loader.Progress())
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package objects
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Errors wrapped by AssetError, test them with errors.Is.
+var (
+	ErrAssetNotLoaded = errors.New("asset is not loaded")       // The path has no cached image.
+	ErrAssetInUse     = errors.New("asset is still referenced") // The image can not be unloaded while it is used.
+)
+
+// AssetError reports a failed operation of an AssetManager on one file.
+type AssetError struct {
+	Op   string // The operation, "load", "add", "release" or "unload".
+	Path string // The path of the file.
+	Err  error  // The cause, for example fs.ErrNotExist, a decoding error or ErrAssetInUse.
+}
+
+// Error returns the message of the error.
+// @return string: The message with the operation, the path and the cause.
+func (assetError *AssetError) Error() string {
+	return "asset error: " + assetError.Op + " " + assetError.Path + ": " + assetError.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+// @return error: The cause.
+func (assetError *AssetError) Unwrap() error {
+	return assetError.Err
+}
+
+// AssetManager loads every image file once and shares the *ebiten.Image between all its users.
+// Every Load takes a reference which is given back with Release. An image without references stays
+// cached, so it is not decoded again when it is loaded later, until it is unloaded explicitly.
+// Paths are paths of the file system of the manager, with slashes or the separators of the system.
+// The manager is used from the game loop and is not safe for concurrent use.
+type AssetManager interface {
+	// Load returns the image of a file, decoding it only when it is not cached, and takes a reference to it.
+	// @param path string: The path of the image file.
+	// @return *ebiten.Image: The shared image, it must not be modified.
+	// @return error: Returns an *AssetError if the file can not be read or decoded.
+	Load(path string) (*ebiten.Image, error)
+
+	// Add caches an image decoded elsewhere, for example by an AssetLoader, without taking a reference.
+	// It creates the *ebiten.Image, so it must be called from the game loop. A cached path keeps its image.
+	// @param path string: The path of the image file.
+	// @param img image.Image: The decoded image.
+	// @return error: Returns an *AssetError if the image is nil.
+	Add(path string, img image.Image) error
+
+	// Release gives back a reference taken by Load.
+	// @param path string: The path of the image file.
+	// @return error: Returns an *AssetError wrapping ErrAssetNotLoaded if the path has no reference.
+	Release(path string) error
+
+	// Unload removes an image without references from the cache and frees its memory.
+	// @param path string: The path of the image file.
+	// @return error: Returns an *AssetError wrapping ErrAssetNotLoaded or ErrAssetInUse.
+	Unload(path string) error
+
+	// UnloadUnused unloads every image without references.
+	// @return int: The number of unloaded images.
+	UnloadUnused() int
+
+	// RefCount returns the number of references to an image.
+	// @param path string: The path of the image file.
+	// @return int: The number of references, 0 also if the image is not cached.
+	RefCount(path string) int
+
+	// Len returns the number of cached images.
+	// @return int: The number of images.
+	Len() int
+
+	// GetFileSystem returns the file system the images are read from.
+	// @return fs.FS: The file system.
+	GetFileSystem() fs.FS
+}
+
+// asset is a cached image with its number of references.
+type asset struct {
+	image      *ebiten.Image // The decoded image.
+	references int           // The number of Load calls without Release.
+}
+
+// assetManager is an internal implementation of the AssetManager interface.
+type assetManager struct {
+	assets map[string]*asset // The cached images by cleaned path.
+	fsys   fs.FS             // The file system the images are read from.
+}
+
+// NewAssetManager creates an asset manager with an empty cache reading the files of the operating system.
+// @return AssetManager: A new asset manager.
+func NewAssetManager() AssetManager {
+	return NewAssetManagerFS(NewOSFileSystem())
+}
+
+// NewAssetManagerFS creates an asset manager with an empty cache reading a file system,
+// for example an embed.FS compiled into the game, so it does not depend on the working directory.
+// @param fsys fs.FS: The file system, nil for the one of the operating system.
+// @return AssetManager: A new asset manager.
+func NewAssetManagerFS(fsys fs.FS) AssetManager {
+	if fsys == nil {
+		fsys = NewOSFileSystem()
+	}
+	return &assetManager{assets: make(map[string]*asset), fsys: fsys}
+}
+
+// Load returns the image of a file, decoding it only when it is not cached, and takes a reference to it.
+// @param path string: The path of the image file.
+// @return *ebiten.Image: The shared image.
+// @return error: Returns an *AssetError if the file can not be read or decoded.
+func (assetManager *assetManager) Load(path string) (*ebiten.Image, error) {
+	key := assetPath(path)
+	cached, ok := assetManager.assets[key]
+	if !ok {
+		img, err := loadImage(assetManager.fsys, key)
+		if err != nil {
+			return nil, &AssetError{Op: "load", Path: key, Err: err}
+		}
+		cached = &asset{image: img}
+		assetManager.assets[key] = cached
+	}
+	cached.references++
+	return cached.image, nil
+}
+
+// Add caches an image decoded elsewhere without taking a reference.
+// @param path string: The path of the image file.
+// @param img image.Image: The decoded image.
+// @return error: Returns an *AssetError if the image is nil.
+func (assetManager *assetManager) Add(path string, img image.Image) error {
+	key := assetPath(path)
+	if img == nil {
+		return &AssetError{Op: "add", Path: key, Err: errors.New("image is nil")}
+	}
+	if _, ok := assetManager.assets[key]; !ok {
+		assetManager.assets[key] = &asset{image: ebiten.NewImageFromImage(img)}
+	}
+	return nil
+}
+
+// Release gives back a reference taken by Load.
+// @param path string: The path of the image file.
+// @return error: Returns an *AssetError wrapping ErrAssetNotLoaded if the path has no reference.
+func (assetManager *assetManager) Release(path string) error {
+	key := assetPath(path)
+	cached, ok := assetManager.assets[key]
+	if !ok || cached.references == 0 {
+		return &AssetError{Op: "release", Path: key, Err: ErrAssetNotLoaded}
+	}
+	cached.references--
+	return nil
+}
+
+// Unload removes an image without references from the cache and frees its memory.
+// @param path string: The path of the image file.
+// @return error: Returns an *AssetError wrapping ErrAssetNotLoaded or ErrAssetInUse.
+func (assetManager *assetManager) Unload(path string) error {
+	key := assetPath(path)
+	cached, ok := assetManager.assets[key]
+	if !ok {
+		return &AssetError{Op: "unload", Path: key, Err: ErrAssetNotLoaded}
+	}
+	if cached.references > 0 {
+		return &AssetError{Op: "unload", Path: key, Err: ErrAssetInUse}
+	}
+	cached.image.Deallocate()
+	delete(assetManager.assets, key)
+	return nil
+}
+
+// UnloadUnused unloads every image without references.
+// @return int: The number of unloaded images.
+func (assetManager *assetManager) UnloadUnused() int {
+	unloaded := 0
+	for key, cached := range assetManager.assets {
+		if cached.references == 0 {
+			cached.image.Deallocate()
+			delete(assetManager.assets, key)
+			unloaded++
+		}
+	}
+	return unloaded
+}
+
+// RefCount returns the number of references to an image.
+// @param path string: The path of the image file.
+// @return int: The number of references.
+func (assetManager *assetManager) RefCount(path string) int {
+	cached, ok := assetManager.assets[assetPath(path)]
+	if !ok {
+		return 0
+	}
+	return cached.references
+}
+
+// Len returns the number of cached images.
+// @return int: The number of images.
+func (assetManager *assetManager) Len() int {
+	return len(assetManager.assets)
+}
+
+// GetFileSystem returns the file system the images are read from.
+// @return fs.FS: The file system.
+func (assetManager *assetManager) GetFileSystem() fs.FS {
+	return assetManager.fsys
+}
@@ -0,0 +1,100 @@
+package objects
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+// writeImage writes an empty image file for the loaders.
+func writeImage(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := writePNG(path, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAssetManagerReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hero.png")
+	writeImage(t, path, 4)
+	assets := NewAssetManager()
+
+	first, err := assets.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := assets.Load(filepath.Join(filepath.Dir(path), ".", "hero.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || assets.Len() != 1 || assets.RefCount(path) != 2 {
+		t.Errorf("loading twice gave shared %v, %d images, %d references", first == second, assets.Len(), assets.RefCount(path))
+	}
+
+	var assetError *AssetError
+	if err := assets.Unload(path); !errors.As(err, &assetError) || !errors.Is(err, ErrAssetInUse) {
+		t.Errorf("unloading a used image: %v", err)
+	}
+	assets.Release(path)
+	assets.Release(path)
+	if err := assets.Release(path); !errors.Is(err, ErrAssetNotLoaded) {
+		t.Errorf("releasing too often: %v", err)
+	}
+	if assets.Len() != 1 || assets.UnloadUnused() != 1 || assets.Len() != 0 {
+		t.Error("an image without references must stay cached until it is unloaded")
+	}
+	if err := assets.Unload(path); !errors.Is(err, ErrAssetNotLoaded) {
+		t.Errorf("unloading twice: %v", err)
+	}
+
+	_, err = assets.Load(filepath.Join(t.TempDir(), "missing.png"))
+	if !errors.As(err, &assetError) || assetError.Op != "load" || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loading a missing file: %v", err)
+	}
+}
+
+func TestBitmapHandlersShareAssets(t *testing.T) {
+	dir := t.TempDir()
+	writeImage(t, filepath.Join(dir, "a.png"), 2)
+	writeImage(t, filepath.Join(dir, "b.png"), 3)
+	assets := NewAssetManager()
+	path := filepath.Join(dir, "a.png")
+
+	handlers := []BitmapHandler{NewBitmapHandler(0, 0), NewBitmapHandler(0, 0)}
+	for _, handler := range handlers {
+		handler.SetAssetManager(assets)
+		if err := handler.Load("a", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, _ := handlers[0].Get("a")
+	second, _ := handlers[1].Get("a")
+	if first != second || assets.RefCount(path) != 2 {
+		t.Errorf("handlers share %v with %d references", first == second, assets.RefCount(path))
+	}
+	handlers[0].Delete("a")
+	handlers[1].Load("a", filepath.Join(dir, "b.png"))
+	if assets.RefCount(path) != 0 || assets.Len() != 2 {
+		t.Errorf("replaced bitmaps keep %d references on %d images", assets.RefCount(path), assets.Len())
+	}
+
+	// Loading a sprite again takes the images from the cache and does not leak references.
+	sprite := NewSpriteObject(NewBitmapObject([]BitmapHandler{handlers[0]}, NewDrawableObject(NewGameObject(nil, goldenBackground))), "sprite")
+	for i := 0; i < 2; i++ {
+		if err := sprite.LoadBitmaps(dir, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if assets.Len() != 2 || assets.RefCount(path) != 1 {
+		t.Errorf("after loading the sprite twice: %d images, %d references", assets.Len(), assets.RefCount(path))
+	}
+
+	if err := NewBitmapHandler(0, 0).Load("a", filepath.Join(dir, "missing.png")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loading a missing file without a manager: %v", err)
+	}
+	if _, err := NewBitmapObject(handlers, NewDrawableObject(NewGameObject(nil, goldenBackground))).Bounds("z", 0); !errors.Is(err, ErrBitmapNotFound) {
+		t.Errorf("bounds of a missing bitmap: %v", err)
+	}
+}
@@ -1,9 +1,9 @@
 package objects
 
 import (
-	"image"
+	"errors"
 	"image/color"
-	"image/png"
+	"io/fs"
 	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,6 +14,15 @@ import (
 type bitmapHandler struct {
 	bitmaps map[string]*ebiten.Image // A map for storing bitmaps by their names.
 	x, y    int                      // Coordinates for positioning the bitmaps.
+	assets  AssetManager             // The cache used by Load, nil to decode every file.
+	fsys    fs.FS                    // The file system used by Load without an asset manager.
+	shared  map[string]sharedBitmap  // The bitmaps taken from an AssetManager by name.
+}
+
+// sharedBitmap remembers where a bitmap of a handler was taken from, to release it when it is replaced.
+type sharedBitmap struct {
+	assets AssetManager // The manager which holds the image.
+	path   string       // The path of the image file.
 }
 
 // BitmapHandler defines the interface for handling bitmaps.
@@ -31,12 +40,34 @@ type BitmapHandler interface {
 	Delete(name string)
 
 	// Load loads a bitmap from a file at the specified file path.
+	// With an AssetManager the image is shared with the other users of the file and released when the
+	// bitmap is replaced or deleted.
 	// @param name string: The name to assign to the loaded bitmap.
 	// @param filePath string: The file path of the image to load.
-	// @return error: Returns nil if the loading operation is successful, or an error if there is a failure.
+	// @return error: Returns nil if the loading operation is successful, or an *AssetError if there is a failure.
 	Load(name, filePath string) error
 
+	// SetAssetManager sets the cache used by Load. Bitmaps loaded before keep their image.
+	// @param assets AssetManager: The asset manager, nil to decode every file on Load.
+	// @return error: Returns nil if the asset manager is successfully set.
+	SetAssetManager(assets AssetManager) error
+
+	// GetAssetManager returns the cache used by Load.
+	// @return AssetManager: The asset manager, nil if there is none.
+	GetAssetManager() AssetManager
+
+	// SetFileSystem sets the file system used by Load when there is no AssetManager.
+	// @param fsys fs.FS: The file system, nil for the one of the operating system.
+	// @return error: Returns nil if the file system is successfully set.
+	SetFileSystem(fsys fs.FS) error
+
+	// GetFileSystem returns the file system the bitmaps are loaded from, the one of the AssetManager if there is one.
+	// @return fs.FS: The file system.
+	GetFileSystem() fs.FS
+
 	// Save saves the bitmap to a file at the specified file path.
+	// The format is chosen by the extension of the path: .png, .jpg or .jpeg, .gif or .bmp.
+	// The pixels are read back from the GPU, so it can only be called while the game runs.
 	// @param name string: The name of the bitmap to save.
 	// @param filePath string: The file path where the bitmap will be saved.
 	// @return error: Returns nil if the save operation is successful, or an error if there is a failure.
@@ -54,6 +85,12 @@ type BitmapHandler interface {
 	// @return bool: True if the bitmap was found, false otherwise.
 	Get(name string) (*ebiten.Image, bool)
 
+	// Set stores an existing image under a name, for example a frame cut from a sprite sheet.
+	// @param name string: The name of the bitmap.
+	// @param img *ebiten.Image: The image to store.
+	// @return error: Returns an error if the image is nil.
+	Set(name string, img *ebiten.Image) error
+
 	// GetCords retrieves the current coordinates of the bitmap handler.
 	// @return x, y int: The current x and y coordinates of the bitmap handler.
 	GetCords() (x, y int)
@@ -74,6 +111,9 @@ func NewBitmapHandler(x, y int) BitmapHandler {
 		bitmaps: make(map[string]*ebiten.Image),
 		x:       x,
 		y:       y,
+		assets:  nil,
+		fsys:    NewOSFileSystem(),
+		shared:  make(map[string]sharedBitmap),
 	}
 }
 
@@ -85,37 +125,77 @@ func NewBitmapHandler(x, y int) BitmapHandler {
 func (bh *bitmapHandler) Create(name string, width, height int, clr color.Color) {
 	img := ebiten.NewImage(width, height)
 	img.Fill(clr) // Fill the image with the specified color.
+	bh.release(name)
 	bh.bitmaps[name] = img
 }
 
 // Delete removes a bitmap by its name.
 // @param name string: The name of the bitmap to delete.
 func (bh *bitmapHandler) Delete(name string) {
+	bh.release(name)
 	delete(bh.bitmaps, name)
 }
 
 // Load loads a bitmap from a file and stores it with the given name.
 // @param name string: The name to assign to the loaded bitmap.
 // @param filePath string: The file path of the image to load.
-// @return error: Returns nil if the loading operation is successful, or an error if there is a failure.
+// @return error: Returns nil if the loading operation is successful, or an *AssetError if there is a failure.
 func (bh *bitmapHandler) Load(name, filePath string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return err
+	if bh.assets == nil {
+		bitmap, err := loadImage(bh.fsys, filePath)
+		if err != nil {
+			return &AssetError{Op: "load", Path: filePath, Err: err}
+		}
+		bh.release(name)
+		bh.bitmaps[name] = bitmap
+		return nil
 	}
-	defer file.Close()
-
-	img, _, err := image.Decode(file)
+	bitmap, err := bh.assets.Load(filePath)
 	if err != nil {
 		return err
 	}
-
-	bitmap := ebiten.NewImageFromImage(img)
+	bh.release(name)
 	bh.bitmaps[name] = bitmap
+	bh.shared[name] = sharedBitmap{assets: bh.assets, path: filePath}
+	return nil
+}
+
+// SetAssetManager sets the cache used by Load.
+// @param assets AssetManager: The asset manager, nil to decode every file on Load.
+// @return error: Returns nil if the asset manager is successfully set.
+func (bh *bitmapHandler) SetAssetManager(assets AssetManager) error {
+	bh.assets = assets
 	return nil
 }
 
-// Save saves the bitmap to a file at the specified file path.
+// GetAssetManager returns the cache used by Load.
+// @return AssetManager: The asset manager, nil if there is none.
+func (bh *bitmapHandler) GetAssetManager() AssetManager {
+	return bh.assets
+}
+
+// SetFileSystem sets the file system used by Load when there is no AssetManager.
+// @param fsys fs.FS: The file system, nil for the one of the operating system.
+// @return error: Returns nil if the file system is successfully set.
+func (bh *bitmapHandler) SetFileSystem(fsys fs.FS) error {
+	if fsys == nil {
+		fsys = NewOSFileSystem()
+	}
+	bh.fsys = fsys
+	return nil
+}
+
+// GetFileSystem returns the file system the bitmaps are loaded from.
+// @return fs.FS: The file system of the AssetManager, or the one set by SetFileSystem without a manager.
+func (bh *bitmapHandler) GetFileSystem() fs.FS {
+	if bh.assets != nil {
+		return bh.assets.GetFileSystem()
+	}
+	return bh.fsys
+}
+
+// Save saves the bitmap to a file at the specified file path in the format of its extension.
+// If the bitmap can not be encoded, the partially written file is removed.
 // @param name string: The name of the bitmap to save.
 // @param filePath string: The file path where the bitmap will be saved.
 // @return error: Returns nil if the save operation is successful, or an error if there is a failure.
@@ -124,16 +204,7 @@ func (bh *bitmapHandler) Save(name, filePath string) error {
 	if !exists {
 		return os.ErrNotExist
 	}
-
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	img := bitmap.SubImage(bitmap.Bounds()).(*image.RGBA)
-
-	return png.Encode(file, img)
+	return saveImage(readPixels(bitmap), filePath)
 }
 
 // Copy copies a bitmap from one name to another.
@@ -147,6 +218,7 @@ func (bh *bitmapHandler) Copy(srcName, destName string) error {
 	}
 
 	dst := ebiten.NewImageFromImage(src)
+	bh.release(destName)
 	bh.bitmaps[destName] = dst
 	return nil
 }
@@ -160,6 +232,19 @@ func (bh *bitmapHandler) Get(name string) (*ebiten.Image, bool) {
 	return img, exists
 }
 
+// Set stores an existing image under a name, the image is shared and not copied.
+// @param name string: The name of the bitmap.
+// @param img *ebiten.Image: The image to store.
+// @return error: Returns an error if the image is nil.
+func (bh *bitmapHandler) Set(name string, img *ebiten.Image) error {
+	if img == nil {
+		return errors.New("bitmap error: image is nil")
+	}
+	bh.release(name)
+	bh.bitmaps[name] = img
+	return nil
+}
+
 // GetCords retrieves the current coordinates of the bitmap handler.
 // @return x, y int: The current x and y coordinates of the bitmap handler.
 func (bh *bitmapHandler) GetCords() (x, y int) {
@@ -174,3 +259,14 @@ func (bh *bitmapHandler) SetCoords(x, y int) error {
 	bh.x, bh.y = x, y
 	return nil
 }
+
+// release gives back the image of a bitmap to the AssetManager it was loaded from, if any.
+// @param name string: The name of the bitmap which is replaced or deleted.
+func (bh *bitmapHandler) release(name string) {
+	shared, ok := bh.shared[name]
+	if !ok {
+		return
+	}
+	delete(bh.shared, name)
+	shared.assets.Release(shared.path)
+}
@@ -2,10 +2,13 @@ package objects
 
 import (
 	"errors"
-
-	"github.com/hajimehoshi/ebiten/v2"
+	"fmt"
+	"image"
 )
 
+// ErrBitmapNotFound is returned when a BitmapHandler has no bitmap with the requested name.
+var ErrBitmapNotFound = errors.New("bitmap error: name not exist")
+
 // BitmapObject represents an object that can manage multiple bitmaps, draw them, and access their handlers.
 // Also this object inherit BitmapHandler and DrawableObject
 type BitmapObject interface {
@@ -18,28 +21,69 @@ type BitmapObject interface {
 	// @return BitmapHandler: The BitmapHandler at the specified index.
 	GetBitmapHandler(num int) BitmapHandler
 
+	// GetTransformableObject returns the TransformableObject which scales, rotates and offsets the drawn bitmaps.
+	// @return TransformableObject: The transformable object associated with the BitmapObject.
+	GetTransformableObject() TransformableObject
+
 	// Draw renders the bitmap on the screen using the provided handler and bitmap name.
 	// @param name string: The name of the bitmap to be drawn.
 	// @param num int: The index of the BitmapHandler to use for drawing.
 	// @return error: Returns nil if the drawing operation is successful, or an error if there is a failure.
 	Draw(name string, num int) error
+
+	// DrawWithOptions renders the bitmap like Draw, but with the given options instead of the object's options.
+	// @param name string: The name of the bitmap to be drawn.
+	// @param num int: The index of the BitmapHandler to use for drawing.
+	// @param options DrawOptions: The flip, tint, alpha, blend mode and filter of this drawing.
+	// @return error: Returns an error if the bitmap is not found or the options are invalid.
+	DrawWithOptions(name string, num int, options DrawOptions) error
+
+	// SetDrawOptions sets the options used by Draw.
+	// @param options DrawOptions: The flip, tint, alpha, blend mode and filter.
+	// @return error: Returns an error if the options are invalid.
+	SetDrawOptions(options DrawOptions) error
+
+	// GetDrawOptions returns the options used by Draw.
+	// @return DrawOptions: The current options.
+	GetDrawOptions() DrawOptions
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the bitmap with the current transformations.
+	// @param name string: The name of the bitmap.
+	// @param num int: The index of the BitmapHandler which holds the bitmap.
+	// @return image.Rectangle: The bounding box of the bitmap.
+	// @return error: Returns an error if the bitmap is not found.
+	Bounds(name string, num int) (image.Rectangle, error)
+
+	// Contains tests whether a screen point hits the bitmap with the current transformations.
+	// @param name string: The name of the bitmap.
+	// @param num int: The index of the BitmapHandler which holds the bitmap.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point lies on the bitmap, false also if the bitmap is not found.
+	Contains(name string, num int, x, y int) bool
 }
 
 // bitmapObject is an implementation of the BitmapObject interface.
 // It stores a list of BitmapHandlers and a DrawableObject, and provides methods to retrieve and draw bitmaps.
 type bitmapObject struct {
-	bitmapHandlers []BitmapHandler // A slice of BitmapHandlers.
-	drawableObject DrawableObject  // The associated DrawableObject.
+	bitmapHandlers      []BitmapHandler     // A slice of BitmapHandlers.
+	drawableObject      DrawableObject      // The associated DrawableObject.
+	transformableObject TransformableObject // The transformation applied to the drawn bitmaps.
+	drawOptions         DrawOptions         // The flip, tint, alpha, blend mode and filter used by Draw.
 }
 
 // NewBitmapObject creates a new instance of bitmapObject with the provided BitmapHandlers and DrawableObject.
+// The bitmaps are drawn scaled 3 times by default, which can be changed through GetTransformableObject.
 // @param bitmapHandlers []BitmapHandler: The list of BitmapHandlers to be associated with the BitmapObject.
 // @param drawableObject DrawableObject: The DrawableObject associated with the BitmapObject.
 // @return BitmapObject: A new bitmapObject instance.
 func NewBitmapObject(bitmapHandlers []BitmapHandler, drawableObject DrawableObject) BitmapObject {
+	transformableObject := NewTransformableObject(drawableObject.GetGameObject())
+	transformableObject.Scale(3)
 	return &bitmapObject{
-		drawableObject: drawableObject,
-		bitmapHandlers: bitmapHandlers,
+		drawableObject:      drawableObject,
+		bitmapHandlers:      bitmapHandlers,
+		transformableObject: transformableObject,
+		drawOptions:         NewDrawOptions(),
 	}
 }
 
@@ -56,31 +100,127 @@ func (bitmapObject *bitmapObject) GetBitmapHandler(num int) BitmapHandler {
 	return bitmapObject.bitmapHandlers[num]
 }
 
+// GetTransformableObject returns the TransformableObject which scales, rotates and offsets the drawn bitmaps.
+// @return TransformableObject: The transformable object associated with the BitmapObject.
+func (bitmapObject *bitmapObject) GetTransformableObject() TransformableObject {
+	return bitmapObject.transformableObject
+}
+
+// SetDrawOptions sets the options used by Draw.
+// @param options DrawOptions: The flip, tint, alpha, blend mode and filter.
+// @return error: Returns an error if the options are invalid.
+func (bitmapObject *bitmapObject) SetDrawOptions(options DrawOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	bitmapObject.drawOptions = options
+	return nil
+}
+
+// GetDrawOptions returns the options used by Draw.
+// @return DrawOptions: The current options.
+func (bitmapObject *bitmapObject) GetDrawOptions() DrawOptions {
+	return bitmapObject.drawOptions
+}
+
 // Draw renders the bitmap with the specified name and handler index on the screen.
+// The bitmap is placed at the coordinates of the BitmapHandler and transformed by the object's
+// world matrix with its top-left corner as origin, the object's draw options are applied.
 // @param name string: The name of the bitmap to be drawn.
 // @param num int: The index of the BitmapHandler to use for drawing.
 // @return error: Returns nil if the drawing operation is successful, or an error if the bitmap is not found.
 func (bitmapObject *bitmapObject) Draw(name string, num int) error {
+	return bitmapObject.DrawWithOptions(name, num, bitmapObject.drawOptions)
+}
+
+// DrawWithOptions renders the bitmap like Draw, but with the given options instead of the object's options.
+// A flip mirrors the bitmap within its own box before the transformation, so the bounds do not change.
+// @param name string: The name of the bitmap to be drawn.
+// @param num int: The index of the BitmapHandler to use for drawing.
+// @param options DrawOptions: The flip, tint, alpha, blend mode and filter of this drawing.
+// @return error: Returns an error if the bitmap is not found or the options are invalid.
+func (bitmapObject *bitmapObject) DrawWithOptions(name string, num int, options DrawOptions) error {
 	screen := bitmapObject.GetDrawableObject().GetGameObject().GetScreen() // Retrieve the screen from the DrawableObject.
 	handler := bitmapObject.bitmapHandlers[num]                            // Get the BitmapHandler at the specified index.
 	img, exists := handler.Get(name)                                       // Retrieve the image by its name.
 
 	if !exists {
-		return errors.New("name not exist") // Return an error if the bitmap is not found.
+		return fmt.Errorf("%w: %q", ErrBitmapNotFound, name) // Return an error if the bitmap is not found.
+	}
+	if screen == nil {
+		return errors.New("bitmap error: canvas can not draw images")
+	}
+	if err := options.validate(); err != nil {
+		return err
+	}
+
+	bounds, err := bitmapObject.Bounds(name, num)
+	if err != nil {
+		return err
 	}
 
 	// Set up the drawing options.
-	op := &ebiten.DrawImageOptions{}
-	x, y := handler.GetCords() // Get the coordinates of the BitmapHandler.
-	x_, y_ := float64(x), float64(y)
-	scaleX, scaleY := 3.0, 3.0    // Scale factors (adjustable).
-	op.GeoM.Scale(scaleX, scaleY) // Apply scaling.
-	op.GeoM.Translate(x_, y_)     // Apply translation (positioning).
+	size := img.Bounds().Size()
+	op := options.imageOptions(bitmapObject.placement(handler), size.X, size.Y)
 
 	// Draw the bitmap on the screen.
 	screen.DrawImage(img, op)
 
 	// Draw the associated DrawableObject.
+	bitmapObject.GetDrawableObject().SetBounds(bounds)
 	bitmapObject.GetDrawableObject().Draw()
 	return nil
 }
+
+// Bounds returns the axis-aligned box of screen pixels covered by the bitmap with the current transformations.
+// @param name string: The name of the bitmap.
+// @param num int: The index of the BitmapHandler which holds the bitmap.
+// @return image.Rectangle: The bounding box of the bitmap.
+// @return error: Returns an error if the bitmap is not found.
+func (bitmapObject *bitmapObject) Bounds(name string, num int) (image.Rectangle, error) {
+	handler := bitmapObject.bitmapHandlers[num]
+	img, exists := handler.Get(name)
+	if !exists {
+		return image.Rectangle{}, fmt.Errorf("%w: %q", ErrBitmapNotFound, name)
+	}
+	transform := bitmapObject.placement(handler)
+	size := img.Bounds().Size()
+	xs, ys := make([]float64, 0, 4), make([]float64, 0, 4)
+	for _, corner := range [4][2]int{{0, 0}, {size.X, 0}, {size.X, size.Y}, {0, size.Y}} {
+		cornerX, cornerY := transform.Apply(float64(corner[0]), float64(corner[1]))
+		xs, ys = append(xs, cornerX), append(ys, cornerY)
+	}
+	return edgeBounds(xs, ys), nil
+}
+
+// placement returns the matrix which maps the pixels of a bitmap to the screen.
+// The coordinates of the BitmapHandler are the origin of the transformation,
+// so the bitmap follows the world transformation of its parent.
+// @param handler BitmapHandler: The handler which holds the bitmap.
+// @return Transform2D: The transformation of the bitmap.
+func (bitmapObject *bitmapObject) placement(handler BitmapHandler) Transform2D {
+	x, y := handler.GetCords() // Get the coordinates of the BitmapHandler.
+	bitmapObject.transformableObject.SetOrigin(float64(x), float64(y))
+	return IdentityTransform2D().Translate(float64(x), float64(y)).Concat(bitmapObject.transformableObject.GetWorldTransform())
+}
+
+// Contains tests whether a screen point hits the bitmap with the current transformations.
+// The point is mapped back to the pixels of the bitmap, transparent pixels count as hits.
+// @param name string: The name of the bitmap.
+// @param num int: The index of the BitmapHandler which holds the bitmap.
+// @param x, y int: The screen point.
+// @return bool: True if the point lies on the bitmap, false also if the bitmap is not found.
+func (bitmapObject *bitmapObject) Contains(name string, num int, x, y int) bool {
+	handler := bitmapObject.bitmapHandlers[num]
+	img, exists := handler.Get(name)
+	if !exists {
+		return false
+	}
+	inverse, err := bitmapObject.placement(handler).Invert()
+	if err != nil {
+		return false
+	}
+	localX, localY := inverse.Apply(float64(x)+0.5, float64(y)+0.5)
+	size := img.Bounds().Size()
+	return localX >= 0 && localY >= 0 && localX < float64(size.X) && localY < float64(size.Y)
+}
@@ -0,0 +1,165 @@
+package objects
+
+import (
+	"Game_Engine/collision"
+	"image"
+	"testing"
+)
+
+func TestShapeBoundsAndContains(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+
+	square := EnhancedNewSquareObject(canvas, goldenBackground, 20, 10, 10, goldenLine)
+	if got, want := square.Bounds(), image.Rect(10, 10, 31, 31); got != want {
+		t.Errorf("square bounds = %v, want %v", got, want)
+	}
+	// Rotated by 45 degrees the square becomes a diamond around its center (20, 20).
+	square.Rotate(45)
+	if got := square.Bounds(); got.Min.X > 6 || got.Max.X < 34 {
+		t.Errorf("rotated square bounds %v do not cover the diamond", got)
+	}
+	if square.Contains(11, 11) {
+		t.Error("corner of the unrotated square must be outside the diamond")
+	}
+	if !square.Contains(20, 7) || !square.Contains(20, 20) {
+		t.Error("tip and center of the diamond must be inside")
+	}
+
+	circle := EnhancedNewCircleObject(canvas, goldenBackground, 32, 32, 10, goldenLine)
+	circle.GetShapeObject().GetTransformableObject().ScaleXY(2, 1)
+	if got, want := circle.Bounds(), image.Rect(12, 22, 53, 43); got != want {
+		t.Errorf("ellipse bounds = %v, want %v", got, want)
+	}
+	if !circle.Contains(50, 32) || circle.Contains(32, 45) {
+		t.Error("ellipse containment ignores the non-uniform scale")
+	}
+
+	line := EnhancedNewLineObject(canvas, goldenBackground, 10, 50, 30, 50, goldenLine)
+	line.Rotate(90)
+	if !line.Contains(20, 40) || line.Contains(12, 50) {
+		t.Error("line containment ignores the rotation")
+	}
+}
+
+func TestBitmapBoundsAndContains(t *testing.T) {
+	handler := NewBitmapHandler(10, 10)
+	handler.Create("frame", 4, 2, goldenFill)
+	bitmap := NewBitmapObject([]BitmapHandler{handler}, NewDrawableObject(NewGameObject(nil, goldenBackground)))
+	// Bitmaps are drawn scaled 3 times, the bounds cover exactly the pixels Contains hits.
+	bounds, err := bitmap.Bounds("frame", 0)
+	if want := image.Rect(10, 10, 22, 16); err != nil || bounds != want {
+		t.Errorf("bitmap bounds = %v, want %v: %v", bounds, want, err)
+	}
+	for y := bounds.Min.Y - 1; y <= bounds.Max.Y; y++ {
+		for x := bounds.Min.X - 1; x <= bounds.Max.X; x++ {
+			if got, want := bitmap.Contains("frame", 0, x, y), image.Pt(x, y).In(bounds); got != want {
+				t.Errorf("Contains(%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestIsPointInPolygonThroughVertex(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	diamond := []Point2D{point(canvas, 20, 10), point(canvas, 30, 20), point(canvas, 20, 30), point(canvas, 10, 20)}
+	// The ray from these points runs exactly through the vertex (30, 20).
+	if !isPointInPolygon(point(canvas, 15, 20), diamond, canvas, goldenBackground) {
+		t.Error("point inside the diamond reported outside")
+	}
+	if isPointInPolygon(point(canvas, 5, 20), diamond, canvas, goldenBackground) {
+		t.Error("point left of the diamond reported inside")
+	}
+}
+
+func TestScenePick(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	scene := NewScene(goldenBackground)
+	below := NewShapeNode(EnhancedNewSquareObject(canvas, goldenBackground, 30, 10, 10, goldenLine))
+	above := NewShapeNode(EnhancedNewCircleObject(canvas, goldenBackground, 25, 25, 8, goldenFill))
+	scene.AddNode(above)
+	scene.AddNode(below)
+	above.SetZIndex(1)
+
+	if picked := scene.Pick(25, 25); picked != above {
+		t.Error("pick must return the node drawn on top")
+	}
+	if picked := scene.Pick(12, 12); picked != below {
+		t.Error("pick must fall through to the node below")
+	}
+	above.SetVisible(false)
+	if picked := scene.Pick(25, 25); picked != below {
+		t.Error("hidden nodes must not be picked")
+	}
+	if picked := scene.Pick(60, 60); picked != nil {
+		t.Error("pick outside all nodes must return nil")
+	}
+}
+
+func TestShapeColliders(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	square := EnhancedNewSquareObject(canvas, goldenBackground, 10, 0, 0, goldenLine)
+	square.Rotate(30)
+	square.GetShapeObject().GetTransformableObject().ScaleXY(2, 1)
+	box, ok := square.Collider().(collision.OBB)
+	if !ok {
+		t.Fatalf("collider of a rotated square is %T, want collision.OBB", square.Collider())
+	}
+	if !nearlyEqual(box.Angle, 30) || !nearlyEqual(box.HalfWidth, 10) || !nearlyEqual(box.HalfHeight, 5) {
+		t.Errorf("collider = %+v", box)
+	}
+	if x, y := box.Center().X, box.Center().Y; !nearlyEqual(x, 5) || !nearlyEqual(y, 5) {
+		t.Errorf("collider center = (%v, %v), want (5, 5)", x, y)
+	}
+
+	circle := EnhancedNewCircleObject(canvas, goldenBackground, 17, 10, 3, goldenLine)
+	if !collision.Overlaps(square.Collider(), circle.Collider()) {
+		t.Error("stretched square must reach the circle")
+	}
+	circle.GetShapeObject().GetTransformableObject().ScaleXY(1, 3)
+	if _, ok := circle.Collider().(collision.Polygon); !ok {
+		t.Errorf("collider of an ellipse is %T, want collision.Polygon", circle.Collider())
+	}
+}
+
+func TestBitmapDrawOptions(t *testing.T) {
+	options := NewDrawOptions()
+	options.FlipX = true
+	if x, y := options.flip(4, 2).Apply(0, 0); x != 4 || y != 0 {
+		t.Errorf("flipped corner = (%v, %v), want (4, 0)", x, y)
+	}
+	options.FlipY = true
+	if x, y := options.flip(4, 2).Apply(1, 0); x != 3 || y != 2 {
+		t.Errorf("flipped point = (%v, %v), want (3, 2)", x, y)
+	}
+
+	handler := NewBitmapHandler(10, 10)
+	handler.Create("frame", 4, 2, goldenFill)
+	bitmap := NewBitmapObject([]BitmapHandler{handler}, NewDrawableObject(NewGameObject(nil, goldenBackground)))
+	before, _ := bitmap.Bounds("frame", 0)
+	if err := bitmap.SetDrawOptions(options); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := bitmap.Bounds("frame", 0); after != before {
+		t.Errorf("flipping moved the bounds from %v to %v", before, after)
+	}
+	options.Transparency = 1.5
+	if err := bitmap.SetDrawOptions(options); err == nil {
+		t.Error("expected an error for a transparency above 1")
+	}
+	if !bitmap.GetDrawOptions().FlipX {
+		t.Error("invalid options replaced the valid ones")
+	}
+
+	// Options set field by field keep the bitmap visible.
+	flipped := DrawOptions{FlipX: true}
+	if err := flipped.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := flipped.imageOptions(IdentityTransform2D(), 4, 2).ColorScale.A(); got != 1 {
+		t.Errorf("alpha of the zero options = %v, want 1", got)
+	}
+	flipped.Transparency = 0.75
+	if got := flipped.imageOptions(IdentityTransform2D(), 4, 2).ColorScale.A(); got != 0.25 {
+		t.Errorf("alpha with transparency 0.75 = %v, want 0.25", got)
+	}
+}
@@ -0,0 +1,208 @@
+package objects
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Canvas represents a raster target that primitives can be drawn on.
+// It abstracts the screen so drawing code can run both on an ebiten window and headlessly on a plain image.
+type Canvas interface {
+	// Set sets the color of a single pixel.
+	// @param x, y int: Coordinates of the pixel.
+	// @param col color.Color: The color of the pixel.
+	Set(x, y int, col color.Color)
+
+	// At returns the color of a single pixel.
+	// @param x, y int: Coordinates of the pixel.
+	// @return color.Color: The color of the pixel.
+	At(x, y int) color.Color
+
+	// Bounds returns the drawable area of the canvas.
+	// @return image.Rectangle: The bounds of the canvas.
+	Bounds() image.Rectangle
+
+	// Fill fills the whole canvas with the specified color.
+	// @param col color.Color: The fill color.
+	Fill(col color.Color)
+}
+
+// ebitenCanvas is an implementation of the Canvas interface backed by an *ebiten.Image.
+type ebitenCanvas struct {
+	image *ebiten.Image // The ebiten image used as the raster target.
+}
+
+// NewEbitenCanvas creates a new canvas which draws on the given ebiten image.
+// @param screen *ebiten.Image: The ebiten image to draw on.
+// @return Canvas: A new canvas backed by the ebiten image.
+func NewEbitenCanvas(screen *ebiten.Image) Canvas {
+	return &ebitenCanvas{
+		image: screen,
+	}
+}
+
+// Set sets the color of a single pixel of the ebiten image.
+// @param x, y int: Coordinates of the pixel.
+// @param col color.Color: The color of the pixel.
+func (canvas *ebitenCanvas) Set(x, y int, col color.Color) {
+	canvas.image.Set(x, y, col)
+}
+
+// At returns the color of a single pixel of the ebiten image.
+// @param x, y int: Coordinates of the pixel.
+// @return color.Color: The color of the pixel.
+func (canvas *ebitenCanvas) At(x, y int) color.Color {
+	return canvas.image.At(x, y)
+}
+
+// Bounds returns the bounds of the ebiten image.
+// @return image.Rectangle: The bounds of the image.
+func (canvas *ebitenCanvas) Bounds() image.Rectangle {
+	return canvas.image.Bounds()
+}
+
+// Fill fills the whole ebiten image with the specified color.
+// @param col color.Color: The fill color.
+func (canvas *ebitenCanvas) Fill(col color.Color) {
+	canvas.image.Fill(col)
+}
+
+// GetImage returns the ebiten image behind the canvas.
+// @return *ebiten.Image: The ebiten image.
+func (canvas *ebitenCanvas) GetImage() *ebiten.Image {
+	return canvas.image
+}
+
+// imageCanvas is an implementation of the Canvas interface backed by an *image.RGBA.
+// It does not need a window or a graphics driver, so it can be used in tests.
+type imageCanvas struct {
+	image *image.RGBA // The in-memory image used as the raster target.
+}
+
+// NewImageCanvas creates a new in-memory canvas with the specified size.
+// @param width, height int: The size of the canvas.
+// @return Canvas: A new canvas backed by an *image.RGBA.
+func NewImageCanvas(width, height int) Canvas {
+	return &imageCanvas{
+		image: image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+}
+
+// NewImageCanvasFromRGBA creates a new canvas which draws on the given in-memory image.
+// @param img *image.RGBA: The image to draw on.
+// @return Canvas: A new canvas backed by the image.
+func NewImageCanvasFromRGBA(img *image.RGBA) Canvas {
+	return &imageCanvas{
+		image: img,
+	}
+}
+
+// Set sets the color of a single pixel of the image.
+// @param x, y int: Coordinates of the pixel.
+// @param col color.Color: The color of the pixel.
+func (canvas *imageCanvas) Set(x, y int, col color.Color) {
+	canvas.image.Set(x, y, col)
+}
+
+// At returns the color of a single pixel of the image.
+// @param x, y int: Coordinates of the pixel.
+// @return color.Color: The color of the pixel.
+func (canvas *imageCanvas) At(x, y int) color.Color {
+	return canvas.image.At(x, y)
+}
+
+// Bounds returns the bounds of the image.
+// @return image.Rectangle: The bounds of the image.
+func (canvas *imageCanvas) Bounds() image.Rectangle {
+	return canvas.image.Bounds()
+}
+
+// Fill fills the whole image with the specified color.
+// @param col color.Color: The fill color.
+func (canvas *imageCanvas) Fill(col color.Color) {
+	draw.Draw(canvas.image, canvas.image.Bounds(), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// GetImage returns the in-memory image behind the canvas.
+// @return *image.RGBA: The image.
+func (canvas *imageCanvas) GetImage() *image.RGBA {
+	return canvas.image
+}
+
+// ebitenImageOf returns the ebiten image behind a canvas, or nil if the canvas is not backed by ebiten.
+// @param canvas Canvas: The canvas to inspect.
+// @return *ebiten.Image: The ebiten image or nil.
+func ebitenImageOf(canvas Canvas) *ebiten.Image {
+	switch c := canvas.(type) {
+	case *ebiten.Image:
+		return c
+	case *ebitenCanvas:
+		return c.image
+	}
+	return nil
+}
+
+// clippedCanvas is an implementation of the Canvas interface which limits drawing on another canvas to a rectangle.
+type clippedCanvas struct {
+	canvas Canvas          // The canvas drawn on.
+	clip   image.Rectangle // The area which can be changed.
+}
+
+// clipCanvas returns a canvas which draws on the given canvas only inside the rectangle.
+// Coordinates are not shifted, so objects are drawn at the same place as on the whole canvas.
+// Images and ebiten screens are clipped with SubImage, so bitmaps drawn with DrawImage are clipped as well.
+// @param canvas Canvas: The canvas to draw on.
+// @param clip image.Rectangle: The area which can be changed.
+// @return Canvas: The clipped canvas.
+func clipCanvas(canvas Canvas, clip image.Rectangle) Canvas {
+	switch c := canvas.(type) {
+	case *ebiten.Image:
+		return c.SubImage(clip).(*ebiten.Image)
+	case *ebitenCanvas:
+		return NewEbitenCanvas(c.image.SubImage(clip).(*ebiten.Image))
+	case *imageCanvas:
+		return NewImageCanvasFromRGBA(c.image.SubImage(clip).(*image.RGBA))
+	}
+	return &clippedCanvas{
+		canvas: canvas,
+		clip:   clip.Intersect(canvas.Bounds()),
+	}
+}
+
+// Set sets the color of a single pixel if it lies inside the clip rectangle.
+// @param x, y int: Coordinates of the pixel.
+// @param col color.Color: The color of the pixel.
+func (canvas *clippedCanvas) Set(x, y int, col color.Color) {
+	if image.Pt(x, y).In(canvas.clip) {
+		canvas.canvas.Set(x, y, col)
+	}
+}
+
+// At returns the color of a single pixel, transparent outside the clip rectangle.
+// @param x, y int: Coordinates of the pixel.
+// @return color.Color: The color of the pixel.
+func (canvas *clippedCanvas) At(x, y int) color.Color {
+	if !image.Pt(x, y).In(canvas.clip) {
+		return color.RGBA{}
+	}
+	return canvas.canvas.At(x, y)
+}
+
+// Bounds returns the clip rectangle.
+// @return image.Rectangle: The bounds of the clipped canvas.
+func (canvas *clippedCanvas) Bounds() image.Rectangle {
+	return canvas.clip
+}
+
+// Fill fills the clip rectangle with the specified color.
+// @param col color.Color: The fill color.
+func (canvas *clippedCanvas) Fill(col color.Color) {
+	for y := canvas.clip.Min.Y; y < canvas.clip.Max.Y; y++ {
+		for x := canvas.clip.Min.X; x < canvas.clip.Max.X; x++ {
+			canvas.canvas.Set(x, y, col)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package objects
 
 import (
+	"Game_Engine/collision"
+	"errors"
+	"image"
 	"image/color"
-
-	"github.com/hajimehoshi/ebiten/v2"
+	"math"
 )
 
-// CircleObject represents a circle object that can be drawn, transformed (scaled, rotated, translated), and erased from the screen.
+// CircleObject represents a circle object that can be drawn, transformed (scaled, rotated, translated), and hidden.
+// The circle is retained: it records its geometry and transformation, and is rasterized only by Draw,
+// usually called by a Renderer which clears and redraws the frame.
 // It provides methods for manipulating the circle and applying transformations to it.
 // Also this object inherit ShapeObject and use primitive for drawing circle
 type CircleObject interface {
@@ -18,38 +22,61 @@ type CircleObject interface {
 	// @return error: Returns nil if the drawing operation is successful.
 	Draw() error
 
-	// UnDraw erases the circle from the screen.
+	// UnDraw hides the circle, so it is skipped when the frame is redrawn. No pixels are changed.
 	// @return error: Returns nil if the erase operation is successful.
 	UnDraw() error
 
 	// Translate moves the circle by a given offset on the x and y axes.
-	// @param x int: The offset on the x-axis.
-	// @param y int: The offset on the y-axis.
+	// @param x float64: The offset on the x-axis.
+	// @param y float64: The offset on the y-axis.
 	// @return error: Returns nil if the translation operation is successful.
-	Translate(x, y int) error
+	Translate(x, y float64) error
 
 	// Scale changes the scale of the circle by a given factor.
-	// @param S int: The scale factor.
+	// @param S float64: The scale factor.
 	// @return error: Returns nil if the scaling operation is successful.
-	Scale(S int) error
+	Scale(S float64) error
 
 	// Rotate rotates the circle by a given angle.
-	// @param angle int: The angle to rotate the circle.
+	// @param angle float64: The angle in degrees to rotate the circle.
 	// @return error: Returns nil if the rotation operation is successful.
-	Rotate(angle int) error
+	Rotate(angle float64) error
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the circle with its current transformations.
+	// @return image.Rectangle: The bounding box of the circle.
+	Bounds() image.Rectangle
+
+	// SetColor sets the color of the circle.
+	// @param color color.Color: The new color of the circle.
+	// @return error: Returns an error if the color is nil.
+	SetColor(color color.Color) error
+
+	// GetColor returns the color of the circle.
+	// @return color.Color: The color of the circle.
+	GetColor() color.Color
+
+	// Contains tests whether a screen point hits the circle with its current transformations.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point is inside the circle or on its outline.
+	Contains(x, y int) bool
+
+	// Collider returns the shape of the transformed circle for the collision package.
+	// @return collision.Shape: A circle, or a polygon approximating the ellipse of a non-uniformly scaled circle.
+	Collider() collision.Shape
 }
 
 // circleObject is the internal implementation of the CircleObject interface.
-// It contains the shape object, the center of the circle, its radius, a primitive renderer, and its color.
+// It contains the shape object, the center of the circle, its radius, and its color.
+// The circle is drawn with a primitive renderer on the current canvas of its game object.
 type circleObject struct {
-	shapeObject ShapeObject            // The associated shape object for the circle.
-	center      Point2D                // The center of the circle (coordinates).
-	radius      int                    // The radius of the circle.
-	primitive   PrimitiveRendererСlass // The renderer for drawing the circle.
-	color       color.Color            // The color of the circle.
+	shapeObject ShapeObject // The associated shape object for the circle.
+	center      Point2D     // The center of the circle (coordinates).
+	radius      int         // The radius of the circle.
+	color       color.Color // The color of the circle.
 }
 
 // NewCircleObject creates a new circle object with the specified shape object, center coordinates, radius, and color.
+// The center of the circle becomes the origin of its transformation.
 // @param shapeObject ShapeObject: The associated shape object for the circle.
 // @param x int: The x-coordinate of the circle's center.
 // @param y int: The y-coordinate of the circle's center.
@@ -57,34 +84,28 @@ type circleObject struct {
 // @param color color.Color: The color of the circle.
 // @return CircleObject: The new circle object instance.
 func NewCircleObject(shapeObject ShapeObject, x, y, r int, color color.Color) CircleObject {
+	shapeObject.GetTransformableObject().SetOrigin(float64(x), float64(y))
 	return &circleObject{
 		shapeObject: shapeObject,
-		center:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
+		center:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetCanvas(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
 		color:       color,
 		radius:      r,
-		primitive:   NewPrimitiveRendererclass(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
 	}
 }
 
 // EnhancedNewCircleObject creates a new circle object with the specified screen, background color, center coordinates, radius, and color.
 // This method also initializes a new game object and shape object.
-// @param screen *ebiten.Image: The screen where the circle will be drawn.
+// @param screen Canvas: The canvas where the circle will be drawn (an *ebiten.Image can be passed directly).
 // @param backgroundColor color.Color: The background color for the circle.
 // @param x int: The x-coordinate of the circle's center.
 // @param y int: The y-coordinate of the circle's center.
 // @param r int: The radius of the circle.
 // @param color color.Color: The color of the circle.
 // @return CircleObject: The new circle object instance.
-func EnhancedNewCircleObject(screen *ebiten.Image, backgroundColor color.Color, x, y, r int, color color.Color) CircleObject {
-	gmob := NewGameObject(screen, backgroundColor)
+func EnhancedNewCircleObject(screen Canvas, backgroundColor color.Color, x, y, r int, color color.Color) CircleObject {
+	gmob := NewCanvasGameObject(screen, backgroundColor)
 	shapeObject := NewShapeObject(NewDrawableObject(gmob), NewTransformableObject(gmob))
-	return &circleObject{
-		shapeObject: shapeObject,
-		center:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
-		radius:      r,
-		color:       color,
-		primitive:   NewPrimitiveRendererclass(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
-	}
+	return NewCircleObject(shapeObject, x, y, r, color)
 }
 
 // GetShapeObject returns the associated shape object for the circle.
@@ -96,48 +117,139 @@ func (circleObject *circleObject) GetShapeObject() ShapeObject {
 // Draw draws the circle with its current transformations (translation, scaling, rotation).
 // @return error: Returns nil if the drawing operation is successful.
 func (circleObject *circleObject) Draw() error {
-	x, y := circleObject.center.GetCoords()
-	circleObject.primitive.DrawCircle(x+circleObject.GetShapeObject().GetTransformableObject().GetTranslationX(), y+circleObject.GetShapeObject().GetTransformableObject().GetTranslationY(), circleObject.radius*circleObject.shapeObject.GetTransformableObject().GetScale(), circleObject.color)
+	circleObject.render(circleObject.color)
+	circleObject.shapeObject.GetDrawableObject().SetBounds(circleObject.Bounds())
 	circleObject.shapeObject.GetDrawableObject().Draw()
 	return nil
 }
 
-// UnDraw erases the circle from the screen by effectively removing it.
+// UnDraw hides the circle by marking it as not drawn. The pixels disappear with the next redraw of the frame.
 // @return error: Returns nil if the erase operation is successful.
 func (circleObject *circleObject) UnDraw() error {
-	x, y := circleObject.center.GetCoords()
-	circleObject.primitive.DrawCircle(x+circleObject.GetShapeObject().GetTransformableObject().GetTranslationX(), y+circleObject.GetShapeObject().GetTransformableObject().GetTranslationY(), circleObject.radius*circleObject.shapeObject.GetTransformableObject().GetScale(), circleObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor())
-	circleObject.shapeObject.GetDrawableObject().Draw()
+	circleObject.shapeObject.GetDrawableObject().UnDraw()
 	return nil
 }
 
+// Bounds returns the axis-aligned box of screen pixels covered by the circle with its current transformations.
+// The circle becomes an ellipse under the transformation, its extents are the radius times the lengths
+// of the rows of the matrix, (a, b) for x and (c, d) for y.
+// @return image.Rectangle: The bounding box of the circle.
+func (circleObject *circleObject) Bounds() image.Rectangle {
+	x, y := circleObject.center.GetCoords()
+	transform := circleObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	a, b, c, d, _, _ := transform.Elements()
+	centerX, centerY := transform.Apply(float64(x), float64(y))
+	extentX := float64(circleObject.radius) * math.Hypot(a, b)
+	extentY := float64(circleObject.radius) * math.Hypot(c, d)
+	return pixelBounds([]float64{centerX - extentX, centerX + extentX}, []float64{centerY - extentY, centerY + extentY})
+}
+
+// render draws the transformed circle in the given color.
+// While the transformation keeps circles round the midpoint algorithm is used,
+// otherwise (non-uniform scale) the outline is approximated by segments mapped through the matrix.
+// @param col color.Color: The color of the outline.
+func (circleObject *circleObject) render(col color.Color) {
+	gameObject := circleObject.shapeObject.GetDrawableObject().GetGameObject()
+	primitive := NewPrimitiveRendererclass(gameObject.GetCanvas(), gameObject.GetBackgroundColor())
+	x, y := circleObject.center.GetCoords()
+	transform := circleObject.shapeObject.GetTransformableObject().GetWorldTransform()
+
+	if transform.IsSimilarity() {
+		centerX, centerY := transform.ApplyInt(x, y)
+		radius := int(math.Round(float64(circleObject.radius) * transform.UniformScale()))
+		primitive.DrawCircle(centerX, centerY, radius, col)
+		return
+	}
+
+	a, b, c, d, _, _ := transform.Elements()
+	longest := float64(circleObject.radius) * max(math.Hypot(a, c), math.Hypot(b, d))
+	steps := max(16, int(math.Ceil(2*math.Pi*longest/4)))
+	prevX, prevY := transform.ApplyInt(x+circleObject.radius, y)
+	for i := 1; i <= steps; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(steps)
+		sin, cos := math.Sincos(angle)
+		nextX, nextY := transform.Apply(float64(x)+float64(circleObject.radius)*cos, float64(y)+float64(circleObject.radius)*sin)
+		curX, curY := int(math.Round(nextX)), int(math.Round(nextY))
+		primitive.segment(prevX, prevY, curX, curY, col)
+		prevX, prevY = curX, curY
+	}
+}
+
 // Translate moves the circle by a given offset on the x and y axes.
-// @param x int: The offset on the x-axis.
-// @param y int: The offset on the y-axis.
+// @param x float64: The offset on the x-axis.
+// @param y float64: The offset on the y-axis.
 // @return error: Returns nil if the translation operation is successful.
-func (circleObject *circleObject) Translate(x, y int) error {
-	circleObject.UnDraw()
+func (circleObject *circleObject) Translate(x, y float64) error {
 	circleObject.GetShapeObject().GetTransformableObject().Translate(x, y)
-	circleObject.Draw()
 	return nil
 }
 
 // Scale changes the scale of the circle by a given factor.
-// @param S int: The scale factor for the circle.
+// @param S float64: The scale factor for the circle.
 // @return error: Returns nil if the scaling operation is successful.
-func (circleObject *circleObject) Scale(S int) error {
-	circleObject.UnDraw()
+func (circleObject *circleObject) Scale(S float64) error {
 	circleObject.GetShapeObject().GetTransformableObject().Scale(S)
-	circleObject.Draw()
 	return nil
 }
 
 // Rotate rotates the circle by a given angle.
-// @param angle int: The angle by which to rotate the circle.
+// @param angle float64: The angle in degrees by which to rotate the circle.
 // @return error: Returns nil if the rotation operation is successful.
-func (circleObject *circleObject) Rotate(angle int) error {
-	circleObject.UnDraw()
+func (circleObject *circleObject) Rotate(angle float64) error {
 	circleObject.GetShapeObject().GetTransformableObject().Rotate(angle)
-	circleObject.Draw()
 	return nil
 }
+
+// SetColor sets the color of the circle. The new color is used by the next Draw.
+// @param color color.Color: The new color of the circle.
+// @return error: Returns an error if the color is nil.
+func (circleObject *circleObject) SetColor(color color.Color) error {
+	if color == nil {
+		return errors.New("circle error: color is nil")
+	}
+	circleObject.color = color
+	return nil
+}
+
+// GetColor returns the color of the circle.
+// @return color.Color: The color of the circle.
+func (circleObject *circleObject) GetColor() color.Color {
+	return circleObject.color
+}
+
+// Contains tests whether a screen point hits the circle with its current transformations.
+// The point is mapped back to the untransformed circle and compared with the radius.
+// @param x, y int: The screen point.
+// @return bool: True if the point is inside the circle or on its outline.
+func (circleObject *circleObject) Contains(x, y int) bool {
+	inverse, err := circleObject.shapeObject.GetTransformableObject().GetWorldTransform().Invert()
+	if err != nil {
+		return false // The circle is scaled to nothing.
+	}
+	centerX, centerY := circleObject.center.GetCoords()
+	localX, localY := inverse.Apply(float64(x), float64(y))
+	return math.Hypot(localX-float64(centerX), localY-float64(centerY)) <= float64(circleObject.radius)+0.5
+}
+
+// Collider returns the shape of the transformed circle for the collision package.
+// @return collision.Shape: A circle, or a polygon approximating the ellipse of a non-uniformly scaled circle.
+func (circleObject *circleObject) Collider() collision.Shape {
+	x, y := circleObject.center.GetCoords()
+	transform := circleObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	centerX, centerY := transform.Apply(float64(x), float64(y))
+	if transform.IsSimilarity() {
+		return collision.NewCircle(centerX, centerY, float64(circleObject.radius)*transform.UniformScale())
+	}
+	const steps = 24
+	points := make([]collision.Vector, 0, steps)
+	for i := 0; i < steps; i++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / steps)
+		pointX, pointY := transform.Apply(float64(x)+float64(circleObject.radius)*cos, float64(y)+float64(circleObject.radius)*sin)
+		points = append(points, collision.NewVector(pointX, pointY))
+	}
+	polygon, err := collision.NewPolygon(points...)
+	if err != nil {
+		return collision.NewCircle(centerX, centerY, 0) // The circle is scaled to nothing.
+	}
+	return polygon
+}
@@ -0,0 +1,77 @@
+package objects
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawOptions describes how a BitmapObject draws its bitmaps besides the geometric transformation.
+// Scale, rotation and the pivot are set on the TransformableObject of the BitmapObject, so bitmaps
+// and shapes share one transformation model, the options add what only applies to pixels.
+type DrawOptions struct {
+	FlipX        bool          // Mirrors the bitmap horizontally within its own box.
+	FlipY        bool          // Mirrors the bitmap vertically within its own box.
+	Tint         color.Color   // The color multiplied with every pixel, nil or white keeps the colors.
+	Transparency float64       // From 0 (opaque) to 1 (invisible), the zero value keeps the bitmap opaque.
+	Blend        ebiten.Blend  // The way the pixels are combined with the screen, the zero value is ebiten.BlendSourceOver.
+	Filter       ebiten.Filter // The filter used when the bitmap is scaled or rotated.
+}
+
+// NewDrawOptions creates the default draw options: no flip, no tint, opaque, source-over blending and
+// nearest filtering, which keeps pixel art sharp.
+// @return DrawOptions: The default options.
+func NewDrawOptions() DrawOptions {
+	return DrawOptions{
+		FlipX:        false,
+		FlipY:        false,
+		Tint:         nil,
+		Transparency: 0,
+		Blend:        ebiten.BlendSourceOver,
+		Filter:       ebiten.FilterNearest,
+	}
+}
+
+// validate checks that the options can be used for drawing.
+// @return error: Returns an error if Transparency is outside of [0, 1] or the filter is unknown.
+func (options DrawOptions) validate() error {
+	if !(options.Transparency >= 0 && options.Transparency <= 1) {
+		return errors.New("bitmap error: transparency must be between 0 and 1")
+	}
+	if options.Filter != ebiten.FilterNearest && options.Filter != ebiten.FilterLinear {
+		return errors.New("bitmap error: unknown filter")
+	}
+	return nil
+}
+
+// flip returns the matrix which mirrors a bitmap of the given size within its own box,
+// it is applied to the pixels before the placement of the bitmap.
+// @param width, height int: The size of the bitmap.
+// @return Transform2D: The mirroring, the identity without flips.
+func (options DrawOptions) flip(width, height int) Transform2D {
+	transform := IdentityTransform2D()
+	if options.FlipX {
+		transform = transform.Scale(-1, 1).Translate(float64(width), 0)
+	}
+	if options.FlipY {
+		transform = transform.Scale(1, -1).Translate(0, float64(height))
+	}
+	return transform
+}
+
+// imageOptions builds the ebiten options which draw a bitmap with these options.
+// @param placement Transform2D: The matrix which maps the pixels of the bitmap to the screen.
+// @param width, height int: The size of the bitmap.
+// @return *ebiten.DrawImageOptions: The options for ebiten.Image.DrawImage.
+func (options DrawOptions) imageOptions(placement Transform2D, width, height int) *ebiten.DrawImageOptions {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = options.flip(width, height).Concat(placement).GeoM()
+	if options.Tint != nil {
+		op.ColorScale.ScaleWithColor(options.Tint)
+	}
+	op.ColorScale.ScaleAlpha(float32(1 - options.Transparency))
+	op.Blend = options.Blend
+	op.Filter = options.Filter
+	return op
+}
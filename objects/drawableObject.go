@@ -1,5 +1,7 @@
 package objects
 
+import "image"
+
 // DrawableObject represents an object that can be drawn and undrawn.
 // Also this object inherit GameObject(the basic class of hierarchy)
 // It also provides a method to retrieve the associated game object.
@@ -15,13 +17,28 @@ type DrawableObject interface {
 	// UnDraw undraws the object, marking it as not drawn.
 	// @return error: Returns nil if the undrawing operation is successful.
 	UnDraw() error
+
+	// GetIsDrawn returns the current state of the drawable object (whether it is drawn or not).
+	// @return bool: Returns true if the object is drawn, false otherwise.
+	GetIsDrawn() bool
+
+	// SetBounds records the box of screen pixels covered by the object when it was drawn last.
+	// @param bounds image.Rectangle: The bounding box of the object.
+	// @return error: Returns nil if the bounds are recorded successfully.
+	SetBounds(bounds image.Rectangle) error
+
+	// GetBounds returns the box of screen pixels covered by the object when it was drawn last.
+	// @return image.Rectangle: The bounding box, empty if the object has not been drawn yet.
+	GetBounds() image.Rectangle
 }
 
 // drawableObject is an internal implementation of the DrawableObject interface.
-// It contains a reference to a game object and a flag indicating whether the object is drawn.
+// It contains a reference to a game object, a flag indicating whether the object is drawn
+// and the bounding box of the last drawing.
 type drawableObject struct {
-	gameObject GameObject // The associated game object.
-	isDrawn    bool       // A flag indicating whether the object is drawn or not.
+	gameObject GameObject      // The associated game object.
+	isDrawn    bool            // A flag indicating whether the object is drawn or not.
+	bounds     image.Rectangle // The box of screen pixels covered by the last drawing.
 }
 
 // NewDrawableObject creates a new instance of a drawable object with the specified game object.
@@ -32,6 +49,7 @@ func NewDrawableObject(gameObject GameObject) DrawableObject {
 	return &drawableObject{
 		gameObject: gameObject,
 		isDrawn:    false,
+		bounds:     image.Rectangle{},
 	}
 }
 
@@ -60,3 +78,17 @@ func (drawableObject *drawableObject) UnDraw() error {
 func (drawableObject *drawableObject) GetIsDrawn() bool {
 	return drawableObject.isDrawn
 }
+
+// SetBounds records the box of screen pixels covered by the object when it was drawn last.
+// @param bounds image.Rectangle: The bounding box of the object.
+// @return error: Returns nil if the bounds are recorded successfully.
+func (drawableObject *drawableObject) SetBounds(bounds image.Rectangle) error {
+	drawableObject.bounds = bounds
+	return nil
+}
+
+// GetBounds returns the box of screen pixels covered by the object when it was drawn last.
+// @return image.Rectangle: The bounding box, empty if the object has not been drawn yet.
+func (drawableObject *drawableObject) GetBounds() image.Rectangle {
+	return drawableObject.bounds
+}
@@ -0,0 +1,60 @@
+package objects
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// osFileSystem is the fs.FS of the operating system used when no other file system is set.
+// Unlike os.DirFS it accepts every path the os package accepts, relative to the working directory
+// or absolute, so existing callers keep working.
+type osFileSystem struct{}
+
+// NewOSFileSystem creates the file system of the operating system, the default of the loaders.
+// Use os.DirFS, embed.FS or zip.Reader instead to load assets independently of the working directory.
+// @return fs.FS: The file system.
+func NewOSFileSystem() fs.FS {
+	return osFileSystem{}
+}
+
+// Open opens a file.
+// @param name string: The path of the file.
+// @return fs.File: The opened file.
+// @return error: Returns the error of os.Open.
+func (osFileSystem osFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// ReadFile reads a whole file, it implements fs.ReadFileFS.
+// @param name string: The path of the file.
+// @return []byte: The content of the file.
+// @return error: Returns the error of os.ReadFile.
+func (osFileSystem osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// ReadDir reads a directory, it implements fs.ReadDirFS.
+// @param name string: The path of the directory.
+// @return []fs.DirEntry: The entries sorted by name.
+// @return error: Returns the error of os.ReadDir.
+func (osFileSystem osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// Stat describes a file, it implements fs.StatFS.
+// @param name string: The path of the file.
+// @return fs.FileInfo: The description of the file.
+// @return error: Returns the error of os.Stat.
+func (osFileSystem osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// assetPath cleans a path and uses slashes as separators, the form of the paths of fs.FS.
+// The os package accepts slashes on every system, so the path also works with osFileSystem.
+// @param name string: The path with the separators of the system or slashes.
+// @return string: The cleaned path.
+func assetPath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
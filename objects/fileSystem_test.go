@@ -0,0 +1,59 @@
+package objects
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadersUseFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	writeImage(t, filepath.Join(dir, "hero", "walk1.png"), 2)
+	writeImage(t, filepath.Join(dir, "hero", "walk2.png"), 2)
+	if err := writePNG(filepath.Join(dir, "atlas", "sheet.png"), image.NewRGBA(image.Rect(0, 0, 8, 4))); err != nil {
+		t.Fatal(err)
+	}
+	atlas := `{"frames": {"a.png": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}, "b.png": {"frame": {"x": 4, "y": 0, "w": 4, "h": 4}}}, "meta": {"image": "sheet.png"}}`
+	if err := os.WriteFile(filepath.Join(dir, "atlas", "sheet.json"), []byte(atlas), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The loaders only see the directory through the file system, not through paths of the operating system.
+	fsys := os.DirFS(dir)
+
+	handler := NewBitmapHandler(0, 0)
+	handler.SetFileSystem(fsys)
+	sprite := NewSpriteObject(NewBitmapObject([]BitmapHandler{handler}, NewDrawableObject(NewGameObject(nil, goldenBackground))), "hero")
+	if err := sprite.LoadBitmaps("hero", 0); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := sprite.GetName(1); name != "walk2" {
+		t.Errorf("second bitmap is %q, want walk2", name)
+	}
+	if err := sprite.LoadAtlas("atlas/sheet.json", 0); err != nil {
+		t.Fatal(err)
+	}
+	if img, ok := handler.Get("b"); !ok || img.Bounds() != image.Rect(4, 0, 8, 4) {
+		t.Errorf("atlas frame b is missing or has the wrong bounds")
+	}
+	if err := sprite.LoadSheet("atlas/sheet.png", SheetGrid{FrameWidth: 2, FrameHeight: 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if index, err := sprite.GetFrameIndex("sheet_7"); err != nil || index != 7 {
+		t.Errorf("sheet frame sheet_7 has index %d: %v", index, err)
+	}
+
+	// With an asset manager its file system is used, also by the loader.
+	assets := NewAssetManagerFS(fsys)
+	other := NewBitmapHandler(0, 0)
+	other.SetAssetManager(assets)
+	if err := other.Load("walk", "hero/walk1.png"); err != nil || assets.Len() != 1 {
+		t.Errorf("loading through the manager: %v", err)
+	}
+	loader, _ := NewAssetLoader(assets, 1)
+	loader.Start("hero/walk2.png")
+	waitLoader(t, loader)
+	if loader.Err() != nil || assets.RefCount("hero/walk2.png") != 0 || assets.Len() != 2 {
+		t.Errorf("preloading from the file system: %v", loader.Err())
+	}
+}
@@ -21,12 +21,21 @@ type GameObject interface {
 	// SetScreen sets the screen (image) for the game object.
 	// @param screen *ebiten.Image: The screen (image) to be associated with the game object.
 	SetScreen(screen *ebiten.Image)
+
+	// GetCanvas returns the canvas the game object draws its primitives on.
+	// @return Canvas: The canvas of the game object.
+	GetCanvas() Canvas
+
+	// SetCanvas sets the canvas the game object draws its primitives on.
+	// @param canvas Canvas: The canvas to be associated with the game object.
+	SetCanvas(canvas Canvas)
 }
 
 // gameObject is an internal implementation of the GameObject interface.
-// It contains a screen (image) and a background color.
+// It contains a screen (image), a canvas and a background color.
 type gameObject struct {
 	screen          *ebiten.Image
+	canvas          Canvas
 	backgroundColor color.Color
 }
 
@@ -35,10 +44,24 @@ type gameObject struct {
 // @param backgroundColor color.Color: The background color of the game object.
 // @return GameObject: A new instance of the game object with the given screen and background color.
 func NewGameObject(screen *ebiten.Image, backgroundColor color.Color) GameObject {
-	return &gameObject{
-		screen:          screen,
+	gameObject := &gameObject{
 		backgroundColor: backgroundColor,
 	}
+	gameObject.SetScreen(screen)
+	return gameObject
+}
+
+// NewCanvasGameObject creates a new instance of a game object which draws on the specified canvas.
+// If the canvas is backed by an ebiten image, that image is used as the screen as well.
+// @param canvas Canvas: The canvas to be associated with the game object.
+// @param backgroundColor color.Color: The background color of the game object.
+// @return GameObject: A new instance of the game object with the given canvas and background color.
+func NewCanvasGameObject(canvas Canvas, backgroundColor color.Color) GameObject {
+	gameObject := &gameObject{
+		backgroundColor: backgroundColor,
+	}
+	gameObject.SetCanvas(canvas)
+	return gameObject
 }
 
 // NewWScreenGameObject creates a new instance of a game object without a screen, only with a background color.
@@ -48,15 +71,35 @@ func NewGameObject(screen *ebiten.Image, backgroundColor color.Color) GameObject
 func NewWScreenGameObject(backgroundColor color.Color) GameObject {
 	return &gameObject{
 		screen:          nil,
+		canvas:          nil,
 		backgroundColor: backgroundColor,
 	}
 }
 
 // SetScreen sets the screen (image) for the game object.
 // This method allows you to change the screen associated with the game object.
+// The canvas of the game object is switched to the new screen as well.
 // @param screen *ebiten.Image: The screen (image) to set for the game object.
 func (gameObject *gameObject) SetScreen(screen *ebiten.Image) {
 	gameObject.screen = screen
+	gameObject.canvas = nil
+	if screen != nil {
+		gameObject.canvas = NewEbitenCanvas(screen)
+	}
+}
+
+// GetCanvas returns the canvas the game object draws its primitives on.
+// @return Canvas: The current canvas of the game object.
+func (gameObject *gameObject) GetCanvas() Canvas {
+	return gameObject.canvas
+}
+
+// SetCanvas sets the canvas the game object draws its primitives on.
+// The screen is updated to the ebiten image behind the canvas, or nil for headless canvases.
+// @param canvas Canvas: The canvas to set for the game object.
+func (gameObject *gameObject) SetCanvas(canvas Canvas) {
+	gameObject.canvas = canvas
+	gameObject.screen = ebitenImageOf(canvas)
 }
 
 // GetScreen returns the current screen (image) associated with the game object.
@@ -1,11 +1,11 @@
 package objects
 
 import (
-	"fmt"
 	"image"
 	"image/color"
+	"io/fs"
 	"math"
-	"os"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -68,8 +68,10 @@ func segmentsIntersect(p1, q1, p2, q2 Point2D) bool {
 }
 
 // Determines if a point is inside a polygon using the ray-casting algorithm.
-// @return bool: True if the point is inside the polygon, false otherwise.
-func isPointInPolygon(p Point2D, polygon []Point2D, screen *ebiten.Image, backgroundColor color.Color) bool {
+// Only edges which cross the ray's line are counted, with their lower end included and the upper one excluded,
+// so a ray passing exactly through a vertex is counted once.
+// @return bool: True if the point is inside the polygon, false otherwise (also for points on an edge).
+func isPointInPolygon(p Point2D, polygon []Point2D, screen Canvas, backgroundColor color.Color) bool {
 	n := len(polygon)
 	if n < 3 {
 		return false
@@ -82,12 +84,14 @@ func isPointInPolygon(p Point2D, polygon []Point2D, screen *ebiten.Image, backgr
 	count := 0
 	for i := 0; i < n; i++ {
 		next := (i + 1) % n
-		// Check if the polygon edge intersects with the ray
-		if segmentsIntersect(polygon[i], polygon[next], p, extreme) {
-			// Check if the point lies on the edge of the polygon
-			if orientation(polygon[i], p, polygon[next]) == 0 && onSegment(polygon[i], p, polygon[next]) {
-				return false // Point lies on the edge
-			}
+		// Check if the point lies on the edge of the polygon
+		if orientation(polygon[i], p, polygon[next]) == 0 && onSegment(polygon[i], p, polygon[next]) {
+			return false // Point lies on the edge
+		}
+		_, y1 := polygon[i].GetCoords()
+		_, y2 := polygon[next].GetCoords()
+		// Check if the polygon edge crosses the ray
+		if (y1 > py) != (y2 > py) && segmentsIntersect(polygon[i], polygon[next], p, extreme) {
 			count++
 		}
 	}
@@ -95,6 +99,30 @@ func isPointInPolygon(p Point2D, polygon []Point2D, screen *ebiten.Image, backgr
 	return count%2 == 1
 }
 
+// isPointOnPolygon determines if a point lies on one of the edges of a closed polygon.
+// @return bool: True if the point is on the outline of the polygon.
+func isPointOnPolygon(p Point2D, polygon []Point2D) bool {
+	for i := range polygon {
+		next := (i + 1) % len(polygon)
+		if orientation(polygon[i], p, polygon[next]) == 0 && onSegment(polygon[i], p, polygon[next]) {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceToSegment returns the distance of a point from the segment (x1, y1)-(x2, y2).
+// @return float64: The distance.
+func distanceToSegment(px, py, x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return math.Hypot(px-x1, py-y1)
+	}
+	t := math.Max(0, math.Min(1, ((px-x1)*dx+(py-y1)*dy)/lengthSquared))
+	return math.Hypot(px-(x1+t*dx), py-(y1+t*dy))
+}
+
 // Rotates a point (x, y) around a center (cx, cy) by a specified angle.
 // @return (int, int): The new coordinates of the rotated point.
 func rotatePoint(x, y, cx, cy int, angle float64) (int, int) {
@@ -116,71 +144,79 @@ func rotatePoint(x, y, cx, cy int, angle float64) (int, int) {
 	return int(math.Round(newX)) + cx, int(math.Round(newY)) + cy
 }
 
-// Returns the absolute value of an integer.
-func abs(x int) int {
-	if x < 0 {
-		return -x
+// Checks if two colors are the same after conversion to premultiplied RGBA.
+// @return bool: True if the colors are equal, false otherwise.
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	return x
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
 }
 
-// Retrieves the dimensions of an image from a file.
-// @return (int, int, error): The width, height, and any error encountered.
-func getImageSize(filePath string) (int, int, error) {
-	// Open the file
-	file, err := os.Open(filePath)
-	if err != nil {
-		return 0, 0, err
+// pixelBounds returns the rectangle of pixels covering all given points, the points are pixel centres
+// like the vertices of the shapes, so the pixel of the largest point is included.
+// @param xs, ys []float64: The coordinates of the points.
+// @return image.Rectangle: The covering rectangle, or an empty one if there are no points.
+func pixelBounds(xs, ys []float64) image.Rectangle {
+	if len(xs) == 0 || len(xs) != len(ys) {
+		return image.Rectangle{}
 	}
-	defer file.Close()
-
-	// Decode the image
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	// Get image dimensions
-	return img.Bounds().Dx(), img.Bounds().Dy(), nil
+	bounds := edgeBounds(xs, ys)
+	return image.Rectangle{Min: bounds.Min, Max: bounds.Max.Add(image.Pt(1, 1))}
 }
 
-// Checks if a slice contains a specified integer.
-// @return bool: True if the slice contains the item, false otherwise.
-func contains(slice []int, item int) bool {
-	for _, v := range slice {
-		if v == item {
-			return true
-		}
+// edgeBounds returns the rectangle of pixels covered by an area with the given corners, the corners lie on
+// the edges of pixels like the corners (0, 0) and (width, height) of a bitmap.
+// @param xs, ys []float64: The coordinates of the corners.
+// @return image.Rectangle: The covering rectangle, or an empty one if there are no corners.
+func edgeBounds(xs, ys []float64) image.Rectangle {
+	if len(xs) == 0 || len(xs) != len(ys) {
+		return image.Rectangle{}
 	}
-	return false
+	minX, maxX, minY, maxY := xs[0], xs[0], ys[0], ys[0]
+	for i := range xs {
+		minX, maxX = math.Min(minX, xs[i]), math.Max(maxX, xs[i])
+		minY, maxY = math.Min(minY, ys[i]), math.Max(maxY, ys[i])
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
 }
 
-// Returns the index of an item in a slice, or -1 if not found.
-// @return int: The index of the item or -1 if not found.
-func indexOf(slice []int, item int) int {
-	for i, v := range slice {
-		if v == item {
-			return i
-		}
+// Returns the absolute value of an integer.
+func abs(x int) int {
+	if x < 0 {
+		return -x
 	}
-	return -1
+	return x
 }
 
-// Writes text to a specified file, overwriting any existing content.
-// @return error: Returns nil if successful, otherwise returns an error.
-func writeToFile(filename, text string) error {
-	// Open the file for writing
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+// loadImage decodes an image file into an ebiten image.
+// @param fsys fs.FS: The file system holding the file.
+// @param filePath string: The path of the image file.
+// @return *ebiten.Image: The loaded image.
+// @return error: Returns an error if the file can not be read or decoded.
+func loadImage(fsys fs.FS, filePath string) (*ebiten.Image, error) {
+	file, err := fsys.Open(assetPath(filePath))
 	if err != nil {
-		return fmt.Errorf("Error opening file: %v", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	// Write the text to the file
-	_, err = file.WriteString(text)
+	img, _, err := image.Decode(file)
 	if err != nil {
-		return fmt.Errorf("Error writing to file: %v", err)
+		return nil, err
 	}
+	return ebiten.NewImageFromImage(img), nil
+}
 
-	return nil
+// tickDuration returns the duration of one tick of the game loop, 1/TPS.
+// With ebiten.SyncWithFPS there is no fixed tick and the ticks of ebiten.DefaultTPS are used.
+// @return time.Duration: The duration of a tick, always positive.
+func tickDuration() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Second / time.Duration(tps)
 }
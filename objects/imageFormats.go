@@ -0,0 +1,161 @@
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/bmp"
+)
+
+// The image packages register their decoders for image.Decode when they are imported,
+// so the loaders read PNG, JPEG, GIF and BMP files.
+
+// imageFormat returns the format of an image file from its extension.
+// @param filePath string: The path of the file.
+// @return string: "png", "jpeg", "gif" or "bmp".
+// @return error: Returns an error if the extension is not one of a supported format.
+func imageFormat(filePath string) (string, error) {
+	switch strings.ToLower(path.Ext(assetPath(filePath))) {
+	case ".png":
+		return "png", nil
+	case ".jpg", ".jpeg":
+		return "jpeg", nil
+	case ".gif":
+		return "gif", nil
+	case ".bmp":
+		return "bmp", nil
+	}
+	return "", fmt.Errorf("bitmap error: unsupported image format of %q", filePath)
+}
+
+// encodeImage writes an image in a format.
+// @param writer io.Writer: The destination.
+// @param img image.Image: The image.
+// @param format string: A format returned by imageFormat.
+// @return error: Returns the error of the encoder.
+func encodeImage(writer io.Writer, img image.Image, format string) error {
+	switch format {
+	case "png":
+		return png.Encode(writer, img)
+	case "jpeg":
+		return jpeg.Encode(writer, img, &jpeg.Options{Quality: 95})
+	case "gif":
+		return gif.Encode(writer, img, nil)
+	case "bmp":
+		return bmp.Encode(writer, img)
+	}
+	return fmt.Errorf("bitmap error: unsupported image format %q", format)
+}
+
+// saveImage writes an image to a file in the format of its extension, with the origin of the image at (0, 0).
+// If the image can not be encoded, the partially written file is removed.
+// @param img image.Image: The image, for example a sub-image.
+// @param filePath string: The file path where the image will be saved.
+// @return error: Returns an error if the format is not supported or the file can not be written.
+func saveImage(img image.Image, filePath string) error {
+	format, err := imageFormat(filePath)
+	if err != nil {
+		return err
+	}
+	if bounds := img.Bounds(); bounds.Min != (image.Point{}) {
+		cropped := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(cropped, cropped.Bounds(), img, bounds.Min, draw.Src)
+		img = cropped
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := encodeImage(file, img, format); err != nil {
+		// Do not leave a broken image behind.
+		file.Close()
+		os.Remove(filePath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	return nil
+}
+
+// readPixels copies the pixels of an ebiten image to the CPU, it works for sub-images as well.
+// Like ebiten.Image.ReadPixels it can only be called while the game runs.
+// @param img *ebiten.Image: The image.
+// @return *image.RGBA: The pixels, with the origin at (0, 0).
+func readPixels(img *ebiten.Image) *image.RGBA {
+	size := img.Bounds().Size()
+	pixels := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	img.ReadPixels(pixels.Pix)
+	return pixels
+}
+
+// gifSheet decodes an animated GIF into a sheet with every frame side by side.
+// The frames are composed like a browser shows them, following the disposal method of each frame.
+// @param reader io.Reader: The GIF file.
+// @param name string: The base of the frame names and the name of the tag.
+// @return *image.RGBA: The sheet.
+// @return []AtlasFrame: The frames on the sheet with their delays, named name_0, name_1 and so on.
+// @return AtlasTag: The tag of all frames with the loop count of the GIF.
+// @return error: Returns an error if the file is not a GIF.
+func gifSheet(reader io.Reader, name string) (*image.RGBA, []AtlasFrame, AtlasTag, error) {
+	animation, err := gif.DecodeAll(reader)
+	if err != nil {
+		return nil, nil, AtlasTag{}, err
+	}
+	if len(animation.Image) == 0 {
+		return nil, nil, AtlasTag{}, errors.New("bitmap error: gif has no frames")
+	}
+	width, height := animation.Config.Width, animation.Config.Height
+	if width == 0 || height == 0 {
+		width, height = animation.Image[0].Bounds().Max.X, animation.Image[0].Bounds().Max.Y
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	sheet := image.NewRGBA(image.Rect(0, 0, width*len(animation.Image), height))
+	frames := make([]AtlasFrame, len(animation.Image))
+	for i, frame := range animation.Image {
+		disposal := byte(0)
+		if i < len(animation.Disposal) {
+			disposal = animation.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(canvas.Bounds())
+			draw.Draw(previous, canvas.Bounds(), canvas, image.Point{}, draw.Src)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		rect := image.Rect(i*width, 0, (i+1)*width, height)
+		draw.Draw(sheet, rect, canvas, image.Point{}, draw.Src)
+		frames[i] = AtlasFrame{Name: fmt.Sprintf("%s_%d", name, i), Rect: rect}
+		if i < len(animation.Delay) {
+			frames[i].Duration = time.Duration(animation.Delay[i]) * 10 * time.Millisecond
+		}
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	// The GIF loop count is the number of repetitions after the first play, -1 to play once.
+	tag := AtlasTag{Name: name, From: 0, To: len(frames) - 1, Direction: AsepriteForward}
+	switch {
+	case animation.LoopCount < 0:
+		tag.Repeat = 1
+	case animation.LoopCount > 0:
+		tag.Repeat = animation.LoopCount + 1
+	}
+	return sheet, frames, tag, nil
+}
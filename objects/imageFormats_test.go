@@ -0,0 +1,136 @@
+package objects
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestImageFormatsRoundTrip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.RGBA{255, 0, 0, 255})
+	for _, file := range []string{"a.png", "b.JPG", "c.jpeg", "d.gif", "e.bmp"} {
+		format, err := imageFormat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := encodeImage(&buffer, img, format); err != nil {
+			t.Fatal(err)
+		}
+		decoded, decodedFormat, err := image.Decode(&buffer)
+		if err != nil || decodedFormat != format || decoded.Bounds() != img.Bounds() {
+			t.Errorf("%s: decoded as %q with bounds %v: %v", file, decodedFormat, decoded.Bounds(), err)
+		}
+	}
+	if _, err := imageFormat("hero.tiff"); err == nil {
+		t.Error("expected an error for an unsupported extension")
+	}
+}
+
+func TestSaveImage(t *testing.T) {
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	sheet := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				sheet.Set(x, y, red)
+			} else {
+				sheet.Set(x, y, blue)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+	cases := []struct {
+		img    image.Image
+		file   string
+		bounds image.Rectangle
+		left   color.RGBA
+	}{
+		{sheet, "sheet.png", image.Rect(0, 0, 4, 2), red},
+		{sheet.SubImage(image.Rect(2, 0, 4, 2)), "right.bmp", image.Rect(0, 0, 2, 2), blue},
+	}
+	for _, tc := range cases {
+		path := filepath.Join(dir, tc.file)
+		if err := saveImage(tc.img, path); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, _, err := image.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		if decoded.Bounds() != tc.bounds || color.RGBAModel.Convert(decoded.At(0, 1)) != tc.left {
+			t.Errorf("%s: bounds %v, left pixel %v, want %v and %v", tc.file, decoded.Bounds(), decoded.At(0, 1), tc.bounds, tc.left)
+		}
+	}
+
+	// An empty image can not be encoded as PNG, no file is left behind.
+	path := filepath.Join(dir, "empty.png")
+	if err := saveImage(sheet.SubImage(image.Rect(0, 0, 0, 0)), path); err == nil {
+		t.Error("expected an error for an empty image")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the broken file was not removed: %v", err)
+	}
+}
+
+func TestLoadGIF(t *testing.T) {
+	red := image.NewPaletted(image.Rect(0, 0, 4, 4), palette.Plan9)
+	for i := range red.Pix {
+		red.Pix[i] = uint8(red.Palette.Index(color.RGBA{255, 0, 0, 255}))
+	}
+	// The second frame only covers a corner, the rest shows the first frame.
+	blue := image.NewPaletted(image.Rect(2, 2, 4, 4), palette.Plan9)
+	for i := range blue.Pix {
+		blue.Pix[i] = uint8(blue.Palette.Index(color.RGBA{0, 0, 255, 255}))
+	}
+	var buffer bytes.Buffer
+	animation := &gif.GIF{Image: []*image.Paletted{red, blue}, Delay: []int{5, 20}, LoopCount: -1}
+	if err := gif.EncodeAll(&buffer, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet, frames, tag, err := gifSheet(bytes.NewReader(buffer.Bytes()), "blink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Bounds() != image.Rect(0, 0, 8, 4) || frames[1].Rect != image.Rect(4, 0, 8, 4) {
+		t.Errorf("sheet %v, second frame %v", sheet.Bounds(), frames[1].Rect)
+	}
+	if got := sheet.RGBAAt(4, 0); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("second frame keeps %v from the first frame, want red", got)
+	}
+	if got := sheet.RGBAAt(7, 3); got != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("second frame shows %v in its corner, want blue", got)
+	}
+	if frames[0].Duration != 50*time.Millisecond || tag.Repeat != 1 || tag.To != 1 {
+		t.Errorf("first delay %v, tag %+v", frames[0].Duration, tag)
+	}
+
+	handler := NewBitmapHandler(0, 0)
+	handler.SetFileSystem(fstest.MapFS{"fx/blink.gif": {Data: buffer.Bytes()}})
+	sprite := NewSpriteObject(NewBitmapObject([]BitmapHandler{handler}, NewDrawableObject(NewGameObject(nil, goldenBackground))), "fx")
+	if err := sprite.LoadGIF("fx/blink.gif", 0); err != nil {
+		t.Fatal(err)
+	}
+	clips := sprite.GetClips()
+	if len(clips) != 1 || clips[0].Name != "blink" || clips[0].Mode != PlayOnce || len(clips[0].Frames) != 2 {
+		t.Errorf("clips = %+v", clips)
+	}
+	if _, ok := handler.Get("blink_1"); !ok {
+		t.Error("frame blink_1 is missing")
+	}
+}
@@ -1,13 +1,14 @@
 package objects
 
 import (
+	"errors"
+	"image"
 	"image/color"
-	"math"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// LineObject represents a line object that can be drawn, transformed (scaled, rotated, translated), and erased from the screen.
+// LineObject represents a line object that can be drawn, transformed (scaled, rotated, translated), and hidden.
+// The line is retained: it records its geometry and transformation, and is rasterized only by Draw,
+// usually called by a Renderer which clears and redraws the frame.
 // It provides methods for manipulating the line and applying transformations to it.
 // Also this object inherit ShapeObject and use segment for drawing line
 type LineObject interface {
@@ -19,38 +20,57 @@ type LineObject interface {
 	// @return error: Returns nil if the drawing operation is successful.
 	Draw() error
 
-	// UnDraw erases the line from the screen.
+	// UnDraw hides the line, so it is skipped when the frame is redrawn. No pixels are changed.
 	// @return error: Returns nil if the erase operation is successful.
 	UnDraw() error
 
 	// Translate moves the line by a given offset on the x and y axes.
-	// @param x int: The offset on the x-axis.
-	// @param y int: The offset on the y-axis.
+	// @param x float64: The offset on the x-axis.
+	// @param y float64: The offset on the y-axis.
 	// @return error: Returns nil if the translation operation is successful.
-	Translate(x, y int) error
+	Translate(x, y float64) error
 
 	// Scale changes the scale of the line by a given factor.
-	// @param S int: The scale factor.
+	// @param S float64: The scale factor.
 	// @return error: Returns nil if the scaling operation is successful.
-	Scale(S int) error
+	Scale(S float64) error
 
 	// Rotate rotates the line by a given angle.
-	// @param angle int: The angle to rotate the line.
+	// @param angle float64: The angle in degrees to rotate the line.
 	// @return error: Returns nil if the rotation operation is successful.
-	Rotate(angle int) error
+	Rotate(angle float64) error
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the line with its current transformations.
+	// @return image.Rectangle: The bounding box of the line.
+	Bounds() image.Rectangle
+
+	// SetColor sets the color of the line.
+	// @param color color.Color: The new color of the line.
+	// @return error: Returns an error if the color is nil.
+	SetColor(color color.Color) error
+
+	// GetColor returns the color of the line.
+	// @return color.Color: The color of the line.
+	GetColor() color.Color
+
+	// Contains tests whether a screen point hits the line with its current transformations.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point is at most one pixel away from the line.
+	Contains(x, y int) bool
 }
 
 // lineObject is the internal implementation of the LineObject interface.
-// It contains the shape object, the start and finish points of the line, the line segment, and its color.
+// It contains the shape object, the start and finish points of the line, and its color.
+// The line is drawn with a line segment on the current canvas of its game object.
 type lineObject struct {
 	shapeObject ShapeObject // The associated shape object for the line.
 	start       Point2D     // The starting point of the line.
 	finish      Point2D     // The finishing point of the line.
-	segment     LineSegment // The line segment that uses for drawing the line.
 	color       color.Color // The color of the line.
 }
 
 // NewLineObject creates a new line object with the specified shape object, start and finish points, and color.
+// The middle of the line becomes the origin of its transformation.
 // @param shapeObject ShapeObject: The associated shape object for the line.
 // @param x1 int: The x-coordinate of the line's start point.
 // @param y1 int: The y-coordinate of the line's start point.
@@ -59,18 +79,18 @@ type lineObject struct {
 // @param color color.Color: The color of the line.
 // @return LineObject: The new line object instance.
 func NewLineObject(shapeObject ShapeObject, x1, y1, x2, y2 int, color color.Color) LineObject {
+	shapeObject.GetTransformableObject().SetOrigin(float64(x1+x2)/2, float64(y1+y2)/2)
 	return &lineObject{
 		shapeObject: shapeObject,
-		start:       NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x1, y1, color),
-		finish:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x2, y2, color),
+		start:       NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetCanvas(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x1, y1, color),
+		finish:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetCanvas(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x2, y2, color),
 		color:       color,
-		segment:     NewLineSegment(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
 	}
 }
 
 // EnhancedNewLineObject creates a new line object with the specified screen, background color, start and finish points, and color.
 // This method also initializes a new game object and shape object.
-// @param screen *ebiten.Image: The screen where the line will be drawn.
+// @param screen Canvas: The canvas where the line will be drawn (an *ebiten.Image can be passed directly).
 // @param backgroundColor color.Color: The background color for the line.
 // @param x1 int: The x-coordinate of the line's start point.
 // @param y1 int: The y-coordinate of the line's start point.
@@ -78,16 +98,10 @@ func NewLineObject(shapeObject ShapeObject, x1, y1, x2, y2 int, color color.Colo
 // @param y2 int: The y-coordinate of the line's finish point.
 // @param color color.Color: The color of the line.
 // @return LineObject: The new line object instance.
-func EnhancedNewLineObject(screen *ebiten.Image, backgroundColor color.Color, x1, y1, x2, y2 int, color color.Color) LineObject {
-	gmob := NewGameObject(screen, backgroundColor)
+func EnhancedNewLineObject(screen Canvas, backgroundColor color.Color, x1, y1, x2, y2 int, color color.Color) LineObject {
+	gmob := NewCanvasGameObject(screen, backgroundColor)
 	shapeObject := NewShapeObject(NewDrawableObject(gmob), NewTransformableObject(gmob))
-	return &lineObject{
-		shapeObject: shapeObject,
-		start:       NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x1, y1, color),
-		finish:      NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x2, y2, color),
-		color:       color,
-		segment:     NewLineSegment(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
-	}
+	return NewLineObject(shapeObject, x1, y1, x2, y2, color)
 }
 
 // GetShapeObject returns the associated shape object for the line.
@@ -97,89 +111,97 @@ func (lineObject *lineObject) GetShapeObject() ShapeObject {
 }
 
 // Draw draws the line with its current transformations (translation, scaling, rotation).
+// Both ends are mapped to the screen through the world transformation of the line.
 // @return error: Returns nil if the drawing operation is successful.
 func (lineObject *lineObject) Draw() error {
-	x1, y1 := lineObject.start.GetCoords()
-	x2, y2 := lineObject.finish.GetCoords()
-
-	// Apply translation
-	translationX := lineObject.GetShapeObject().GetTransformableObject().GetTranslationX()
-	translationY := lineObject.GetShapeObject().GetTransformableObject().GetTranslationY()
-	x1, y1 = x1+translationX, y1+translationY
-	x2, y2 = x2+translationX, y2+translationY
-
-	// Apply scaling to both points
-	scale := lineObject.shapeObject.GetTransformableObject().GetScale()
-	dx := (x2 - x1) * (scale - 1)
-	dy := (y2 - y1) * (scale - 1)
-	x2, y2 = x2+dx, y2+dy
-
-	// Apply rotation around the center of the line
-	radAngle := float64(lineObject.shapeObject.GetTransformableObject().GetAngle()) * math.Pi / 180.0
-	centrX, centrY := (x1+x2)/2, (y1+y2)/2
-	x1, y1 = rotatePoint(x1, y1, centrX, centrY, radAngle)
-	x2, y2 = rotatePoint(x2, y2, centrX, centrY, radAngle)
-	lineObject.segment.Segment(NewPoint2D(lineObject.shapeObject.GetDrawableObject().GetGameObject().GetScreen(), lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x1, y1, lineObject.color), NewPoint2D(lineObject.shapeObject.GetDrawableObject().GetGameObject().GetScreen(), lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x2, y2, lineObject.color), lineObject.color)
+	lineObject.render(lineObject.color)
+	lineObject.shapeObject.GetDrawableObject().SetBounds(lineObject.Bounds())
 	lineObject.shapeObject.GetDrawableObject().Draw()
 	return nil
 }
 
-// UnDraw erases the line from the screen by effectively removing it.
+// UnDraw hides the line by marking it as not drawn. The pixels disappear with the next redraw of the frame.
 // @return error: Returns nil if the erase operation is successful.
 func (lineObject *lineObject) UnDraw() error {
+	lineObject.shapeObject.GetDrawableObject().UnDraw()
+	return nil
+}
+
+// Bounds returns the axis-aligned box of screen pixels covered by the line with its current transformations.
+// @return image.Rectangle: The bounding box of the line.
+func (lineObject *lineObject) Bounds() image.Rectangle {
 	x1, y1 := lineObject.start.GetCoords()
 	x2, y2 := lineObject.finish.GetCoords()
+	transform := lineObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	startX, startY := transform.Apply(float64(x1), float64(y1))
+	finishX, finishY := transform.Apply(float64(x2), float64(y2))
+	return pixelBounds([]float64{startX, finishX}, []float64{startY, finishY})
+}
 
-	// Apply translation
-	translationX := lineObject.GetShapeObject().GetTransformableObject().GetTranslationX()
-	translationY := lineObject.GetShapeObject().GetTransformableObject().GetTranslationY()
-	x1, y1 = x1+translationX, y1+translationY
-	x2, y2 = x2+translationX, y2+translationY
-
-	// Apply scaling to both points
-	scale := lineObject.shapeObject.GetTransformableObject().GetScale()
-	dx := (x2 - x1) * (scale - 1)
-	dy := (y2 - y1) * (scale - 1)
-	x2, y2 = x2+dx, y2+dy
-
-	// Apply rotation around the center of the line
-	radAngle := float64(lineObject.shapeObject.GetTransformableObject().GetAngle()) * math.Pi / 180.0
-	centrX, centrY := (x1+x2)/2, (y1+y2)/2
-	x1, y1 = rotatePoint(x1, y1, centrX, centrY, radAngle)
-	x2, y2 = rotatePoint(x2, y2, centrX, centrY, radAngle)
-
-	lineObject.segment.Segment(NewPoint2D(lineObject.shapeObject.GetDrawableObject().GetGameObject().GetScreen(), lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x1, y1, lineObject.color), NewPoint2D(lineObject.shapeObject.GetDrawableObject().GetGameObject().GetScreen(), lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x2, y2, lineObject.color), lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor())
-	lineObject.shapeObject.GetDrawableObject().Draw()
-	return nil
+// render draws the transformed line in the given color.
+// @param col color.Color: The color of the line.
+func (lineObject *lineObject) render(col color.Color) {
+	x1, y1 := lineObject.start.GetCoords()
+	x2, y2 := lineObject.finish.GetCoords()
+	transform := lineObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	x1, y1 = transform.ApplyInt(x1, y1)
+	x2, y2 = transform.ApplyInt(x2, y2)
+
+	screen := lineObject.shapeObject.GetDrawableObject().GetGameObject().GetCanvas()
+	backgroundColor := lineObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()
+	NewLineSegment(screen, backgroundColor).Segment(NewPoint2D(screen, backgroundColor, x1, y1, col), NewPoint2D(screen, backgroundColor, x2, y2, col), col)
 }
 
 // Translate moves the line by the specified x and y offsets.
-// @param x int: The offset on the x-axis.
-// @param y int: The offset on the y-axis.
+// @param x float64: The offset on the x-axis.
+// @param y float64: The offset on the y-axis.
 // @return error: Returns nil if the translation operation is successful.
-func (lineObject *lineObject) Translate(x, y int) error {
-	lineObject.UnDraw()
+func (lineObject *lineObject) Translate(x, y float64) error {
 	lineObject.GetShapeObject().GetTransformableObject().Translate(x, y)
-	lineObject.Draw()
 	return nil
 }
 
 // Scale changes the scale of the line by the specified factor.
-// @param S int: The scale factor.
+// @param S float64: The scale factor.
 // @return error: Returns nil if the scaling operation is successful.
-func (lineObject *lineObject) Scale(S int) error {
-	lineObject.UnDraw()
+func (lineObject *lineObject) Scale(S float64) error {
 	lineObject.GetShapeObject().GetTransformableObject().Scale(S)
-	lineObject.Draw()
 	return nil
 }
 
 // Rotate rotates the line by the specified angle.
-// @param angle int: The angle to rotate the line.
+// @param angle float64: The angle in degrees to rotate the line.
 // @return error: Returns nil if the rotation operation is successful.
-func (lineObject *lineObject) Rotate(angle int) error {
-	lineObject.UnDraw()
+func (lineObject *lineObject) Rotate(angle float64) error {
 	lineObject.GetShapeObject().GetTransformableObject().Rotate(angle)
-	lineObject.Draw()
 	return nil
 }
+
+// SetColor sets the color of the line. The new color is used by the next Draw.
+// @param color color.Color: The new color of the line.
+// @return error: Returns an error if the color is nil.
+func (lineObject *lineObject) SetColor(color color.Color) error {
+	if color == nil {
+		return errors.New("line error: color is nil")
+	}
+	lineObject.color = color
+	return nil
+}
+
+// GetColor returns the color of the line.
+// @return color.Color: The color of the line.
+func (lineObject *lineObject) GetColor() color.Color {
+	return lineObject.color
+}
+
+// Contains tests whether a screen point hits the line with its current transformations.
+// @param x, y int: The screen point.
+// @return bool: True if the point is at most one pixel away from the line.
+func (lineObject *lineObject) Contains(x, y int) bool {
+	x1, y1 := lineObject.start.GetCoords()
+	x2, y2 := lineObject.finish.GetCoords()
+	transform := lineObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	startX, startY := transform.Apply(float64(x1), float64(y1))
+	finishX, finishY := transform.Apply(float64(x2), float64(y2))
+	return distanceToSegment(float64(x), float64(y), startX, startY, finishX, finishY) <= 1
+}
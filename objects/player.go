@@ -1,12 +1,35 @@
 package objects
 
 import (
+	"Game_Engine/collision"
+	"encoding/json"
+	"errors"
+	"image"
 	"image/color"
+	"math"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// Names of the clips of the player's animation state machine.
+// The setters of PlayerObject fill the walking, attack and idle clips, the diagonal clips are optional
+// and can be added with SetClip; without them diagonal movement plays the horizontal clip.
+const (
+	ClipIdle          = "idle"
+	ClipAttack        = "attack"
+	ClipWalkRight     = "walk_right"
+	ClipWalkLeft      = "walk_left"
+	ClipWalkUp        = "walk_up"
+	ClipWalkDown      = "walk_down"
+	ClipWalkUpRight   = "walk_up_right"
+	ClipWalkUpLeft    = "walk_up_left"
+	ClipWalkDownRight = "walk_down_right"
+	ClipWalkDownLeft  = "walk_down_left"
+)
+
 // PlayerObject defines an interface for managing the player, its movement, and animations.
+// The frames are chosen by an AnimationStateMachine driven by the input vector.
 // This object inherits SpriteObject
 type PlayerObject interface {
 	// GetSpriteObject returns the associated SpriteObject.
@@ -18,56 +41,109 @@ type PlayerObject interface {
 	// @return error: Returns nil if successful, otherwise returns an error.
 	LoadHero(folderPath string) error
 
-	// SetRightMovement sets the frame sequence for moving to the right.
+	// LoadHeroAtlas loads the player's frames from a JSON texture atlas and its image, for example an Aseprite export.
+	// Every tag of the atlas becomes the clip of the state with the same name, so tags named like
+	// ClipWalkLeft ("walk_left") or ClipIdle ("idle") drive the player without further setup.
+	// The attack is always played once, whatever the repeat count of its tag.
+	// @param jsonPath string: Path to the JSON file of the atlas.
+	// @return error: Returns nil if successful, otherwise returns an error.
+	LoadHeroAtlas(jsonPath string) error
+
+	// SetRightMovement sets the frame sequence for moving to the right (the looping clip ClipWalkRight).
 	// @param rmv []int: Frame indices for moving to the right.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetRightMovement(rmv []int) error
 
-	// SetLeftMovement sets the frame sequence for moving to the left.
+	// SetLeftMovement sets the frame sequence for moving to the left (the looping clip ClipWalkLeft).
 	// @param lmv []int: Frame indices for moving to the left.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetLeftMovement(lmv []int) error
 
-	// SetTopMovement sets the frame sequence for moving upward.
+	// SetTopMovement sets the frame sequence for moving upward (the looping clip ClipWalkUp).
 	// @param tmv []int: Frame indices for moving upward.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetTopMovement(tmv []int) error
 
-	// SetDownMovement sets the frame sequence for moving downward.
+	// SetDownMovement sets the frame sequence for moving downward (the looping clip ClipWalkDown).
 	// @param dmv []int: Frame indices for moving downward.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetDownMovement(dmv []int) error
 
-	// SetAttack sets the frame sequence for attacking.
+	// SetAttack sets the frame sequence for attacking (the clip ClipAttack, played once).
 	// @param att []int: Frame indices for attacking.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetAttack(att []int) error
 
-	// SetCalm sets the frame index for the idle state.
+	// SetCalm sets the frame index for the idle state (the clip ClipIdle).
 	// @param cal int: The frame index for the idle state.
 	// @return error: Returns nil if successful, otherwise returns an error.
 	SetCalm(cal int) error
+
 	// Move functioun recives information about buttons pressed and then transformate it in the movement
+	// It updates the player by one tick of the game loop (1/TPS) and draws it, so it is meant to be called once per tick.
 	// @param isRight bool: indicates that right arrow is pressed or not.
 	// @param isLeft bool: indicates that left arrow is pressed or not.
 	// @param isTop bool: indicates that top arrow is pressed or not.
 	// @param isDown bool: indicates that down arrow is pressed or not.
-	// @param isAttac bool: not yet implemented
+	// @param isAttack bool: indicates that attack button is pressed or not.
 	// @param x, y int: represent current position of player
 	// @return error: Returns nil if successful, otherwise returns an error.
 	Move(isRight, isLeft, isTop, isDown, isAttack bool, x, y int) error
+
+	// Update moves the player and advances its animation by the elapsed time, the frame is chosen by
+	// the animation state machine for the input. Nothing is drawn, call Draw for that.
+	// @param input AnimationInput: The movement direction and the attack request.
+	// @param x, y int: represent current position of player
+	// @param dt time.Duration: The time elapsed since the previous update, the delta of the game loop.
+	// @return error: Returns nil if successful, otherwise returns an error.
+	Update(input AnimationInput, x, y int, dt time.Duration) error
+
+	// Draw draws the current frame of the player at its position.
+	// @return error: Returns an error if the hero is not loaded or can not be drawn.
+	Draw() error
+
+	// GetAnimationStateMachine returns the state machine choosing the frames of the player,
+	// used to add clips (diagonals) or transitions.
+	// @return AnimationStateMachine: The state machine.
+	GetAnimationStateMachine() AnimationStateMachine
+
+	// SetAnimationStateMachine replaces the state machine choosing the frames of the player.
+	// @param animation AnimationStateMachine: The new state machine.
+	// @return error: Returns an error if the state machine is nil.
+	SetAnimationStateMachine(animation AnimationStateMachine) error
+
+	// SetObstacles sets the shapes the player can not walk through.
+	// @param obstacles []collision.Shape: The obstacles, for example colliders of walls.
+	// @return error: Returns nil if successful, otherwise returns an error.
+	SetObstacles(obstacles []collision.Shape) error
+
+	// AddObstacle adds a shape the player can not walk through.
+	// @param obstacle collision.Shape: The obstacle.
+	// @return error: Returns an error if the obstacle is nil.
+	AddObstacle(obstacle collision.Shape) error
+
+	// SaveState returns the position and the animation state of the player as JSON for a game-state snapshot.
+	// @return []byte: The saved state.
+	// @return error: Returns an error if the state can not be encoded.
+	SaveState() ([]byte, error)
+
+	// RestoreState sets the position and the animation state saved by SaveState.
+	// @param data []byte: The saved state.
+	// @return error: Returns an error if the data is not a saved state or names an unknown animation state.
+	RestoreState(data []byte) error
+
+	// Collider returns the box of the current frame of the player for the collision package.
+	// @return collision.Shape: The box around the player.
+	// @return error: Returns an error if the hero is not loaded.
+	Collider() (collision.Shape, error)
 }
 
 // playerObject implements the PlayerObject interface.
 // It manages the player's animations, movement, and actions.
 type playerObject struct {
-	spriteObject  SpriteObject // The SpriteObject associated with the player.
-	calm          int          // Frame index for the idle state.
-	rightMovement []int        // Frame sequence for moving to the right.
-	leftMovement  []int        // Frame sequence for moving to the left.
-	topMovement   []int        // Frame sequence for moving upward.
-	downMovement  []int        // Frame sequence for moving downward.
-	attack        []int        // Frame sequence for attacking.
+	spriteObject SpriteObject          // The SpriteObject associated with the player.
+	animation    AnimationStateMachine // The state machine choosing the frames from the input.
+	obstacles    []collision.Shape     // Shapes the player can not walk through.
 }
 
 // NewPlayerObject creates a new player object.
@@ -88,7 +164,33 @@ func NewPlayerObject(screen *ebiten.Image, backgroundColor color.Color, color co
 
 	return &playerObject{
 		spriteObject: spriteObject,
+		animation:    newPlayerAnimation(),
+	}
+}
+
+// newPlayerAnimation creates the default state machine of the player.
+// An attack is played to its end and returns to idle, diagonals use their own clip when it exists and
+// the horizontal one otherwise, and standing still shows the idle frame.
+// @return AnimationStateMachine: The state machine without clips.
+func newPlayerAnimation() AnimationStateMachine {
+	animation := NewAnimationStateMachine()
+	transitions := []AnimationTransition{
+		{From: AnyState, To: ClipAttack, Condition: InputAttack()},
+		{From: ClipAttack, To: ClipIdle, OnFinish: true},
+		{From: AnyState, To: ClipWalkUpRight, Condition: InputDirection(1, -1)},
+		{From: AnyState, To: ClipWalkUpLeft, Condition: InputDirection(-1, -1)},
+		{From: AnyState, To: ClipWalkDownRight, Condition: InputDirection(1, 1)},
+		{From: AnyState, To: ClipWalkDownLeft, Condition: InputDirection(-1, 1)},
+		{From: AnyState, To: ClipWalkRight, Condition: InputMoving(1, 0)},
+		{From: AnyState, To: ClipWalkLeft, Condition: InputMoving(-1, 0)},
+		{From: AnyState, To: ClipWalkUp, Condition: InputMoving(0, -1)},
+		{From: AnyState, To: ClipWalkDown, Condition: InputMoving(0, 1)},
+		{From: AnyState, To: ClipIdle, Condition: InputIdle()},
 	}
+	for _, transition := range transitions {
+		animation.AddTransition(transition)
+	}
+	return animation
 }
 
 // GetSpriteObject returns the associated SpriteObject.
@@ -108,178 +210,275 @@ func (playerObject *playerObject) LoadHero(folderPath string) error {
 	return nil
 }
 
+// LoadHeroAtlas loads the player's frames from a JSON texture atlas and its image.
+// @param jsonPath string: Path to the JSON file of the atlas.
+// @return error: Returns nil if successful, otherwise returns an error.
+func (playerObject *playerObject) LoadHeroAtlas(jsonPath string) error {
+	err := playerObject.spriteObject.LoadAtlas(jsonPath, 0)
+	if err != nil {
+		return err
+	}
+	// The first clip becomes the current state, so the idle clip is set first to start the player idle.
+	for _, idle := range []bool{true, false} {
+		for _, clip := range playerObject.spriteObject.GetClips() {
+			if (clip.Name == ClipIdle) != idle {
+				continue
+			}
+			if clip.Name == ClipAttack {
+				clip.Mode = PlayOnce
+			}
+			err = playerObject.animation.SetClip(clip)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // SetRightMovement sets the frame sequence for moving to the right.
 // @param rmv []int: Frame indices for moving to the right.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetRightMovement(rmv []int) error {
-	playerObject.rightMovement = rmv
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipWalkRight, PlayLoop, rmv...))
 }
 
 // SetLeftMovement sets the frame sequence for moving to the left.
 // @param lmv []int: Frame indices for moving to the left.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetLeftMovement(lmv []int) error {
-	playerObject.leftMovement = lmv
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipWalkLeft, PlayLoop, lmv...))
 }
 
 // SetTopMovement sets the frame sequence for moving upward.
 // @param tmv []int: Frame indices for moving upward.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetTopMovement(tmv []int) error {
-	playerObject.topMovement = tmv
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipWalkUp, PlayLoop, tmv...))
 }
 
 // SetDownMovement sets the frame sequence for moving downward.
 // @param dmv []int: Frame indices for moving downward.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetDownMovement(dmv []int) error {
-	playerObject.downMovement = dmv
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipWalkDown, PlayLoop, dmv...))
 }
 
 // SetAttack sets the frame sequence for attacking.
 // @param att []int: Frame indices for attacking.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetAttack(att []int) error {
-	playerObject.attack = att
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipAttack, PlayOnce, att...))
 }
 
 // SetCalm sets the frame index for the idle state.
 // @param cal int: The frame index for the idle state.
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) SetCalm(cal int) error {
-	playerObject.calm = cal
-	return nil
+	return playerObject.animation.SetClip(NewAnimationClip(ClipIdle, PlayLoop, cal))
 }
 
 // Move functioun recives information about buttons pressed and then transformate it in the movement
+// The buttons are converted to an input vector for Update, so diagonals and attacks are animated too,
+// and the animation advances by one tick of the game loop.
 // @param isRight bool: indicates that right arrow is pressed or not.
 // @param isLeft bool: indicates that left arrow is pressed or not.
 // @param isTop bool: indicates that top arrow is pressed or not.
 // @param isDown bool: indicates that down arrow is pressed or not.
-// @param isAttac bool: not yet implemented
+// @param isAttack bool: indicates that attack button is pressed or not.
 // @param x, y int: represent current position of player
 // @return error: Returns nil if successful, otherwise returns an error.
 func (playerObject *playerObject) Move(isRight, isLeft, isTop, isDown, isAttack bool, x, y int) error {
+	err := playerObject.Update(NewAnimationInput(isRight, isLeft, isTop, isDown, isAttack), x, y, tickDuration())
+	if err != nil {
+		return err
+	}
+	return playerObject.Draw()
+}
 
-	if !isAttack && !isDown && !isLeft && !isRight && !isTop {
-		err := playerObject.spriteObject.SetBitmap(playerObject.calm, 0)
-		playerObject.spriteObject.MoveObject(x, y, 0)
+// Update moves the player and advances its animation by the elapsed time.
+// If the player would overlap an obstacle at the position, it is pushed out of the obstacle.
+// @param input AnimationInput: The movement direction and the attack request.
+// @param x, y int: represent current position of player
+// @param dt time.Duration: The time elapsed since the previous update.
+// @return error: Returns nil if successful, otherwise returns an error.
+func (playerObject *playerObject) Update(input AnimationInput, x, y int, dt time.Duration) error {
+	// Stop at the obstacles instead of walking through them
+	x, y = playerObject.resolveCollisions(x, y)
+	err := playerObject.spriteObject.MoveObject(x, y, 0)
+	if err != nil {
+		return err
+	}
+	frame, err := playerObject.animation.Update(input, dt)
+	if err != nil {
+		return err
+	}
+	if playerObject.spriteObject.GetAnimatedObject() == nil {
+		return errors.New("player error: hero is not loaded")
+	}
+	return playerObject.spriteObject.GetAnimatedObject().Animate(frame)
+}
 
-		if err != nil {
-			return err
-		}
-		return nil
+// Draw draws the current frame of the player at its position.
+// @return error: Returns an error if the hero is not loaded or can not be drawn.
+func (playerObject *playerObject) Draw() error {
+	name, err := playerObject.spriteObject.GetCurrentName()
+	if err != nil {
+		return err
 	}
-	if isRight && !isDown && !isTop && !isLeft && !isAttack {
-		if !contains(playerObject.rightMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) {
-			err := playerObject.spriteObject.SetBitmap(playerObject.rightMovement[0], 0)
-			playerObject.spriteObject.MoveObject(x, y, 0)
+	return playerObject.spriteObject.GetBitmapObject().Draw(name, 0)
+}
 
-			if err != nil {
-				return err
-			}
-		} else {
-			if indexOf(playerObject.rightMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) < len(playerObject.rightMovement)-1 {
-				err := playerObject.spriteObject.SetBitmap(playerObject.rightMovement[indexOf(playerObject.rightMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame())+1], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			} else {
-				err := playerObject.spriteObject.SetBitmap(playerObject.rightMovement[0], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+// GetAnimationStateMachine returns the state machine choosing the frames of the player.
+// @return AnimationStateMachine: The state machine.
+func (playerObject *playerObject) GetAnimationStateMachine() AnimationStateMachine {
+	return playerObject.animation
+}
+
+// SetAnimationStateMachine replaces the state machine choosing the frames of the player.
+// @param animation AnimationStateMachine: The new state machine.
+// @return error: Returns an error if the state machine is nil.
+func (playerObject *playerObject) SetAnimationStateMachine(animation AnimationStateMachine) error {
+	if animation == nil {
+		return errors.New("player error: animation state machine is nil")
 	}
-	if !isRight && isDown && !isTop && !isLeft && !isAttack {
-		if !contains(playerObject.downMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) {
-			err := playerObject.spriteObject.SetBitmap(playerObject.downMovement[0], 0)
-			playerObject.spriteObject.MoveObject(x, y, 0)
-			if err != nil {
-				return err
-			}
-		} else {
-			if indexOf(playerObject.downMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) < len(playerObject.downMovement)-1 {
-				err := playerObject.spriteObject.SetBitmap(playerObject.downMovement[indexOf(playerObject.downMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame())+1], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			} else {
-				err := playerObject.spriteObject.SetBitmap(playerObject.downMovement[0], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+	playerObject.animation = animation
+	return nil
+}
+
+// SetObstacles sets the shapes the player can not walk through.
+// @param obstacles []collision.Shape: The obstacles, for example colliders of walls.
+// @return error: Returns nil if successful, otherwise returns an error.
+func (playerObject *playerObject) SetObstacles(obstacles []collision.Shape) error {
+	playerObject.obstacles = obstacles
+	return nil
+}
+
+// AddObstacle adds a shape the player can not walk through.
+// @param obstacle collision.Shape: The obstacle.
+// @return error: Returns an error if the obstacle is nil.
+func (playerObject *playerObject) AddObstacle(obstacle collision.Shape) error {
+	if obstacle == nil {
+		return errors.New("player error: obstacle is nil")
 	}
+	playerObject.obstacles = append(playerObject.obstacles, obstacle)
+	return nil
+}
 
-	if !isRight && !isDown && isTop && !isLeft && !isAttack {
-		if !contains(playerObject.topMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) {
-			err := playerObject.spriteObject.SetBitmap(playerObject.topMovement[0], 0)
-			playerObject.spriteObject.MoveObject(x, y, 0)
-			if err != nil {
-				return err
-			}
-		} else {
-			if indexOf(playerObject.topMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) < len(playerObject.topMovement)-1 {
-
-				err := playerObject.spriteObject.SetBitmap(playerObject.topMovement[indexOf(playerObject.topMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame())+1], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			} else {
-				err := playerObject.spriteObject.SetBitmap(playerObject.topMovement[0], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+// Collider returns the box of the current frame of the player for the collision package.
+// @return collision.Shape: The box around the player.
+// @return error: Returns an error if the hero is not loaded.
+func (playerObject *playerObject) Collider() (collision.Shape, error) {
+	bounds, err := playerObject.spriteObject.Bounds(0)
+	if err != nil {
+		return nil, err
 	}
+	return boxOf(bounds), nil
+}
 
-	if !isRight && !isDown && !isTop && isLeft && !isAttack {
-		if !contains(playerObject.leftMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) {
-			err := playerObject.spriteObject.SetBitmap(playerObject.leftMovement[0], 0)
-			playerObject.spriteObject.MoveObject(x, y, 0)
-			if err != nil {
-				return err
-			}
-		} else {
-			if indexOf(playerObject.leftMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame()) < len(playerObject.leftMovement)-1 {
-				err := playerObject.spriteObject.SetBitmap(playerObject.leftMovement[indexOf(playerObject.leftMovement, playerObject.spriteObject.GetAnimatedObject().GetCurrentFrame())+1], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			} else {
-				err := playerObject.spriteObject.SetBitmap(playerObject.leftMovement[0], 0)
-				playerObject.spriteObject.MoveObject(x, y, 0)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+// playerState is the saved state of a player.
+type playerState struct {
+	X                 int    `json:"x"`         // The x-coordinate of the player.
+	Y                 int    `json:"y"`         // The y-coordinate of the player.
+	Animation         string `json:"animation"` // The state of the animation state machine.
+	AnimationPlayback        // The position of the playback in the clip of the state.
+}
+
+// SaveState returns the position and the animation state of the player as JSON.
+// @return []byte: The saved state.
+// @return error: Returns an error if the state can not be encoded.
+func (playerObject *playerObject) SaveState() ([]byte, error) {
+	x, y := playerObject.spriteObject.GetBitmapObject().GetBitmapHandler(0).GetCords()
+	return json.Marshal(playerState{
+		X:                 x,
+		Y:                 y,
+		Animation:         playerObject.animation.GetState(),
+		AnimationPlayback: playerObject.animation.GetPlayer().GetPlayback(),
+	})
+}
+
+// RestoreState sets the position and the animation state saved by SaveState.
+// @param data []byte: The saved state.
+// @return error: Returns an error if the data is not a saved state or names an unknown animation state.
+func (playerObject *playerObject) RestoreState(data []byte) error {
+	var state playerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if err := playerObject.spriteObject.MoveObject(state.X, state.Y, 0); err != nil {
+		return err
 	}
-	err := playerObject.spriteObject.SetBitmap(playerObject.calm, 0)
-	playerObject.spriteObject.MoveObject(x, y, 0)
+	if state.Animation == "" {
+		return nil // Saved before any clip was set.
+	}
+	if err := playerObject.animation.SetState(state.Animation); err != nil {
+		return err
+	}
+	if err := playerObject.animation.GetPlayer().SetPlayback(state.AnimationPlayback); err != nil {
+		return err
+	}
+	if playerObject.spriteObject.GetAnimatedObject() == nil {
+		return nil // The frame is shown once the hero is loaded and updated.
+	}
+	frame, err := playerObject.animation.GetFrame()
 	if err != nil {
 		return err
 	}
-	return nil
+	return playerObject.spriteObject.GetAnimatedObject().Animate(frame)
+}
+
+// maxCollisionSteps limits how many times the player is pushed out of obstacles in one move.
+const maxCollisionSteps = 4
+
+// resolveCollisions moves a position of the player out of all obstacles.
+// @param x, y int: The position the player wants to move to.
+// @return (int, int): The closest position where the player does not overlap an obstacle.
+func (playerObject *playerObject) resolveCollisions(x, y int) (int, int) {
+	if len(playerObject.obstacles) == 0 {
+		return x, y
+	}
+	handler := playerObject.spriteObject.GetBitmapObject().GetBitmapHandler(0)
+	oldX, oldY := handler.GetCords()
+	handler.SetCoords(x, y)
+	bounds, err := playerObject.spriteObject.Bounds(0)
+	handler.SetCoords(oldX, oldY)
+	if err != nil {
+		return x, y // The hero is not loaded yet, so it has no size.
+	}
 
+	for step := 0; step < maxCollisionSteps; step++ {
+		collided := false
+		for _, obstacle := range playerObject.obstacles {
+			contact, ok := collision.Collide(obstacle, boxOf(bounds))
+			if !ok {
+				continue
+			}
+			// Push the player along the contact normal by whole pixels
+			offset := image.Pt(roundAway(contact.Normal.X*contact.Depth), roundAway(contact.Normal.Y*contact.Depth))
+			bounds = bounds.Add(offset)
+			x, y = x+offset.X, y+offset.Y
+			collided = true
+		}
+		if !collided {
+			break
+		}
+	}
+	return x, y
+}
+
+// boxOf converts a rectangle of pixels to a collision box.
+// @param bounds image.Rectangle: The rectangle.
+// @return collision.AABB: The box.
+func boxOf(bounds image.Rectangle) collision.AABB {
+	return collision.NewAABB(float64(bounds.Min.X), float64(bounds.Min.Y), float64(bounds.Max.X), float64(bounds.Max.Y))
+}
+
+// roundAway rounds a number away from zero, so a push always clears the overlap.
+// @param value float64: The number.
+// @return int: The rounded number.
+func roundAway(value float64) int {
+	if value < 0 {
+		return -int(math.Ceil(-value - 1e-9))
+	}
+	return int(math.Ceil(value - 1e-9))
 }
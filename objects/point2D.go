@@ -2,8 +2,6 @@ package objects
 
 import (
 	"image/color"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // Point2D represents a 2D point interface with basic operations.
@@ -15,20 +13,20 @@ type Point2D interface {
 
 // point2D is a concrete implementation of the Point2D interface.
 type point2D struct {
-	screen          *ebiten.Image // The screen where the point is drawn.
-	X               int           // X-coordinate of the point.
-	Y               int           // Y-coordinate of the point.
-	col             color.Color   // The color of the point.
-	backgroundColor color.Color   // Background color of the screen.
+	screen          Canvas      // The canvas where the point is drawn.
+	X               int         // X-coordinate of the point.
+	Y               int         // Y-coordinate of the point.
+	col             color.Color // The color of the point.
+	backgroundColor color.Color // Background color of the screen.
 }
 
 // NewPoint2D creates a new point2D instance.
-// @param screen *ebiten.Image: The screen where the point will be drawn.
+// @param screen Canvas: The canvas where the point will be drawn (an *ebiten.Image can be passed directly).
 // @param backgroundCol color.Color: The background color of the screen.
 // @param x, y int: Initial coordinates of the point.
 // @param col color.Color: The color of the point.
 // @return Point2D: A new point2D instance.
-func NewPoint2D(screen *ebiten.Image, backgroundCol color.Color, x, y int, col color.Color) Point2D {
+func NewPoint2D(screen Canvas, backgroundCol color.Color, x, y int, col color.Color) Point2D {
 	return &point2D{
 		screen:          screen,
 		X:               x,
@@ -3,10 +3,10 @@ package objects
 import (
 	"errors"
 	"fmt"
+	"image"
 	"image/color"
 	"math"
-
-	"github.com/hajimehoshi/ebiten/v2"
+	"sort"
 )
 
 // absolute calculates the absolute value of a float64 number.
@@ -19,6 +19,39 @@ func absolute(num float64) float64 {
 	return num
 }
 
+// Connectivity defines which neighbouring pixels are treated as connected by the fill algorithms.
+type Connectivity int
+
+const (
+	Connectivity4 Connectivity = 4 // Only horizontal and vertical neighbours are connected.
+	Connectivity8 Connectivity = 8 // Diagonal neighbours are connected as well.
+)
+
+// FillRule defines how the interior of a self-intersecting or compound polygon is determined.
+type FillRule int
+
+const (
+	FillRuleEvenOdd FillRule = iota // A point is inside if a ray from it crosses an odd number of edges.
+	FillRuleNonZero                 // A point is inside if the winding number of the edges around it is not zero.
+)
+
+// polygonEdge is an entry of the edge table used by FillPolygon.
+type polygonEdge struct {
+	yMin    int     // First scanline crossed by the edge.
+	yMax    int     // Scanline after the last one crossed by the edge.
+	xMin    float64 // X coordinate at yMin.
+	x       float64 // X coordinate at the current scanline.
+	slope   float64 // Change of x per scanline.
+	winding int     // +1 for edges going down, -1 for edges going up.
+}
+
+var (
+	// ErrFillOutOfBounds is returned when a fill starts outside of the canvas.
+	ErrFillOutOfBounds = errors.New("fill error: start point is out of bounds")
+	// ErrFillLimitExceeded is returned when a fill would change more pixels than allowed by SetFillLimit.
+	ErrFillLimitExceeded = errors.New("fill error: pixel limit exceeded")
+)
+
 // PrimitiveRendererClass defines the interface for rendering various shapes and primitives.
 type PrimitiveRendererСlass interface {
 	// Draws a single pixel on the screen.
@@ -58,23 +91,53 @@ type PrimitiveRendererСlass interface {
 	// @param col color.Color: The fill color.
 	FillSquare(int, int, int, color.Color)
 
-	// Draws a polygon defined by a set of points.
-	// @param points []Point2D: List of polygon vertices.
-	// @param lineColor color.Color: The color of the polygon edges.
+	// Draws a filled polygon defined by a set of points.
+	// @param points []Point2D: List of polygon vertices, the last point must repeat the first one.
+	// @param lineColor color.Color: The color of the polygon edges and interior.
 	// @return error: Returns an error if the polygon is invalid.
 	DrawPolygon([]Point2D, color.Color) error
 
+	// Draws a filled polygon made of several closed contours, for example an outline with holes.
+	// @param contours [][]Point2D: List of contours, the last point of each contour must repeat the first one.
+	// @param lineColor color.Color: The color of the polygon edges and interior.
+	// @return error: Returns an error if any contour is invalid.
+	DrawCompoundPolygon([][]Point2D, color.Color) error
+
+	// Fills the interior of a polygon made of one or more contours without reading the canvas.
+	// @param contours [][]Point2D: List of contours.
+	// @param fillColor color.Color: The fill color.
+	// @param rule FillRule: FillRuleEvenOdd or FillRuleNonZero.
+	// @return error: Returns an error if a contour is invalid.
+	FillPolygon([][]Point2D, color.Color, FillRule) error
+
+	// Sets the rule deciding which areas of DrawPolygon and DrawCompoundPolygon are filled.
+	// @param rule FillRule: FillRuleEvenOdd or FillRuleNonZero.
+	// @return error: Returns an error if the rule is not supported.
+	SetFillRule(FillRule) error
+
 	// Fills an area using the flood-fill algorithm.
 	// @param x, y int: Starting coordinates for the fill.
 	// @param fillColor color.Color: The fill color.
 	// @param boundaryColor color.Color: The color marking the boundaries.
-	FloodFill(int, int, color.Color, color.Color)
+	// @return error: Returns an error if the start point is off the canvas or the pixel limit is exceeded.
+	FloodFill(int, int, color.Color, color.Color) error
 
 	// Fills an area up to the boundary color using the border-fill algorithm.
 	// @param x, y int: Starting coordinates for the fill.
 	// @param fillColor color.Color: The fill color.
 	// @param borderColor color.Color: The boundary color.
-	BorderFill(int, int, color.Color, color.Color)
+	// @return error: Returns an error if the start point is off the canvas or the pixel limit is exceeded.
+	BorderFill(int, int, color.Color, color.Color) error
+
+	// Sets which neighbours FloodFill and BorderFill treat as connected.
+	// @param connectivity Connectivity: Connectivity4 or Connectivity8.
+	// @return error: Returns an error if the connectivity is not supported.
+	SetFillConnectivity(Connectivity) error
+
+	// Sets the maximum number of pixels a single fill may change.
+	// @param limit int: The pixel budget; 0 means the whole canvas.
+	// @return error: Returns an error if the limit is negative.
+	SetFillLimit(int) error
 
 	// Draws an ellipse using a midpoint algorithm.
 	// @param center Point2D: Center of the ellipse.
@@ -85,7 +148,7 @@ type PrimitiveRendererСlass interface {
 
 // primitiveRendererСlass is a concrete implementation of the PrimitiveRendererСlass interface.
 type primitiveRendererСlass struct {
-	screen *ebiten.Image
+	screen Canvas
 	startX int
 	startY int
 
@@ -93,13 +156,16 @@ type primitiveRendererСlass struct {
 	col             color.Color
 	backgroundColor color.Color
 	lines           []LineSegment
+	connectivity    Connectivity // Connectivity used by the fill algorithms.
+	fillRule        FillRule     // Fill rule used by the polygon drawing.
+	fillLimit       int          // Maximum number of pixels a single fill may change, 0 for no limit.
 }
 
 // NewPrimitiveRendererClass creates a new instance of the PrimitiveRendererClass.
-// @param screen *ebiten.Image: The screen to draw on.
+// @param screen Canvas: The canvas to draw on (an *ebiten.Image can be passed directly).
 // @param backgroundColor color.Color: The background color of the screen.
 // @return PrimitiveRendererClass: The created instance.
-func NewPrimitiveRendererclass(screen *ebiten.Image, backgroundColor color.Color) PrimitiveRendererСlass {
+func NewPrimitiveRendererclass(screen Canvas, backgroundColor color.Color) PrimitiveRendererСlass {
 	return &primitiveRendererСlass{
 		screen:          screen,
 		startX:          0,
@@ -108,6 +174,9 @@ func NewPrimitiveRendererclass(screen *ebiten.Image, backgroundColor color.Color
 		col:             nil, // Нулевое значение для интерфейса color.Color
 		backgroundColor: backgroundColor,
 		lines:           make([]LineSegment, 0),
+		connectivity:    Connectivity4,
+		fillRule:        FillRuleEvenOdd,
+		fillLimit:       0,
 	}
 }
 
@@ -313,66 +382,168 @@ func (primitive *primitiveRendererСlass) DrawEllipse(center Point2D, a int, b i
 }
 
 // Draws a polygon defined by a set of points.
-// @param points []Point2D: List of polygon vertices.
-// @param lineColor color.Color: The color of the polygon edges.
+// The polygon is filled with the scanline algorithm using the current fill rule and then outlined.
+// @param points []Point2D: List of polygon vertices, the last point must repeat the first one.
+// @param lineColor color.Color: The color of the polygon edges and interior.
 // @return error: Returns an error if the polygon is invalid.
 func (pr *primitiveRendererСlass) DrawPolygon(points []Point2D, lineColor color.Color) error {
-	if len(points) < 2 {
-		return errors.New("Polygon can't consist of < 3 points")
+	return pr.DrawCompoundPolygon([][]Point2D{points}, lineColor)
+}
+
+// Draws a polygon made of several closed contours, for example an outline with holes.
+// Which areas are filled is decided by the current fill rule.
+// @param contours [][]Point2D: List of contours, the last point of each contour must repeat the first one.
+// @param lineColor color.Color: The color of the polygon edges and interior.
+// @return error: Returns an error if any contour is invalid.
+func (pr *primitiveRendererСlass) DrawCompoundPolygon(contours [][]Point2D, lineColor color.Color) error {
+	for _, points := range contours {
+		if len(points) < 4 {
+			return errors.New("Polygon can't consist of < 3 points")
+		}
+		st_x, st_y := points[0].GetCoords()
+		fn_x, fn_y := points[len(points)-1].GetCoords()
+		if st_x != fn_x || st_y != fn_y {
+			return errors.New("First and last points should be same")
+		}
 	}
-	st_x, st_y := points[0].GetCoords()
-	fn_x, fn_y := points[len(points)-1].GetCoords()
-	if st_x != fn_x && st_y != fn_y {
-		return errors.New("First and last points should be same")
+
+	err := pr.FillPolygon(contours, lineColor, pr.fillRule)
+	if err != nil {
+		return err
 	}
 
-	for i := 0; i < len(points)-1; i++ {
-		startPoint := points[i]
-		endPoint := points[i+1]
-		line := NewLineSegment(pr.screen, pr.backgroundColor) // Use transparent as background
-		line.Segment(startPoint, endPoint, lineColor)
-		pr.lines = append(pr.lines, line)
+	for _, points := range contours {
+		for i := 0; i < len(points)-1; i++ {
+			line := NewLineSegment(pr.screen, pr.backgroundColor)
+			line.Segment(points[i], points[i+1], lineColor)
+			pr.lines = append(pr.lines, line)
+		}
 	}
 	pr.col = lineColor
-	minX := 100000
-	minY := 100000
-	maxX := 0
-	maxY := 0
-	for _, p := range points {
-
-		x, y := p.GetCoords()
-		if x < minX {
-			minX = x
-		}
-		if x > maxX {
-			maxX = x
+
+	return nil
+}
+
+// Fills the interior of a polygon made of one or more contours using an edge-table scanline algorithm.
+// Only the vertex list is used, nothing is read back from the canvas. Contours are closed implicitly.
+// Pixel centers are sampled at integer coordinates, with each edge covering [ymin, ymax).
+// @param contours [][]Point2D: List of contours.
+// @param fillColor color.Color: The fill color.
+// @param rule FillRule: FillRuleEvenOdd or FillRuleNonZero.
+// @return error: Returns an error if a contour has less than 3 vertices or the rule is not supported.
+func (pr *primitiveRendererСlass) FillPolygon(contours [][]Point2D, fillColor color.Color, rule FillRule) error {
+	if rule != FillRuleEvenOdd && rule != FillRuleNonZero {
+		return fmt.Errorf("fill error: unsupported fill rule %d", rule)
+	}
+
+	// Build the edge table, skipping horizontal edges.
+	var edges []polygonEdge
+	for _, points := range contours {
+		n := len(points)
+		if n > 1 {
+			st_x, st_y := points[0].GetCoords()
+			fn_x, fn_y := points[n-1].GetCoords()
+			if st_x == fn_x && st_y == fn_y {
+				n-- // The closing point duplicates the first one.
+			}
 		}
-		if y > maxY {
-			maxY = y
+		if n < 3 {
+			return errors.New("Polygon can't consist of < 3 points")
 		}
-
-		if y < minY {
-			minY = y
+		for i := 0; i < n; i++ {
+			x0, y0 := points[i].GetCoords()
+			x1, y1 := points[(i+1)%n].GetCoords()
+			if y0 == y1 {
+				continue
+			}
+			winding := 1
+			if y0 > y1 {
+				x0, y0, x1, y1 = x1, y1, x0, y0
+				winding = -1
+			}
+			edges = append(edges, polygonEdge{
+				yMin:    y0,
+				yMax:    y1,
+				xMin:    float64(x0),
+				slope:   float64(x1-x0) / float64(y1-y0),
+				winding: winding,
+			})
 		}
 	}
-	medX := (minX + maxX) / 2
-	medY := (minY + maxY) / 2
-	for i := minX; i < maxX; i++ {
-
-		if isPointInPolygon(NewPoint2D(pr.screen, pr.backgroundColor, i, medY, pr.col), points, pr.screen, pr.backgroundColor) {
-			pr.FloodFill(i+1, medY+1, pr.col, pr.backgroundColor)
+	if len(edges) == 0 {
+		return nil
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].yMin < edges[j].yMin })
 
-			return nil
-		}
+	bounds := pr.screen.Bounds()
+	startY := max(edges[0].yMin, bounds.Min.Y)
+	endY := edges[0].yMax
+	for _, edge := range edges {
+		endY = max(endY, edge.yMax)
 	}
-	for i := minY; i < maxY; i++ {
-		if isPointInPolygon(NewPoint2D(pr.screen, pr.backgroundColor, medX, i, pr.col), points, pr.screen, pr.backgroundColor) {
-			pr.FloodFill(medX+1, i+1, pr.col, pr.backgroundColor)
-			return nil
+	endY = min(endY, bounds.Max.Y)
+
+	// Walk the scanlines, keeping the active edge table sorted by intersection.
+	var active []*polygonEdge
+	next := 0
+	for y := startY; y < endY; y++ {
+		for next < len(edges) && edges[next].yMin <= y {
+			edge := &edges[next]
+			if edge.yMax > y {
+				edge.x = edge.xMin + float64(y-edge.yMin)*edge.slope
+				active = append(active, edge)
+			}
+			next++
+		}
+		kept := active[:0]
+		for _, edge := range active {
+			if edge.yMax > y {
+				kept = append(kept, edge)
+			}
+		}
+		active = kept
+		if len(active) == 0 {
+			if next == len(edges) {
+				break
+			}
+			continue
+		}
+		sort.Slice(active, func(i, j int) bool { return active[i].x < active[j].x })
+
+		winding := 0
+		for i := 0; i < len(active)-1; i++ {
+			winding += active[i].winding
+			inside := winding != 0
+			if rule == FillRuleEvenOdd {
+				inside = (i+1)%2 == 1
+			}
+			if !inside {
+				continue
+			}
+			left := max(int(math.Ceil(active[i].x)), bounds.Min.X)
+			right := min(int(math.Ceil(active[i+1].x)), bounds.Max.X)
+			for x := left; x < right; x++ {
+				pr.plotPixel(x, y, fillColor)
+			}
+		}
+
+		for _, edge := range active {
+			edge.x += edge.slope
 		}
 	}
 
-	return errors.New("Point was not found")
+	return nil
+}
+
+// Sets the rule deciding which areas of DrawPolygon and DrawCompoundPolygon are filled.
+// @param rule FillRule: FillRuleEvenOdd or FillRuleNonZero.
+// @return error: Returns an error if the rule is not supported.
+func (pr *primitiveRendererСlass) SetFillRule(rule FillRule) error {
+	if rule != FillRuleEvenOdd && rule != FillRuleNonZero {
+		return fmt.Errorf("fill error: unsupported fill rule %d", rule)
+	}
+	pr.fillRule = rule
+	return nil
 }
 
 // Fills a square area with the specified color.
@@ -388,50 +559,137 @@ func (primitive *primitiveRendererСlass) FillSquare(x int, y int, s int, col co
 }
 
 // Fills an area up to the boundary color using the border-fill algorithm.
+// Every pixel which is neither the border color nor the fill color is filled.
 // @param x, y int: Starting coordinates for the fill.
 // @param fillColor color.Color: The fill color.
 // @param borderColor color.Color: The boundary color.
-func (primitive *primitiveRendererСlass) BorderFill(x int, y int, fillColor color.Color, borderColor color.Color) {
-	if primitive.screen.At(x, y) == borderColor || primitive.screen.At(x, y) == fillColor {
-		return
-	}
-	primitive.plotPixel(x, y, fillColor)
-	primitive.BorderFill(x+1, y, fillColor, borderColor)
-	primitive.BorderFill(x-1, y, fillColor, borderColor)
-	primitive.BorderFill(x, y+1, fillColor, borderColor)
-	primitive.BorderFill(x, y-1, fillColor, borderColor)
+// @return error: Returns an error if the start point is off the canvas or the pixel limit is exceeded.
+func (primitive *primitiveRendererСlass) BorderFill(x int, y int, fillColor color.Color, borderColor color.Color) error {
+	return primitive.scanlineFill(x, y, fillColor, func(current color.Color) bool {
+		return !sameColor(current, borderColor) && !sameColor(current, fillColor)
+	})
 }
 
 // Fills an area using the flood-fill algorithm.
+// Every pixel connected to the start point and having the same color as the start point is filled.
 // @param x, y int: Starting coordinates for the fill.
 // @param fillColor color.Color: The fill color.
 // @param boundaryColor color.Color: The color marking the boundaries.
-func (primitive *primitiveRendererСlass) FloodFill(x, y int, fillColor color.Color, boundaryColor color.Color) {
-	width, height := primitive.screen.Size()
+// @return error: Returns an error if the start point is off the canvas or the pixel limit is exceeded.
+func (primitive *primitiveRendererСlass) FloodFill(x, y int, fillColor color.Color, boundaryColor color.Color) error {
+	if !(image.Point{x, y}).In(primitive.screen.Bounds()) {
+		return ErrFillOutOfBounds
+	}
 	originalColor := primitive.screen.At(x, y)
 
-	if originalColor == fillColor || originalColor == boundaryColor {
-		return
+	if sameColor(originalColor, fillColor) || sameColor(originalColor, boundaryColor) {
+		return nil
+	}
+
+	return primitive.scanlineFill(x, y, fillColor, func(current color.Color) bool {
+		return sameColor(current, originalColor)
+	})
+}
+
+// SetFillConnectivity sets which neighbours FloodFill and BorderFill treat as connected.
+// @param connectivity Connectivity: Connectivity4 or Connectivity8.
+// @return error: Returns an error if the connectivity is not supported.
+func (primitive *primitiveRendererСlass) SetFillConnectivity(connectivity Connectivity) error {
+	if connectivity != Connectivity4 && connectivity != Connectivity8 {
+		return fmt.Errorf("fill error: unsupported connectivity %d", connectivity)
 	}
+	primitive.connectivity = connectivity
+	return nil
+}
 
-	var floodFillRecursive func(x, y int)
-	floodFillRecursive = func(x, y int) {
-		if x < 0 || x >= width || y < 0 || y >= height {
-			return
+// SetFillLimit sets the maximum number of pixels a single fill may change.
+// @param limit int: The pixel budget; 0 means the whole canvas.
+// @return error: Returns an error if the limit is negative.
+func (primitive *primitiveRendererСlass) SetFillLimit(limit int) error {
+	if limit < 0 {
+		return errors.New("fill error: limit can't be negative")
+	}
+	primitive.fillLimit = limit
+	return nil
+}
+
+// scanlineFill fills the region around (x, y) using an explicit stack of seeds.
+// Each seed is expanded into a horizontal span, and the rows above and below the span
+// are scanned for new seeds, so memory use is bounded by the number of spans instead of the call stack.
+// @param x, y int: Starting coordinates for the fill.
+// @param fillColor color.Color: The fill color.
+// @param fillable func(color.Color) bool: Reports whether a pixel of the given color belongs to the region.
+// @return error: Returns an error if the start point is off the canvas or the pixel limit is exceeded.
+func (primitive *primitiveRendererСlass) scanlineFill(x, y int, fillColor color.Color, fillable func(color.Color) bool) error {
+	bounds := primitive.screen.Bounds()
+	if !(image.Point{x, y}).In(bounds) {
+		return ErrFillOutOfBounds
+	}
+
+	width := bounds.Dx()
+	visited := make([]bool, width*bounds.Dy())
+	inside := func(x, y int) bool {
+		if !(image.Point{x, y}).In(bounds) {
+			return false
+		}
+		if visited[(y-bounds.Min.Y)*width+(x-bounds.Min.X)] {
+			return false
 		}
+		return fillable(primitive.screen.At(x, y))
+	}
 
-		currentColor := primitive.screen.At(x, y)
-		if currentColor != originalColor {
-			return // Not the original color, stop recursion
+	limit := primitive.fillLimit
+	if limit == 0 {
+		limit = len(visited)
+	}
+	// Diagonal neighbours are reached by widening the scanned range of the next row by one pixel.
+	diagonal := 0
+	if primitive.connectivity == Connectivity8 {
+		diagonal = 1
+	}
+
+	filled := 0
+	stack := []image.Point{{x, y}}
+	for len(stack) > 0 {
+		seed := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !inside(seed.X, seed.Y) {
+			continue
 		}
 
-		primitive.screen.Set(x, y, fillColor)
+		// Expand the seed into the widest span on its row.
+		left, right := seed.X, seed.X
+		for inside(left-1, seed.Y) {
+			left--
+		}
+		for inside(right+1, seed.Y) {
+			right++
+		}
 
-		floodFillRecursive(x+1, y) // Right
-		floodFillRecursive(x-1, y) // Left
-		floodFillRecursive(x, y+1) // Down
-		floodFillRecursive(x, y-1) // Up
+		filled += right - left + 1
+		if filled > limit {
+			return ErrFillLimitExceeded
+		}
+		for i := left; i <= right; i++ {
+			visited[(seed.Y-bounds.Min.Y)*width+(i-bounds.Min.X)] = true
+			primitive.plotPixel(i, seed.Y, fillColor)
+		}
+
+		// Push one seed per run of fillable pixels on the neighbouring rows.
+		for _, row := range [2]int{seed.Y - 1, seed.Y + 1} {
+			inRun := false
+			for i := left - diagonal; i <= right+diagonal; i++ {
+				if inside(i, row) {
+					if !inRun {
+						stack = append(stack, image.Point{i, row})
+						inRun = true
+					}
+				} else {
+					inRun = false
+				}
+			}
+		}
 	}
 
-	floodFillRecursive(x, y)
+	return nil
 }
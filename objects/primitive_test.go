@@ -0,0 +1,383 @@
+package objects
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateGolden regenerates the golden images instead of comparing against them:
+//
+//	go test ./objects -run Golden -update
+var updateGolden = flag.Bool("update", false, "regenerate golden images in testdata/golden")
+
+const (
+	goldenDir    = "testdata/golden" // Directory with the committed golden images.
+	failedDir    = "testdata/failed" // Directory where actual and diff images are written on failure.
+	goldenWidth  = 64                // Width of the offscreen canvas.
+	goldenHeight = 64                // Height of the offscreen canvas.
+)
+
+var (
+	goldenBackground = color.RGBA{0, 0, 0, 255}       // Background of every golden canvas.
+	goldenLine       = color.RGBA{150, 100, 200, 255} // Color used for outlines.
+	goldenFill       = color.RGBA{50, 100, 200, 255}  // Color used for fills.
+)
+
+// goldenCase describes a single golden-image test: a name and the drawing done on a fresh canvas.
+type goldenCase struct {
+	name string
+	draw func(t *testing.T, canvas Canvas)
+}
+
+// point is a short helper for building Point2D values on a canvas.
+func point(canvas Canvas, x, y int) Point2D {
+	return NewPoint2D(canvas, goldenBackground, x, y, goldenLine)
+}
+
+// renderer is a short helper for building a renderer on a canvas.
+func renderer(canvas Canvas) PrimitiveRendererСlass {
+	return NewPrimitiveRendererclass(canvas, goldenBackground)
+}
+
+var goldenCases = []goldenCase{
+	{"segment_horizontal", func(t *testing.T, c Canvas) {
+		renderer(c).(*primitiveRendererСlass).segment(4, 32, 59, 32, goldenLine)
+	}},
+	{"segment_steep", func(t *testing.T, c Canvas) {
+		renderer(c).(*primitiveRendererСlass).segment(10, 60, 20, 3, goldenLine)
+	}},
+	{"segment_zero_length", func(t *testing.T, c Canvas) {
+		renderer(c).(*primitiveRendererСlass).segment(32, 32, 32, 32, goldenLine)
+	}},
+	{"segment_off_screen", func(t *testing.T, c Canvas) {
+		renderer(c).(*primitiveRendererСlass).segment(-20, -10, 80, 70, goldenLine)
+	}},
+	{"line_segment_diagonal", func(t *testing.T, c Canvas) {
+		NewLineSegment(c, goldenBackground).Segment(point(c, 60, 4), point(c, 4, 40), goldenLine)
+	}},
+	{"line_segment_zero_length", func(t *testing.T, c Canvas) {
+		NewLineSegment(c, goldenBackground).Segment(point(c, 10, 10), point(c, 10, 10), goldenLine)
+	}},
+	{"square", func(t *testing.T, c Canvas) {
+		if err := renderer(c).DrawSquare(10, 10, 40, 0, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"square_rotated", func(t *testing.T, c Canvas) {
+		if err := renderer(c).DrawSquare(16, 16, 30, 30, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polyline", func(t *testing.T, c Canvas) {
+		renderer(c).DrawPolyline([]Point2D{point(c, 4, 60), point(c, 20, 4), point(c, 40, 50), point(c, 60, 10)}, goldenLine)
+	}},
+	{"circle", func(t *testing.T, c Canvas) {
+		renderer(c).DrawCircle(32, 32, 20, goldenLine)
+	}},
+	{"circle_radius_zero", func(t *testing.T, c Canvas) {
+		renderer(c).DrawCircle(32, 32, 0, goldenLine)
+	}},
+	{"circle_off_screen", func(t *testing.T, c Canvas) {
+		renderer(c).DrawCircle(0, 60, 25, goldenLine)
+		renderer(c).DrawCircle(200, 200, 10, goldenLine)
+	}},
+	{"ellipse", func(t *testing.T, c Canvas) {
+		renderer(c).DrawEllipse(point(c, 32, 32), 28, 12, goldenLine)
+	}},
+	{"ellipse_degenerate_a", func(t *testing.T, c Canvas) {
+		renderer(c).DrawEllipse(point(c, 32, 32), 0, 20, goldenLine)
+	}},
+	{"ellipse_degenerate_b", func(t *testing.T, c Canvas) {
+		renderer(c).DrawEllipse(point(c, 32, 32), 20, 0, goldenLine)
+	}},
+	{"ellipse_off_screen", func(t *testing.T, c Canvas) {
+		renderer(c).DrawEllipse(point(c, 60, 4), 30, 15, goldenLine)
+	}},
+	{"polygon_triangle", func(t *testing.T, c Canvas) {
+		err := renderer(c).DrawPolygon([]Point2D{point(c, 8, 56), point(c, 32, 8), point(c, 56, 56), point(c, 8, 56)}, goldenLine)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polygon_concave", func(t *testing.T, c Canvas) {
+		err := renderer(c).DrawPolygon([]Point2D{point(c, 8, 8), point(c, 56, 8), point(c, 56, 56), point(c, 32, 28), point(c, 8, 56), point(c, 8, 8)}, goldenLine)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polygon_star_even_odd", func(t *testing.T, c Canvas) {
+		if err := renderer(c).DrawPolygon(starPoints(c), goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polygon_star_non_zero", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.SetFillRule(FillRuleNonZero)
+		if err := r.DrawPolygon(starPoints(c), goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polygon_hole", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.SetFillRule(FillRuleNonZero)
+		outer := []Point2D{point(c, 4, 4), point(c, 60, 4), point(c, 60, 60), point(c, 4, 60), point(c, 4, 4)}
+		inner := []Point2D{point(c, 20, 20), point(c, 20, 44), point(c, 44, 44), point(c, 44, 20), point(c, 20, 20)}
+		if err := r.DrawCompoundPolygon([][]Point2D{outer, inner}, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"polygon_off_screen", func(t *testing.T, c Canvas) {
+		err := renderer(c).FillPolygon([][]Point2D{{point(c, -40, 10), point(c, 100, -30), point(c, 50, 90)}}, goldenFill, FillRuleEvenOdd)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"fill_square", func(t *testing.T, c Canvas) {
+		renderer(c).FillSquare(10, 20, 30, goldenFill)
+	}},
+	{"fill_square_off_screen", func(t *testing.T, c Canvas) {
+		renderer(c).FillSquare(50, -10, 30, goldenFill)
+	}},
+	{"flood_fill_square", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.DrawSquare(10, 10, 40, 0, goldenLine)
+		r.FloodFill(30, 30, goldenFill, goldenLine)
+	}},
+	{"border_fill_square", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.DrawSquare(10, 10, 40, 0, goldenLine)
+		r.BorderFill(30, 30, goldenFill, goldenLine)
+	}},
+	{"border_fill_circle_4", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.DrawCircle(32, 32, 20, goldenLine)
+		if err := r.BorderFill(32, 32, goldenFill, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"border_fill_square_8", func(t *testing.T, c Canvas) {
+		r := renderer(c)
+		r.SetFillConnectivity(Connectivity8)
+		r.DrawSquare(10, 10, 40, 0, goldenLine)
+		if err := r.BorderFill(30, 30, goldenFill, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"flood_fill_diagonal_4", func(t *testing.T, c Canvas) {
+		if err := diagonalPassage(c).FloodFill(16, 16, goldenFill, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"flood_fill_diagonal_8", func(t *testing.T, c Canvas) {
+		r := diagonalPassage(c)
+		r.SetFillConnectivity(Connectivity8)
+		if err := r.FloodFill(16, 16, goldenFill, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+	}},
+}
+
+// TestPrimitiveGolden renders every primitive into an offscreen canvas and compares it pixel-for-pixel
+// against the committed golden image.
+func TestPrimitiveGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			canvas := NewImageCanvas(goldenWidth, goldenHeight)
+			canvas.Fill(goldenBackground)
+			tc.draw(t, canvas)
+			checkGolden(t, tc.name, canvas.(*imageCanvas).GetImage())
+		})
+	}
+}
+
+// starPoints returns a closed self-intersecting five-pointed star.
+func starPoints(c Canvas) []Point2D {
+	return []Point2D{point(c, 32, 2), point(c, 50, 60), point(c, 2, 24), point(c, 62, 24), point(c, 14, 60), point(c, 32, 2)}
+}
+
+// diagonalPassage fills the top-right and the bottom-left quarters of the canvas, so the two other quarters
+// touch only at the corner between (31, 31) and (32, 32), and returns a renderer on the canvas.
+func diagonalPassage(c Canvas) PrimitiveRendererСlass {
+	r := renderer(c)
+	r.FillSquare(32, 0, 31, goldenLine)
+	r.FillSquare(0, 32, 31, goldenLine)
+	return r
+}
+
+// TestFillConnectivity checks that only 8-connectivity passes the diagonal gap between two regions.
+func TestFillConnectivity(t *testing.T) {
+	for _, tc := range []struct {
+		connectivity Connectivity
+		passes       bool
+	}{
+		{Connectivity4, false},
+		{Connectivity8, true},
+	} {
+		canvas := NewImageCanvas(goldenWidth, goldenHeight)
+		canvas.Fill(goldenBackground)
+		r := diagonalPassage(canvas)
+		r.SetFillConnectivity(tc.connectivity)
+		if err := r.FloodFill(16, 16, goldenFill, goldenLine); err != nil {
+			t.Fatal(err)
+		}
+		if got := sameColor(canvas.At(48, 48), goldenFill); got != tc.passes {
+			t.Errorf("connectivity %d: fill behind the diagonal gap = %v, want %v", tc.connectivity, got, tc.passes)
+		}
+		if !sameColor(canvas.At(48, 16), goldenLine) || !sameColor(canvas.At(16, 48), goldenLine) {
+			t.Errorf("connectivity %d: fill leaked into the boundary", tc.connectivity)
+		}
+	}
+}
+
+// TestDrawPolygonValidation checks that degenerate and open polygons are rejected.
+func TestDrawPolygonValidation(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	r := renderer(canvas)
+	cases := []struct {
+		name   string
+		points []Point2D
+	}{
+		{"two points", []Point2D{point(canvas, 1, 1), point(canvas, 10, 10)}},
+		{"two points closed", []Point2D{point(canvas, 1, 1), point(canvas, 10, 10), point(canvas, 1, 1)}},
+		{"open in x", []Point2D{point(canvas, 1, 1), point(canvas, 10, 1), point(canvas, 10, 10), point(canvas, 2, 1)}},
+		{"open in y", []Point2D{point(canvas, 1, 1), point(canvas, 10, 1), point(canvas, 10, 10), point(canvas, 1, 2)}},
+	}
+	for _, tc := range cases {
+		if err := r.DrawPolygon(tc.points, goldenLine); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+	if err := r.SetFillRule(FillRule(7)); err == nil {
+		t.Error("SetFillRule(7): expected an error")
+	}
+}
+
+// TestFillLargeRegion fills a whole window-sized canvas, which used to overflow the stack.
+func TestFillLargeRegion(t *testing.T) {
+	canvas := NewImageCanvas(800, 600)
+	canvas.Fill(goldenBackground)
+	r := renderer(canvas)
+	if err := r.FloodFill(400, 300, goldenFill, goldenLine); err != nil {
+		t.Fatalf("FloodFill: %v", err)
+	}
+	if err := r.BorderFill(0, 0, goldenLine, color.RGBA{255, 255, 255, 255}); err != nil {
+		t.Fatalf("BorderFill: %v", err)
+	}
+	for _, p := range []image.Point{{0, 0}, {799, 0}, {0, 599}, {799, 599}, {400, 300}} {
+		if got := canvas.At(p.X, p.Y); !sameColor(got, goldenLine) {
+			t.Errorf("pixel %v = %v, want %v", p, got, goldenLine)
+		}
+	}
+}
+
+// TestFillErrors checks that fills report bad start points and exceeded budgets instead of hanging.
+func TestFillErrors(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	canvas.Fill(goldenBackground)
+	r := renderer(canvas)
+
+	if err := r.FloodFill(-1, 10, goldenFill, goldenLine); err != ErrFillOutOfBounds {
+		t.Errorf("FloodFill off canvas: got %v, want %v", err, ErrFillOutOfBounds)
+	}
+	if err := r.BorderFill(10, goldenHeight, goldenFill, goldenLine); err != ErrFillOutOfBounds {
+		t.Errorf("BorderFill off canvas: got %v, want %v", err, ErrFillOutOfBounds)
+	}
+	if err := r.SetFillConnectivity(6); err == nil {
+		t.Error("SetFillConnectivity(6): expected an error")
+	}
+	if err := r.SetFillLimit(-1); err == nil {
+		t.Error("SetFillLimit(-1): expected an error")
+	}
+
+	r.SetFillLimit(100)
+	if err := r.FloodFill(10, 10, goldenFill, goldenLine); err != ErrFillLimitExceeded {
+		t.Errorf("FloodFill over budget: got %v, want %v", err, ErrFillLimitExceeded)
+	}
+	if err := r.BorderFill(10, 10, goldenLine, color.RGBA{255, 255, 255, 255}); err != ErrFillLimitExceeded {
+		t.Errorf("BorderFill over budget: got %v, want %v", err, ErrFillLimitExceeded)
+	}
+}
+
+// checkGolden compares the image with testdata/golden/<name>.png.
+// With -update the golden image is rewritten; on mismatch the actual and diff images are written to testdata/failed.
+func checkGolden(t *testing.T, name string, got *image.RGBA) {
+	t.Helper()
+	goldenPath := filepath.Join(goldenDir, name+".png")
+
+	if *updateGolden {
+		if err := writePNG(goldenPath, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := readPNG(goldenPath)
+	if err != nil {
+		t.Fatalf("reading golden image (run with -update to create it): %v", err)
+	}
+
+	diff, mismatches := diffImages(want, got)
+	if mismatches == 0 {
+		return
+	}
+
+	actualPath := filepath.Join(failedDir, name+".png")
+	diffPath := filepath.Join(failedDir, name+"_diff.png")
+	if err := writePNG(actualPath, got); err != nil {
+		t.Error(err)
+	}
+	if err := writePNG(diffPath, diff); err != nil {
+		t.Error(err)
+	}
+	t.Errorf("%d pixels differ from %s; actual image written to %s, diff to %s", mismatches, goldenPath, actualPath, diffPath)
+}
+
+// diffImages compares two images pixel by pixel.
+// The returned diff image shows matching pixels dimmed and mismatching pixels in red.
+func diffImages(want image.Image, got *image.RGBA) (*image.RGBA, int) {
+	bounds := got.Bounds().Union(want.Bounds())
+	diff := image.NewRGBA(bounds)
+	mismatches := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Point{x, y}
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			g := got.RGBAAt(x, y)
+			if !p.In(want.Bounds()) || !p.In(got.Bounds()) || w != g {
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				mismatches++
+				continue
+			}
+			diff.SetRGBA(x, y, color.RGBA{g.R / 4, g.G / 4, g.B / 4, 255})
+		}
+	}
+	return diff, mismatches
+}
+
+// readPNG decodes a PNG file.
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+// writePNG encodes an image as PNG, creating the parent directory if needed.
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
@@ -0,0 +1,284 @@
+package objects
+
+import (
+	"errors"
+	"image"
+	"image/color"
+)
+
+// Renderer draws a retained scene. The canvas is cleared with the background color and the visible nodes
+// are drawn again, so moving or hiding one object never damages another one.
+// With dirty tracking enabled only the areas of nodes whose transformation, look, bounds or visibility changed
+// since the previous frame are cleared and redrawn; the rest of the canvas keeps the pixels of the previous frame.
+type Renderer interface {
+	// GetScene returns the scene drawn by the renderer.
+	// @return Scene: The scene of the renderer.
+	GetScene() Scene
+
+	// SetBackgroundColor sets the color used to clear the canvas before drawing.
+	// The whole canvas is redrawn on the next frame.
+	// @param backgroundColor color.Color: The background color.
+	// @return error: Returns an error if the color is nil.
+	SetBackgroundColor(backgroundColor color.Color) error
+
+	// GetBackgroundColor returns the color used to clear the canvas before drawing.
+	// @return color.Color: The background color.
+	GetBackgroundColor() color.Color
+
+	// SetDirtyTracking enables or disables redrawing of the changed areas only.
+	// The canvas must keep its pixels between frames (for ebiten call ebiten.SetScreenClearedEveryFrame(false)).
+	// @param enabled bool: True to redraw only the changed areas.
+	// @return error: Returns nil if the mode is set successfully.
+	SetDirtyTracking(enabled bool) error
+
+	// Invalidate forces the whole canvas to be redrawn on the next frame.
+	// @return error: Returns nil if the renderer is invalidated successfully.
+	Invalidate() error
+
+	// Render clears the changed areas of the canvas (or the whole canvas) and draws the scene on it.
+	// @param screen Canvas: The canvas to draw on (an *ebiten.Image can be passed directly).
+	// @return error: Returns the first error reported while drawing.
+	Render(screen Canvas) error
+
+	// GetStats returns the statistics of the last rendered frame.
+	// @return RenderStats: The statistics of the last frame.
+	GetStats() RenderStats
+}
+
+// dirtyMargin is the number of pixels added around the bounds of a changed node,
+// covering the rounding done while rasterizing and the filtering of scaled bitmaps.
+const dirtyMargin = 1
+
+// RenderStats describes the work done by a Renderer for one frame.
+type RenderStats struct {
+	DirtyRects int // The number of merged rectangles which were redrawn.
+	DirtyArea  int // The number of pixels which were cleared and redrawn.
+	CanvasArea int // The number of pixels of the whole canvas.
+	DrawnNodes int // The number of node drawings, a node intersecting several rectangles is counted for each.
+}
+
+// renderState is what the renderer remembers about a node to detect changes between frames.
+type renderState struct {
+	visible    bool            // Whether the node and all its ancestors are visible.
+	transform  Transform2D     // The world transformation of the node.
+	bounds     image.Rectangle // The box of screen pixels covered by the node.
+	appearance any             // The color, or the frame and draw options of the node.
+}
+
+// sceneRenderer is an internal implementation of the Renderer interface.
+type sceneRenderer struct {
+	scene           Scene                      // The scene drawn every frame.
+	backgroundColor color.Color                // The color used to clear the canvas.
+	dirtyTracking   bool                       // Whether only the changed areas are redrawn.
+	invalid         bool                       // Whether the whole canvas must be redrawn on the next frame.
+	screen          Canvas                     // The canvas of the previous frame.
+	screenBounds    image.Rectangle            // The bounds of the canvas of the previous frame.
+	states          map[*sceneNode]renderState // The states of the nodes in the previous frame.
+	stats           RenderStats                // The statistics of the last frame.
+}
+
+// NewRenderer creates a renderer for the scene. Dirty tracking is disabled, so every frame is redrawn completely.
+// @param scene Scene: The scene to draw.
+// @param backgroundColor color.Color: The color used to clear the canvas before drawing.
+// @return Renderer: A new renderer.
+func NewRenderer(scene Scene, backgroundColor color.Color) Renderer {
+	return &sceneRenderer{
+		scene:           scene,
+		backgroundColor: backgroundColor,
+		dirtyTracking:   false,
+		invalid:         true,
+		states:          make(map[*sceneNode]renderState),
+	}
+}
+
+// GetScene returns the scene drawn by the renderer.
+// @return Scene: The scene of the renderer.
+func (sceneRenderer *sceneRenderer) GetScene() Scene {
+	return sceneRenderer.scene
+}
+
+// SetBackgroundColor sets the color used to clear the canvas before drawing.
+// @param backgroundColor color.Color: The background color.
+// @return error: Returns an error if the color is nil.
+func (sceneRenderer *sceneRenderer) SetBackgroundColor(backgroundColor color.Color) error {
+	if backgroundColor == nil {
+		return errors.New("renderer error: background color is nil")
+	}
+	sceneRenderer.backgroundColor = backgroundColor
+	return sceneRenderer.Invalidate()
+}
+
+// GetBackgroundColor returns the color used to clear the canvas before drawing.
+// @return color.Color: The background color.
+func (sceneRenderer *sceneRenderer) GetBackgroundColor() color.Color {
+	return sceneRenderer.backgroundColor
+}
+
+// SetDirtyTracking enables or disables redrawing of the changed areas only.
+// @param enabled bool: True to redraw only the changed areas.
+// @return error: Returns nil if the mode is set successfully.
+func (sceneRenderer *sceneRenderer) SetDirtyTracking(enabled bool) error {
+	sceneRenderer.dirtyTracking = enabled
+	return sceneRenderer.Invalidate()
+}
+
+// Invalidate forces the whole canvas to be redrawn on the next frame.
+// @return error: Returns nil if the renderer is invalidated successfully.
+func (sceneRenderer *sceneRenderer) Invalidate() error {
+	sceneRenderer.invalid = true
+	return nil
+}
+
+// GetStats returns the statistics of the last rendered frame.
+// @return RenderStats: The statistics of the last frame.
+func (sceneRenderer *sceneRenderer) GetStats() RenderStats {
+	return sceneRenderer.stats
+}
+
+// Render clears the changed areas of the canvas (or the whole canvas) and draws the scene on it.
+// Each changed node marks the area it covered in the previous frame and the area it covers now as dirty.
+// Overlapping dirty rectangles are merged and every merged rectangle is cleared and redrawn with all
+// visible nodes intersecting it, clipped to the rectangle.
+// @param screen Canvas: The canvas to draw on (an *ebiten.Image can be passed directly).
+// @return error: Returns the first error reported while drawing.
+func (sceneRenderer *sceneRenderer) Render(screen Canvas) error {
+	if screen == nil {
+		return errors.New("renderer error: canvas is nil")
+	}
+	root, err := asNode(sceneRenderer.scene.GetRoot())
+	if err != nil {
+		return err
+	}
+
+	area := screen.Bounds()
+	states := make(map[*sceneNode]renderState)
+	collectRenderStates(root, true, states)
+
+	var dirty []image.Rectangle
+	if !sceneRenderer.dirtyTracking || sceneRenderer.invalid || sceneRenderer.screen != screen || sceneRenderer.screenBounds != area {
+		dirty = []image.Rectangle{area}
+	} else {
+		for node, current := range states {
+			previous, exists := sceneRenderer.states[node]
+			if exists && previous == current {
+				continue
+			}
+			if exists && previous.visible {
+				dirty = append(dirty, previous.bounds.Inset(-dirtyMargin))
+			}
+			if current.visible {
+				dirty = append(dirty, current.bounds.Inset(-dirtyMargin))
+			}
+		}
+		for node, previous := range sceneRenderer.states {
+			if _, exists := states[node]; !exists && previous.visible {
+				dirty = append(dirty, previous.bounds.Inset(-dirtyMargin))
+			}
+		}
+		dirty = mergeRectangles(dirty, area)
+	}
+
+	sceneRenderer.states = states
+	sceneRenderer.screen = screen
+	sceneRenderer.screenBounds = area
+	sceneRenderer.invalid = false
+	sceneRenderer.stats = RenderStats{
+		DirtyRects: len(dirty),
+		CanvasArea: area.Dx() * area.Dy(),
+	}
+
+	for _, rect := range dirty {
+		sceneRenderer.stats.DirtyArea += rect.Dx() * rect.Dy()
+		clipped := clipCanvas(screen, rect)
+		clipped.Fill(sceneRenderer.backgroundColor)
+		root.setCanvas(clipped)
+		if err := sceneRenderer.drawNode(root, rect, states); err != nil {
+			sceneRenderer.invalid = true
+			return err
+		}
+	}
+	root.setCanvas(screen)
+	return nil
+}
+
+// drawNode draws a node and its children ordered by z-index if they intersect the rectangle.
+// @param node *sceneNode: The node to draw.
+// @param rect image.Rectangle: The rectangle which is redrawn.
+// @param states map[*sceneNode]renderState: The states of the nodes in the current frame.
+// @return error: Returns the first error reported while drawing.
+func (sceneRenderer *sceneRenderer) drawNode(node *sceneNode, rect image.Rectangle, states map[*sceneNode]renderState) error {
+	state := states[node]
+	if !state.visible {
+		return nil
+	}
+	if node.draw != nil && (node.bounds == nil || state.bounds.Inset(-dirtyMargin).Overlaps(rect)) {
+		if err := node.draw(); err != nil {
+			return err
+		}
+		sceneRenderer.stats.DrawnNodes++
+	}
+	for _, child := range node.sortedChildren() {
+		if err := sceneRenderer.drawNode(child, rect, states); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectRenderStates records the state of a node and its children.
+// @param node *sceneNode: The node to record.
+// @param parentVisible bool: Whether all ancestors of the node are visible.
+// @param states map[*sceneNode]renderState: The map the states are stored in.
+func collectRenderStates(node *sceneNode, parentVisible bool, states map[*sceneNode]renderState) {
+	state := renderState{
+		visible:   parentVisible && node.IsVisible(),
+		transform: node.transformableObject.GetWorldTransform(),
+	}
+	if state.visible {
+		state.bounds = node.GetBounds()
+		if node.appearance != nil {
+			state.appearance = node.appearance()
+		}
+	}
+	states[node] = state
+	for _, child := range node.children {
+		collectRenderStates(child, state.visible, states)
+	}
+}
+
+// mergeRectangles clips the rectangles to the area and merges them, until no two of them overlap
+// and merging any two of them would not save pixels.
+// @param rects []image.Rectangle: The rectangles to merge.
+// @param area image.Rectangle: The area of the canvas.
+// @return []image.Rectangle: The merged rectangles.
+func mergeRectangles(rects []image.Rectangle, area image.Rectangle) []image.Rectangle {
+	merged := make([]image.Rectangle, 0, len(rects))
+	for _, rect := range rects {
+		rect = rect.Intersect(area)
+		if !rect.Empty() {
+			merged = append(merged, rect)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(merged) && !changed; i++ {
+			for j := i + 1; j < len(merged); j++ {
+				union := merged[i].Union(merged[j])
+				if merged[i].Overlaps(merged[j]) || rectArea(union) <= rectArea(merged[i])+rectArea(merged[j]) {
+					merged[i] = union
+					merged = append(merged[:j], merged[j+1:]...)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// rectArea returns the number of pixels of a rectangle.
+// @param rect image.Rectangle: The rectangle.
+// @return int: The area of the rectangle.
+func rectArea(rect image.Rectangle) int {
+	return rect.Dx() * rect.Dy()
+}
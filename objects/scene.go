@@ -0,0 +1,512 @@
+package objects
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// ShapeDrawer is implemented by the shape objects (SquareObject, CircleObject and LineObject),
+// so they can be attached to a scene node.
+type ShapeDrawer interface {
+	// GetShapeObject returns the associated shape object.
+	// @return ShapeObject: The associated shape object.
+	GetShapeObject() ShapeObject
+
+	// Draw draws the shape with its current transformations.
+	// @return error: Returns nil if the drawing operation was successful.
+	Draw() error
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the shape.
+	// @return image.Rectangle: The bounding box of the shape.
+	Bounds() image.Rectangle
+
+	// GetColor returns the color of the shape.
+	// @return color.Color: The color of the shape.
+	GetColor() color.Color
+
+	// Contains tests whether a screen point hits the shape.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point is inside the shape or on its outline.
+	Contains(x, y int) bool
+}
+
+// Node represents an element of the scene graph.
+// A node holds a drawable and a transformable object, and its children. The transformation of a child is
+// composed with the transformation of its parent, so children move, rotate and scale together with the parent.
+// Children are drawn after their parent ordered by z-index, invisible nodes are skipped together with their children.
+// Visibility is stored in the isDrawn flag of the node's DrawableObject.
+type Node interface {
+	// GetDrawableObject returns the drawable object of the node.
+	// @return DrawableObject: The drawable object of the node.
+	GetDrawableObject() DrawableObject
+
+	// GetTransformableObject returns the transformable object of the node.
+	// @return TransformableObject: The transformable object of the node.
+	GetTransformableObject() TransformableObject
+
+	// AddChild attaches a node to this node. A node which already has a parent is moved.
+	// @param child Node: The node to attach.
+	// @return error: Returns an error if the child is this node or one of its ancestors.
+	AddChild(child Node) error
+
+	// RemoveChild detaches a child from this node.
+	// @param child Node: The node to detach.
+	// @return error: Returns an error if the node is not a child of this node.
+	RemoveChild(child Node) error
+
+	// Reparent moves the node to a new parent.
+	// @param parent Node: The new parent, or nil to detach the node.
+	// @return error: Returns an error if the new parent would create a cycle.
+	Reparent(parent Node) error
+
+	// GetParent returns the parent of the node.
+	// @return Node: The parent or nil.
+	GetParent() Node
+
+	// GetChildren returns the children of the node in drawing order.
+	// @return []Node: The children of the node.
+	GetChildren() []Node
+
+	// SetZIndex sets the drawing order of the node among its siblings. Nodes with a higher z-index are drawn later.
+	// @param z int: The z-index.
+	// @return error: Returns nil if the z-index is set successfully.
+	SetZIndex(z int) error
+
+	// GetZIndex returns the drawing order of the node among its siblings.
+	// @return int: The z-index.
+	GetZIndex() int
+
+	// SetVisible shows or hides the node together with its children.
+	// @param visible bool: True to show the node.
+	// @return error: Returns nil if the visibility is set successfully.
+	SetVisible(visible bool) error
+
+	// IsVisible returns whether the node is visible.
+	// @return bool: True if the node is visible.
+	IsVisible() bool
+
+	// GetBounds returns the axis-aligned box of screen pixels covered by the node's own object, without its children.
+	// @return image.Rectangle: The bounding box, empty for group nodes.
+	GetBounds() image.Rectangle
+
+	// Contains tests whether a screen point hits the node's own object, without its children.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point hits the object, always false for group nodes.
+	Contains(x, y int) bool
+
+	// Draw draws the node and its children.
+	// @return error: Returns the first error reported while drawing.
+	Draw() error
+}
+
+// sceneNode is an internal implementation of the Node interface.
+// It keeps the functions describing the attached object and links to its parent and children.
+type sceneNode struct {
+	drawableObject      DrawableObject      // The drawable object, its isDrawn flag is the visibility of the node.
+	transformableObject TransformableObject // The transformable object composed with the parent's one.
+	nodeContent                             // The functions describing the attached object, all nil for group nodes.
+	parent              *sceneNode          // The parent node.
+	children            []*sceneNode        // The children of the node.
+	zIndex              int                 // The drawing order among siblings.
+}
+
+// nodeContent holds the functions a scene node uses to work with its attached object.
+type nodeContent struct {
+	draw       func() error           // The function drawing the attached object.
+	bounds     func() image.Rectangle // The function returning the bounding box of the attached object.
+	contains   func(x, y int) bool    // The function testing whether a screen point hits the attached object.
+	appearance func() any             // The function returning what else than the geometry changes the look (color, frame).
+}
+
+// bitmapAppearance is what changes the look of a bitmap or sprite node besides its geometry.
+type bitmapAppearance struct {
+	name    string      // The name of the bitmap or of the current frame.
+	options DrawOptions // The draw options, flips, tint, transparency and blending.
+}
+
+// newBitmapAppearance creates the appearance of a bitmap node.
+// The tint is converted to a color.RGBA64, so the renderer can compare appearances of any color type.
+// @param name string: The name of the bitmap or of the current frame.
+// @param options DrawOptions: The draw options of the bitmap object.
+// @return bitmapAppearance: The appearance.
+func newBitmapAppearance(name string, options DrawOptions) bitmapAppearance {
+	if options.Tint != nil {
+		options.Tint = color.RGBA64Model.Convert(options.Tint)
+	}
+	return bitmapAppearance{name: name, options: options}
+}
+
+// newNode creates a visible node from its parts.
+// @param drawableObject DrawableObject: The drawable object of the node.
+// @param transformableObject TransformableObject: The transformable object of the node.
+// @param content nodeContent: The functions describing the attached object.
+// @return *sceneNode: The new node.
+func newNode(drawableObject DrawableObject, transformableObject TransformableObject, content nodeContent) *sceneNode {
+	drawableObject.Draw()
+	return &sceneNode{
+		drawableObject:      drawableObject,
+		transformableObject: transformableObject,
+		nodeContent:         content,
+		parent:              nil,
+		children:            nil,
+		zIndex:              0,
+	}
+}
+
+// NewNode creates a group node which draws nothing itself but moves its children.
+// @param gameObject GameObject: The game object of the node.
+// @return Node: A new group node.
+func NewNode(gameObject GameObject) Node {
+	return newNode(NewDrawableObject(gameObject), NewTransformableObject(gameObject), nodeContent{})
+}
+
+// NewShapeNode creates a node drawing a shape object (square, circle or line).
+// @param shape ShapeDrawer: The shape to attach.
+// @return Node: A new node drawing the shape.
+func NewShapeNode(shape ShapeDrawer) Node {
+	shapeObject := shape.GetShapeObject()
+	return newNode(shapeObject.GetDrawableObject(), shapeObject.GetTransformableObject(), nodeContent{
+		draw:     shape.Draw,
+		bounds:   shape.Bounds,
+		contains: shape.Contains,
+		appearance: func() any {
+			return shape.GetColor()
+		},
+	})
+}
+
+// NewBitmapNode creates a node drawing a bitmap of a bitmap object.
+// @param bitmapObject BitmapObject: The bitmap object to attach.
+// @param name string: The name of the bitmap to draw.
+// @param num int: The index of the BitmapHandler which holds the bitmap.
+// @return Node: A new node drawing the bitmap.
+func NewBitmapNode(bitmapObject BitmapObject, name string, num int) Node {
+	return newNode(bitmapObject.GetDrawableObject(), bitmapObject.GetTransformableObject(), nodeContent{
+		draw: func() error {
+			return bitmapObject.Draw(name, num)
+		},
+		bounds: func() image.Rectangle {
+			bounds, _ := bitmapObject.Bounds(name, num)
+			return bounds
+		},
+		contains: func(x, y int) bool {
+			return bitmapObject.Contains(name, num, x, y)
+		},
+		appearance: func() any {
+			return newBitmapAppearance(name, bitmapObject.GetDrawOptions())
+		},
+	})
+}
+
+// NewSpriteNode creates a node drawing the current frame of a sprite.
+// @param spriteObject SpriteObject: The sprite to attach.
+// @param bmNum int: The index of the BitmapHandler which holds the frames.
+// @return Node: A new node drawing the sprite.
+func NewSpriteNode(spriteObject SpriteObject, bmNum int) Node {
+	bitmapObject := spriteObject.GetBitmapObject()
+	return newNode(bitmapObject.GetDrawableObject(), bitmapObject.GetTransformableObject(), nodeContent{
+		draw: func() error {
+			name, err := spriteObject.GetCurrentName()
+			if err != nil {
+				return err
+			}
+			return bitmapObject.Draw(name, bmNum)
+		},
+		bounds: func() image.Rectangle {
+			bounds, _ := spriteObject.Bounds(bmNum)
+			return bounds
+		},
+		contains: func(x, y int) bool {
+			return spriteObject.Contains(x, y, bmNum)
+		},
+		appearance: func() any {
+			name, _ := spriteObject.GetCurrentName()
+			return newBitmapAppearance(name, bitmapObject.GetDrawOptions())
+		},
+	})
+}
+
+// asNode converts a Node to its internal implementation.
+// @param n Node: The node to convert.
+// @return *sceneNode: The internal node.
+// @return error: Returns an error if the node was not created by this package.
+func asNode(n Node) (*sceneNode, error) {
+	internal, ok := n.(*sceneNode)
+	if !ok || internal == nil {
+		return nil, errors.New("scene error: unsupported node")
+	}
+	return internal, nil
+}
+
+// GetDrawableObject returns the drawable object of the node.
+// @return DrawableObject: The drawable object of the node.
+func (node *sceneNode) GetDrawableObject() DrawableObject {
+	return node.drawableObject
+}
+
+// GetTransformableObject returns the transformable object of the node.
+// @return TransformableObject: The transformable object of the node.
+func (node *sceneNode) GetTransformableObject() TransformableObject {
+	return node.transformableObject
+}
+
+// AddChild attaches a node to this node. A node which already has a parent is moved.
+// @param child Node: The node to attach.
+// @return error: Returns an error if the child is this node or one of its ancestors.
+func (node *sceneNode) AddChild(child Node) error {
+	internal, err := asNode(child)
+	if err != nil {
+		return err
+	}
+	for ancestor := node; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor == internal {
+			return errors.New("scene error: node can not be a child of itself or its descendants")
+		}
+	}
+	if err := internal.transformableObject.SetParent(node.transformableObject); err != nil {
+		return err
+	}
+	if internal.parent != nil {
+		internal.parent.detach(internal)
+	}
+	internal.parent = node
+	node.children = append(node.children, internal)
+	return nil
+}
+
+// RemoveChild detaches a child from this node.
+// @param child Node: The node to detach.
+// @return error: Returns an error if the node is not a child of this node.
+func (node *sceneNode) RemoveChild(child Node) error {
+	internal, err := asNode(child)
+	if err != nil {
+		return err
+	}
+	if internal.parent != node {
+		return errors.New("scene error: node is not a child")
+	}
+	node.detach(internal)
+	internal.parent = nil
+	return internal.transformableObject.SetParent(nil)
+}
+
+// detach removes a child from the list of children.
+// @param child *sceneNode: The child to remove.
+func (node *sceneNode) detach(child *sceneNode) {
+	for i, c := range node.children {
+		if c == child {
+			node.children = append(node.children[:i], node.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reparent moves the node to a new parent.
+// @param parent Node: The new parent, or nil to detach the node.
+// @return error: Returns an error if the new parent would create a cycle.
+func (node *sceneNode) Reparent(parent Node) error {
+	if parent == nil {
+		if node.parent == nil {
+			return nil
+		}
+		return node.parent.RemoveChild(node)
+	}
+	return parent.AddChild(node)
+}
+
+// GetParent returns the parent of the node.
+// @return Node: The parent or nil.
+func (node *sceneNode) GetParent() Node {
+	if node.parent == nil {
+		return nil
+	}
+	return node.parent
+}
+
+// GetChildren returns the children of the node in drawing order.
+// @return []Node: The children of the node.
+func (node *sceneNode) GetChildren() []Node {
+	children := make([]Node, 0, len(node.children))
+	for _, child := range node.sortedChildren() {
+		children = append(children, child)
+	}
+	return children
+}
+
+// sortedChildren returns the children ordered by z-index, keeping the insertion order for equal z-indexes.
+// @return []*sceneNode: The ordered children.
+func (node *sceneNode) sortedChildren() []*sceneNode {
+	children := append([]*sceneNode(nil), node.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].zIndex < children[j].zIndex
+	})
+	return children
+}
+
+// SetZIndex sets the drawing order of the node among its siblings.
+// @param z int: The z-index.
+// @return error: Returns nil if the z-index is set successfully.
+func (node *sceneNode) SetZIndex(z int) error {
+	node.zIndex = z
+	return nil
+}
+
+// GetZIndex returns the drawing order of the node among its siblings.
+// @return int: The z-index.
+func (node *sceneNode) GetZIndex() int {
+	return node.zIndex
+}
+
+// SetVisible shows or hides the node together with its children by changing the isDrawn flag.
+// @param visible bool: True to show the node.
+// @return error: Returns nil if the visibility is set successfully.
+func (node *sceneNode) SetVisible(visible bool) error {
+	if visible {
+		return node.drawableObject.Draw()
+	}
+	return node.drawableObject.UnDraw()
+}
+
+// IsVisible returns whether the node is visible.
+// @return bool: True if the node is visible.
+func (node *sceneNode) IsVisible() bool {
+	return node.drawableObject.GetIsDrawn()
+}
+
+// GetBounds returns the axis-aligned box of screen pixels covered by the node's own object, without its children.
+// @return image.Rectangle: The bounding box, empty for group nodes.
+func (node *sceneNode) GetBounds() image.Rectangle {
+	if node.bounds == nil {
+		return image.Rectangle{}
+	}
+	return node.bounds()
+}
+
+// Contains tests whether a screen point hits the node's own object, without its children.
+// @param x, y int: The screen point.
+// @return bool: True if the point hits the object, always false for group nodes.
+func (node *sceneNode) Contains(x, y int) bool {
+	if node.contains == nil {
+		return false
+	}
+	return node.contains(x, y)
+}
+
+// pick returns the last drawn visible node under a screen point in the subtree of the node.
+// @param x, y int: The screen point.
+// @return *sceneNode: The node drawn on top at the point, or nil.
+func (node *sceneNode) pick(x, y int) *sceneNode {
+	if !node.IsVisible() {
+		return nil
+	}
+	children := node.sortedChildren()
+	for i := len(children) - 1; i >= 0; i-- {
+		if picked := children[i].pick(x, y); picked != nil {
+			return picked
+		}
+	}
+	if node.Contains(x, y) {
+		return node
+	}
+	return nil
+}
+
+// Draw draws the node and then its children ordered by z-index. Invisible nodes are skipped with their children.
+// @return error: Returns the first error reported while drawing.
+func (node *sceneNode) Draw() error {
+	if !node.IsVisible() {
+		return nil
+	}
+	if node.draw != nil {
+		if err := node.draw(); err != nil {
+			return err
+		}
+	}
+	for _, child := range node.sortedChildren() {
+		if err := child.Draw(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setCanvas points the game objects of the node and its children to a canvas.
+// @param canvas Canvas: The canvas to draw on.
+func (node *sceneNode) setCanvas(canvas Canvas) {
+	node.drawableObject.GetGameObject().SetCanvas(canvas)
+	node.transformableObject.GetGameObject().SetCanvas(canvas)
+	for _, child := range node.children {
+		child.setCanvas(canvas)
+	}
+}
+
+// Scene represents a tree of nodes drawn with a single traversal.
+type Scene interface {
+	// GetRoot returns the root node of the scene.
+	// @return Node: The root node.
+	GetRoot() Node
+
+	// AddNode attaches a node to the root of the scene.
+	// @param n Node: The node to attach.
+	// @return error: Returns an error if the node can not be attached.
+	AddNode(n Node) error
+
+	// Pick returns the visible node drawn on top at a screen point, useful for mouse picking.
+	// @param x, y int: The screen point.
+	// @return Node: The node under the point, or nil if there is none.
+	Pick(x, y int) Node
+
+	// Draw draws all visible nodes on the screen.
+	// @param screen Canvas: The canvas to draw on (an *ebiten.Image can be passed directly).
+	// @return error: Returns the first error reported while drawing.
+	Draw(screen Canvas) error
+}
+
+// scene is an internal implementation of the Scene interface.
+type scene struct {
+	root *sceneNode // The root node of the scene.
+}
+
+// NewScene creates an empty scene.
+// @param backgroundColor color.Color: The background color of the scene's root game object.
+// @return Scene: A new scene.
+func NewScene(backgroundColor color.Color) Scene {
+	gameObject := NewWScreenGameObject(backgroundColor)
+	return &scene{
+		root: newNode(NewDrawableObject(gameObject), NewTransformableObject(gameObject), nodeContent{}),
+	}
+}
+
+// GetRoot returns the root node of the scene.
+// @return Node: The root node.
+func (scene *scene) GetRoot() Node {
+	return scene.root
+}
+
+// AddNode attaches a node to the root of the scene.
+// @param n Node: The node to attach.
+// @return error: Returns an error if the node can not be attached.
+func (scene *scene) AddNode(n Node) error {
+	return scene.root.AddChild(n)
+}
+
+// Pick returns the visible node drawn on top at a screen point.
+// @param x, y int: The screen point.
+// @return Node: The node under the point, or nil if there is none.
+func (scene *scene) Pick(x, y int) Node {
+	picked := scene.root.pick(x, y)
+	if picked == nil {
+		return nil
+	}
+	return picked
+}
+
+// Draw points every node to the screen and draws all visible nodes.
+// @param screen Canvas: The canvas to draw on (an *ebiten.Image can be passed directly).
+// @return error: Returns the first error reported while drawing.
+func (scene *scene) Draw(screen Canvas) error {
+	scene.root.setCanvas(screen)
+	return scene.root.Draw()
+}
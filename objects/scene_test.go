@@ -0,0 +1,246 @@
+package objects
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSceneChildFollowsParent(t *testing.T) {
+	scene := NewScene(goldenBackground)
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	canvas.Fill(goldenBackground)
+
+	tank := NewShapeNode(EnhancedNewSquareObject(canvas, goldenBackground, 20, 22, 22, goldenLine))
+	turret := NewShapeNode(EnhancedNewLineObject(canvas, goldenBackground, 32, 32, 48, 32, goldenFill))
+	if err := scene.AddNode(tank); err != nil {
+		t.Fatal(err)
+	}
+	if err := tank.AddChild(turret); err != nil {
+		t.Fatal(err)
+	}
+	// Turning the tank by 90 degrees around its center points the turret down.
+	tank.GetTransformableObject().Rotate(90)
+	tank.GetTransformableObject().Translate(-10, 0)
+	if err := scene.Draw(canvas); err != nil {
+		t.Fatal(err)
+	}
+	img := canvas.(*imageCanvas).GetImage()
+	if !sameColor(img.At(22, 45), goldenFill) {
+		t.Errorf("turret end not drawn at (22, 45): %v", img.At(22, 45))
+	}
+	if !sameColor(img.At(45, 32), goldenBackground) {
+		t.Errorf("turret drawn at its untransformed position: %v", img.At(45, 32))
+	}
+}
+
+func TestSceneHierarchy(t *testing.T) {
+	gameObject := NewWScreenGameObject(goldenBackground)
+	parent, child, grandchild := NewNode(gameObject), NewNode(gameObject), NewNode(gameObject)
+	parent.AddChild(child)
+	child.AddChild(grandchild)
+
+	if err := grandchild.AddChild(parent); err == nil {
+		t.Error("expected an error when creating a cycle")
+	}
+	if err := parent.RemoveChild(grandchild); err == nil {
+		t.Error("expected an error when removing a node which is not a child")
+	}
+	if err := grandchild.Reparent(parent); err != nil {
+		t.Fatal(err)
+	}
+	if grandchild.GetParent() != parent || len(child.GetChildren()) != 0 {
+		t.Error("reparent did not move the node")
+	}
+	if grandchild.GetTransformableObject().GetParent() != parent.GetTransformableObject() {
+		t.Error("reparent did not link the transformations")
+	}
+	if err := grandchild.Reparent(nil); err != nil || grandchild.GetParent() != nil {
+		t.Error("reparent to nil did not detach the node")
+	}
+
+	child.SetZIndex(5)
+	last := NewNode(gameObject)
+	first := NewNode(gameObject)
+	first.SetZIndex(-1)
+	parent.AddChild(last)
+	parent.AddChild(first)
+	children := parent.GetChildren()
+	if children[0] != first || children[1] != last || children[2] != child {
+		t.Error("children are not ordered by z-index")
+	}
+}
+
+func TestSceneVisibility(t *testing.T) {
+	scene := NewScene(goldenBackground)
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	canvas.Fill(goldenBackground)
+
+	group := NewNode(NewWScreenGameObject(goldenBackground))
+	square := NewShapeNode(EnhancedNewSquareObject(canvas, goldenBackground, 10, 5, 5, goldenLine))
+	group.AddChild(square)
+	scene.AddNode(group)
+
+	if !group.IsVisible() || !group.GetDrawableObject().GetIsDrawn() {
+		t.Fatal("new nodes must be visible")
+	}
+	group.SetVisible(false)
+	scene.Draw(canvas)
+	if got := canvas.At(5, 5); !sameColor(got, goldenBackground) {
+		t.Errorf("hidden group drew its child: %v", got)
+	}
+	group.SetVisible(true)
+	scene.Draw(canvas)
+	if got := canvas.At(5, 5); !sameColor(got, color.RGBA{150, 100, 200, 255}) {
+		t.Errorf("visible group did not draw its child: %v", got)
+	}
+}
+
+func TestRendererRetainedMode(t *testing.T) {
+	scene := NewScene(goldenBackground)
+	renderer := NewRenderer(scene, goldenBackground)
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+
+	below := EnhancedNewSquareObject(canvas, goldenBackground, 30, 10, 10, goldenLine)
+	above := EnhancedNewSquareObject(canvas, goldenBackground, 10, 10, 10, goldenFill)
+	scene.AddNode(NewShapeNode(below))
+	scene.AddNode(NewShapeNode(above))
+	if err := renderer.Render(canvas); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving the upper square away must not leave a hole in the square below it.
+	above.Translate(30, 30)
+	if err := renderer.Render(canvas); err != nil {
+		t.Fatal(err)
+	}
+	if got := canvas.At(10, 10); !sameColor(got, goldenLine) {
+		t.Errorf("square below was damaged: %v", got)
+	}
+	if got := canvas.At(50, 50); !sameColor(got, goldenFill) {
+		t.Errorf("moved square not drawn: %v", got)
+	}
+
+	above.UnDraw()
+	renderer.Render(canvas)
+	if got := canvas.At(50, 50); !sameColor(got, goldenBackground) {
+		t.Errorf("hidden square still drawn: %v", got)
+	}
+	if err := renderer.Render(nil); err == nil {
+		t.Error("expected an error when rendering to a nil canvas")
+	}
+}
+
+func TestRendererDirtyRectangles(t *testing.T) {
+	scene := NewScene(goldenBackground)
+	renderer := NewRenderer(scene, goldenBackground)
+	renderer.SetDirtyTracking(true)
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+
+	still := EnhancedNewSquareObject(canvas, goldenBackground, 10, 4, 4, goldenLine)
+	moving := EnhancedNewSquareObject(canvas, goldenBackground, 10, 40, 40, goldenFill)
+	scene.AddNode(NewShapeNode(still))
+	scene.AddNode(NewShapeNode(moving))
+	renderer.Render(canvas)
+	if stats := renderer.GetStats(); stats.DirtyArea != stats.CanvasArea {
+		t.Errorf("first frame must be drawn completely, dirty area %d of %d", stats.DirtyArea, stats.CanvasArea)
+	}
+
+	renderer.Render(canvas)
+	if stats := renderer.GetStats(); stats.DirtyArea != 0 || stats.DrawnNodes != 0 {
+		t.Errorf("unchanged frame redrew %d pixels and %d nodes", stats.DirtyArea, stats.DrawnNodes)
+	}
+
+	moving.Translate(4, 0)
+	renderer.Render(canvas)
+	stats := renderer.GetStats()
+	if stats.DirtyRects != 1 || stats.DirtyArea == 0 || stats.DirtyArea >= stats.CanvasArea/4 {
+		t.Errorf("unexpected stats after moving one square: %+v", stats)
+	}
+	if stats.DrawnNodes != 1 {
+		t.Errorf("only the moved square must be redrawn, drawn %d nodes", stats.DrawnNodes)
+	}
+	if got := canvas.At(40, 40); !sameColor(got, goldenBackground) {
+		t.Errorf("old position of the moved square not cleared: %v", got)
+	}
+	if got := canvas.At(44, 40); !sameColor(got, goldenFill) {
+		t.Errorf("moved square not drawn: %v", got)
+	}
+	if got := canvas.At(4, 4); !sameColor(got, goldenLine) {
+		t.Errorf("untouched square damaged: %v", got)
+	}
+
+	moving.SetColor(goldenLine)
+	renderer.Render(canvas)
+	if got := canvas.At(44, 40); !sameColor(got, goldenLine) {
+		t.Errorf("color change not redrawn: %v", got)
+	}
+	moving.UnDraw()
+	renderer.Render(canvas)
+	if got := canvas.At(44, 40); !sameColor(got, goldenBackground) {
+		t.Errorf("hidden square not cleared: %v", got)
+	}
+}
+
+func TestRendererRedrawsDrawOptions(t *testing.T) {
+	scene := NewScene(goldenBackground)
+	renderer := NewRenderer(scene, goldenBackground)
+	renderer.SetDirtyTracking(true)
+	canvas := NewEbitenCanvas(ebiten.NewImage(goldenWidth, goldenHeight))
+
+	handler := NewBitmapHandler(10, 10)
+	handler.Create("frame", 4, 2, goldenFill)
+	bitmap := NewBitmapObject([]BitmapHandler{handler}, NewDrawableObject(NewCanvasGameObject(canvas, goldenBackground)))
+	scene.AddNode(NewBitmapNode(bitmap, "frame", 0))
+	// Drawing sets the origin of the bitmap to the coordinates of its handler, the frames after it are stable.
+	for i := 0; i < 3; i++ {
+		if err := renderer.Render(canvas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := renderer.GetStats(); stats.DrawnNodes != 0 {
+		t.Fatalf("unchanged bitmap redrawn: %+v", stats)
+	}
+
+	options := bitmap.GetDrawOptions()
+	options.Tint = color.RGBA{255, 0, 0, 255}
+	for _, change := range []func(){
+		func() { options.FlipX = true },
+		func() { options.Tint = color.NRGBA{0, 255, 0, 128} },
+		func() { options.Transparency = 0.5 },
+		func() { options.Blend = ebiten.BlendLighter },
+	} {
+		change()
+		if err := bitmap.SetDrawOptions(options); err != nil {
+			t.Fatal(err)
+		}
+		if err := renderer.Render(canvas); err != nil {
+			t.Fatal(err)
+		}
+		if stats := renderer.GetStats(); stats.DirtyRects != 1 || stats.DrawnNodes != 1 {
+			t.Errorf("changed draw options %+v not redrawn: %+v", options, stats)
+		}
+	}
+}
+
+func TestMergeRectangles(t *testing.T) {
+	area := image.Rect(0, 0, 100, 100)
+	merged := mergeRectangles([]image.Rectangle{
+		image.Rect(0, 0, 10, 10),
+		image.Rect(5, 5, 15, 15),
+		image.Rect(60, 60, 70, 70),
+		image.Rect(95, 95, 120, 120),
+		image.Rect(200, 200, 210, 210),
+	}, area)
+	want := []image.Rectangle{image.Rect(0, 0, 15, 15), image.Rect(60, 60, 70, 70), image.Rect(95, 95, 100, 100)}
+	if len(merged) != len(want) {
+		t.Fatalf("merged = %v, want %v", merged, want)
+	}
+	for i := range want {
+		if merged[i] != want[i] {
+			t.Errorf("merged[%d] = %v, want %v", i, merged[i], want[i])
+		}
+	}
+}
@@ -3,7 +3,6 @@ package objects
 import (
 	"image/color"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -42,7 +41,7 @@ type LineSegment interface {
 
 // lineSegment is a concrete implementation of the LineSegment interface.
 type lineSegment struct {
-	screen          *ebiten.Image
+	screen          Canvas
 	startPoint      Point2D
 	finalPoint      Point2D
 	col             color.Color
@@ -50,10 +49,10 @@ type lineSegment struct {
 }
 
 // NewLineSegment creates a new instance of a line segment.
-// @param screen *ebiten.Image: The screen to draw the line on.
+// @param screen Canvas: The canvas to draw the line on (an *ebiten.Image can be passed directly).
 // @param backgroundColor color.Color: The background color of the screen.
 // @return LineSegment: A new LineSegment instance.
-func NewLineSegment(screen *ebiten.Image, backgroundColor color.Color) LineSegment {
+func NewLineSegment(screen Canvas, backgroundColor color.Color) LineSegment {
 	return &lineSegment{
 		screen:          screen,
 		startPoint:      nil,
@@ -119,6 +118,7 @@ func (primitive *lineSegment) Segment(startPoint Point2D, finalPoint Point2D, co
 }
 
 // SegmentDefault draws a line segment using the default vector stroke rendering.
+// Canvases which are not backed by ebiten fall back to the Bresenham algorithm.
 // @param startPoint Point2D: The starting point of the line.
 // @param finalPoint Point2D: The ending point of the line.
 // @param col color.Color: The color of the line.
@@ -128,11 +128,16 @@ func (primitive *lineSegment) SegmentDefault(startPoint Point2D, finalPoint Poin
 	x1, y1 := startPoint.GetCoords()
 	x2, y2 := finalPoint.GetCoords()
 	primitive.col = col
+	screen := ebitenImageOf(primitive.screen)
+	if screen == nil {
+		primitive.Segment(startPoint, finalPoint, col)
+		return
+	}
 	x1_ := float32(x1)
 	x2_ := float32(x2)
 	y1_ := float32(y1)
 	y2_ := float32(y2)
-	vector.StrokeLine(primitive.screen, x1_, y1_, x2_, y2_, 1, col, false)
+	vector.StrokeLine(screen, x1_, y1_, x2_, y2_, 1, col, false)
 }
 
 // ChangeStart updates the starting point of the line segment.
@@ -0,0 +1,196 @@
+package objects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// snapshotVersion is the version of the snapshot format written by GameState.Save.
+const snapshotVersion = 1
+
+// Saveable is an object whose state can be stored in a game-state snapshot (AnimatedObject, PlayerObject).
+type Saveable interface {
+	// SaveState returns the state of the object as JSON.
+	// @return []byte: The saved state.
+	// @return error: Returns an error if the state can not be saved.
+	SaveState() ([]byte, error)
+
+	// RestoreState sets the state returned by SaveState.
+	// @param data []byte: The saved state.
+	// @return error: Returns an error if the state can not be restored.
+	RestoreState(data []byte) error
+}
+
+// GameState saves and restores the registered objects together as one snapshot.
+// Nothing is saved automatically, the game decides when to call Save and Restore.
+type GameState interface {
+	// Register adds an object to the snapshot under a key.
+	// @param key string: The unique key of the object in the snapshot.
+	// @param object Saveable: The object.
+	// @return error: Returns an error if the key is empty or used, or the object is nil.
+	Register(key string, object Saveable) error
+
+	// Unregister removes an object from the snapshot.
+	// @param key string: The key of the object.
+	// @return error: Returns an error if no object has the key.
+	Unregister(key string) error
+
+	// Save writes the states of all registered objects as JSON.
+	// @param writer io.Writer: The destination of the snapshot.
+	// @return error: Returns the first error of an object or of the writer.
+	Save(writer io.Writer) error
+
+	// Restore reads a snapshot written by Save and restores all registered objects.
+	// The snapshot is checked to contain every registered object before any of them is changed.
+	// @param reader io.Reader: The source of the snapshot.
+	// @return error: Returns an error if the snapshot can not be read, misses an object or an object rejects its state.
+	Restore(reader io.Reader) error
+
+	// SaveFile saves the snapshot to a file, replacing it only when the whole snapshot is written.
+	// @param path string: The path of the file.
+	// @return error: Returns an error if the snapshot or the file can not be written.
+	SaveFile(path string) error
+
+	// RestoreFile restores a snapshot saved by SaveFile.
+	// @param path string: The path of the file.
+	// @return error: Returns an error if the file can not be read or restored.
+	RestoreFile(path string) error
+}
+
+// snapshot is the JSON document written by GameState.Save.
+type snapshot struct {
+	Version int                        `json:"version"` // The version of the format.
+	Objects map[string]json.RawMessage `json:"objects"` // The states by key.
+}
+
+// gameState is an internal implementation of the GameState interface.
+type gameState struct {
+	objects map[string]Saveable // The registered objects by key.
+}
+
+// NewGameState creates a game state without registered objects.
+// @return GameState: A new game state.
+func NewGameState() GameState {
+	return &gameState{objects: make(map[string]Saveable)}
+}
+
+// Register adds an object to the snapshot under a key.
+// @param key string: The unique key of the object in the snapshot.
+// @param object Saveable: The object.
+// @return error: Returns an error if the key is empty or used, or the object is nil.
+func (gameState *gameState) Register(key string, object Saveable) error {
+	if key == "" {
+		return errors.New("snapshot error: key is empty")
+	}
+	if object == nil {
+		return errors.New("snapshot error: object is nil")
+	}
+	if _, ok := gameState.objects[key]; ok {
+		return fmt.Errorf("snapshot error: key %q is already registered", key)
+	}
+	gameState.objects[key] = object
+	return nil
+}
+
+// Unregister removes an object from the snapshot.
+// @param key string: The key of the object.
+// @return error: Returns an error if no object has the key.
+func (gameState *gameState) Unregister(key string) error {
+	if _, ok := gameState.objects[key]; !ok {
+		return fmt.Errorf("snapshot error: key %q is not registered", key)
+	}
+	delete(gameState.objects, key)
+	return nil
+}
+
+// Save writes the states of all registered objects as JSON.
+// @param writer io.Writer: The destination of the snapshot.
+// @return error: Returns the first error of an object or of the writer.
+func (gameState *gameState) Save(writer io.Writer) error {
+	document := snapshot{Version: snapshotVersion, Objects: make(map[string]json.RawMessage)}
+	for _, key := range gameState.keys() {
+		data, err := gameState.objects[key].SaveState()
+		if err != nil {
+			return fmt.Errorf("snapshot error: saving %q: %w", key, err)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("snapshot error: state of %q is not JSON", key)
+		}
+		document.Objects[key] = data
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// Restore reads a snapshot written by Save and restores all registered objects.
+// @param reader io.Reader: The source of the snapshot.
+// @return error: Returns an error if the snapshot can not be read, misses an object or an object rejects its state.
+func (gameState *gameState) Restore(reader io.Reader) error {
+	var document snapshot
+	if err := json.NewDecoder(reader).Decode(&document); err != nil {
+		return fmt.Errorf("snapshot error: %w", err)
+	}
+	if document.Version != snapshotVersion {
+		return fmt.Errorf("snapshot error: unsupported version %d", document.Version)
+	}
+	keys := gameState.keys()
+	for _, key := range keys {
+		if _, ok := document.Objects[key]; !ok {
+			return fmt.Errorf("snapshot error: no state for %q", key)
+		}
+	}
+	for _, key := range keys {
+		if err := gameState.objects[key].RestoreState(document.Objects[key]); err != nil {
+			return fmt.Errorf("snapshot error: restoring %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SaveFile saves the snapshot to a temporary file next to the path and renames it over the path.
+// @param path string: The path of the file.
+// @return error: Returns an error if the snapshot or the file can not be written.
+func (gameState *gameState) SaveFile(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // Does nothing after a successful rename
+	if err := gameState.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// RestoreFile restores a snapshot saved by SaveFile.
+// @param path string: The path of the file.
+// @return error: Returns an error if the file can not be read or restored.
+func (gameState *gameState) RestoreFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gameState.Restore(file)
+}
+
+// keys returns the registered keys in a stable order.
+// @return []string: The sorted keys.
+func (gameState *gameState) keys() []string {
+	keys := make([]string, 0, len(gameState.objects))
+	for key := range gameState.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
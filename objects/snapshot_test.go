@@ -0,0 +1,147 @@
+package objects
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnimateKeepsStateInMemory(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	animated := NewAnimatedObject(NewWScreenGameObject(goldenBackground), 5, "hero")
+	if err := animated.Animate(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := animated.Animate(5); err == nil || animated.GetCurrentFrame() != 3 {
+		t.Error("an invalid frame must be rejected and keep the current frame")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Animate wrote %d files, want none", len(files))
+	}
+	if other := NewAnimatedObject(NewWScreenGameObject(goldenBackground), 5, "hero"); other.GetCurrentFrame() != 0 {
+		t.Error("animations with the same name must not share their frame")
+	}
+}
+
+func TestGameStateSaveRestore(t *testing.T) {
+	animated := NewAnimatedObject(NewWScreenGameObject(goldenBackground), 5, "hero")
+	animated.Animate(2)
+	player := NewPlayerObject(nil, goldenBackground, goldenLine, 10, 20)
+	player.SetCalm(4)
+	player.SetRightMovement([]int{1, 2, 3})
+	player.GetAnimationStateMachine().SetState(ClipWalkRight)
+	player.GetAnimationStateMachine().GetPlayer().Seek(2)
+
+	state := NewGameState()
+	if err := state.Register("animation", animated); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Register("player", player); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Register("player", player); err == nil {
+		t.Error("expected an error for a used key")
+	}
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := state.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	animated.Animate(0)
+	player.GetSpriteObject().MoveObject(0, 0, 0)
+	player.GetAnimationStateMachine().SetState(ClipIdle)
+	if err := state.RestoreFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if animated.GetCurrentFrame() != 2 {
+		t.Errorf("restored frame = %d, want 2", animated.GetCurrentFrame())
+	}
+	x, y := player.GetSpriteObject().GetBitmapObject().GetBitmapHandler(0).GetCords()
+	frame, _ := player.GetAnimationStateMachine().GetFrame()
+	if x != 10 || y != 20 || player.GetAnimationStateMachine().GetState() != ClipWalkRight || frame != 3 {
+		t.Errorf("restored player at (%d, %d) in %q frame %d", x, y, player.GetAnimationStateMachine().GetState(), frame)
+	}
+}
+
+func TestGameStateRestoreErrors(t *testing.T) {
+	animated := NewAnimatedObject(NewWScreenGameObject(goldenBackground), 5, "hero")
+	state := NewGameState()
+	state.Register("animation", animated)
+
+	cases := map[string]string{
+		"not json":       "frame 3",
+		"missing object": `{"version": 1, "objects": {}}`,
+		"frame too big":  `{"version": 1, "objects": {"animation": {"frame": 9}}}`,
+		"wrong version":  `{"version": 7, "objects": {"animation": {"frame": 1}}}`,
+	}
+	for name, data := range cases {
+		if err := state.Restore(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := state.RestoreFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+
+	var buffer bytes.Buffer
+	if err := state.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), `"animation"`) {
+		t.Errorf("snapshot %s does not contain the registered key", buffer.String())
+	}
+}
+
+func TestGameStateRestoresPlayback(t *testing.T) {
+	player := NewPlayerObject(nil, goldenBackground, goldenLine, 10, 20)
+	machine := player.GetAnimationStateMachine()
+	player.SetCalm(4)
+	machine.SetClip(NewAnimationClip(ClipWalkRight, PlayPingPong, 1, 2, 3))
+	machine.SetClip(NewAnimationClip(ClipAttack, PlayOnce, 7, 8))
+
+	// Half way through the second frame on the way back.
+	machine.SetState(ClipWalkRight)
+	machine.GetPlayer().Update(3*tick + tick/2)
+	saved := machine.GetPlayer().GetPlayback()
+	data, err := player.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine.SetState(ClipIdle)
+	if err := player.RestoreState(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := machine.GetPlayer().GetPlayback(); got != saved || !got.Backward {
+		t.Errorf("restored playback = %+v, want %+v", got, saved)
+	}
+	machine.GetPlayer().Update(tick)
+	if frame, _ := machine.GetFrame(); frame != 1 {
+		t.Errorf("restored ping-pong clip shows frame %d, want 1 on its way back", frame)
+	}
+
+	machine.SetState(ClipAttack)
+	machine.GetPlayer().Update(3 * tick)
+	data, _ = player.SaveState()
+	machine.SetState(ClipIdle)
+	if err := player.RestoreState(data); err != nil {
+		t.Fatal(err)
+	}
+	if frame, _ := machine.GetFrame(); !machine.GetPlayer().IsFinished() || frame != 8 {
+		t.Errorf("restored once clip finished %v on frame %d, want finished on frame 8", machine.GetPlayer().IsFinished(), frame)
+	}
+
+	if err := machine.GetPlayer().SetPlayback(AnimationPlayback{Backward: true}); err == nil {
+		t.Error("expected an error for a once clip playing backwards")
+	}
+}
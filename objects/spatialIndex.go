@@ -0,0 +1,167 @@
+package objects
+
+import (
+	"Game_Engine/spatial"
+	"errors"
+	"image"
+)
+
+// SpatialIndex keeps the bounding boxes of scene nodes in a broad-phase index (spatial package) to find
+// the nodes near a rectangle or a point without testing every node.
+// A registered node is updated automatically when the transformation of its object or of one of its
+// parents changes. Changes not done through the transformation (a new sprite frame) need Refresh.
+type SpatialIndex interface {
+	// Register adds a node with its current bounding box.
+	// @param node Node: The node to add.
+	// @return error: Returns an error if the node is nil or already registered.
+	Register(node Node) error
+
+	// Unregister removes a node and stops following its transformation.
+	// @param node Node: The node to remove.
+	// @return error: Returns an error if the node is not registered.
+	Unregister(node Node) error
+
+	// Refresh updates the bounding box of a node after a change not done through its transformation.
+	// @param node Node: The node to update.
+	// @return error: Returns an error if the node is not registered.
+	Refresh(node Node) error
+
+	// QueryRect returns the nodes whose bounding boxes overlap a rectangle.
+	// @param rect image.Rectangle: The rectangle in screen coordinates.
+	// @return []Node: The nodes in registration order.
+	QueryRect(rect image.Rectangle) []Node
+
+	// QueryPoint returns the nodes whose bounding boxes contain a point.
+	// Use Node.Contains on the result for an exact hit-test.
+	// @param x, y int: The point in screen coordinates.
+	// @return []Node: The nodes in registration order.
+	QueryPoint(x, y int) []Node
+
+	// Nearest returns the k nodes whose bounding boxes are the closest to a point.
+	// @param x, y int: The point in screen coordinates.
+	// @param k int: The number of nodes to return.
+	// @return []Node: The nodes ordered by distance.
+	Nearest(x, y, k int) []Node
+
+	// Pairs returns all pairs of nodes whose bounding boxes overlap, the candidates for a collision test.
+	// @return [][2]Node: The pairs, the node registered first comes first.
+	Pairs() [][2]Node
+}
+
+// indexEntry stores a registered node.
+type indexEntry struct {
+	node     Node // The registered node.
+	listener int  // The identifier of the change listener registered on the node's transformable object.
+}
+
+// spatialIndex is an internal implementation of the SpatialIndex interface.
+// It gives every node an increasing identifier in the underlying index.
+type spatialIndex struct {
+	index   spatial.Index      // The broad-phase index storing the bounding boxes.
+	entries map[int]indexEntry // The registered nodes by identifier.
+	ids     map[Node]int       // The identifiers by node.
+	nextID  int                // The identifier given to the next node.
+}
+
+// NewSpatialIndex creates an empty index of scene nodes.
+// @param index spatial.Index: The empty broad-phase index to store the bounding boxes in, for example spatial.NewGrid(64).
+// @return SpatialIndex: A new index.
+// @return error: Returns an error if the index is nil or not empty.
+func NewSpatialIndex(index spatial.Index) (SpatialIndex, error) {
+	if index == nil || index.Len() != 0 {
+		return nil, errors.New("spatial index error: index must be empty")
+	}
+	return &spatialIndex{index: index, entries: map[int]indexEntry{}, ids: map[Node]int{}}, nil
+}
+
+// Register adds a node with its current bounding box and follows the changes of its transformation.
+// @param node Node: The node to add.
+// @return error: Returns an error if the node is nil or already registered.
+func (spatialIndex *spatialIndex) Register(node Node) error {
+	if node == nil {
+		return errors.New("spatial index error: node is nil")
+	}
+	if _, ok := spatialIndex.ids[node]; ok {
+		return errors.New("spatial index error: node already registered")
+	}
+	spatialIndex.nextID++
+	id := spatialIndex.nextID
+	if err := spatialIndex.index.Insert(id, node.GetBounds()); err != nil {
+		return err
+	}
+	listener := node.GetTransformableObject().AddChangeListener(func() {
+		spatialIndex.index.Update(id, node.GetBounds())
+	})
+	spatialIndex.entries[id] = indexEntry{node: node, listener: listener}
+	spatialIndex.ids[node] = id
+	return nil
+}
+
+// Unregister removes a node and its change listener.
+// @param node Node: The node to remove.
+// @return error: Returns an error if the node is not registered.
+func (spatialIndex *spatialIndex) Unregister(node Node) error {
+	id, ok := spatialIndex.ids[node]
+	if !ok {
+		return errors.New("spatial index error: node not registered")
+	}
+	node.GetTransformableObject().RemoveChangeListener(spatialIndex.entries[id].listener)
+	delete(spatialIndex.entries, id)
+	delete(spatialIndex.ids, node)
+	return spatialIndex.index.Remove(id)
+}
+
+// Refresh updates the bounding box of a node.
+// @param node Node: The node to update.
+// @return error: Returns an error if the node is not registered.
+func (spatialIndex *spatialIndex) Refresh(node Node) error {
+	id, ok := spatialIndex.ids[node]
+	if !ok {
+		return errors.New("spatial index error: node not registered")
+	}
+	return spatialIndex.index.Update(id, node.GetBounds())
+}
+
+// QueryRect returns the nodes whose bounding boxes overlap a rectangle.
+// @param rect image.Rectangle: The rectangle in screen coordinates.
+// @return []Node: The nodes in registration order.
+func (spatialIndex *spatialIndex) QueryRect(rect image.Rectangle) []Node {
+	return spatialIndex.nodes(spatialIndex.index.QueryRect(rect))
+}
+
+// QueryPoint returns the nodes whose bounding boxes contain a point.
+// @param x, y int: The point in screen coordinates.
+// @return []Node: The nodes in registration order.
+func (spatialIndex *spatialIndex) QueryPoint(x, y int) []Node {
+	return spatialIndex.nodes(spatialIndex.index.QueryPoint(x, y))
+}
+
+// Nearest returns the k nodes whose bounding boxes are the closest to a point.
+// @param x, y int: The point in screen coordinates.
+// @param k int: The number of nodes to return.
+// @return []Node: The nodes ordered by distance.
+func (spatialIndex *spatialIndex) Nearest(x, y, k int) []Node {
+	return spatialIndex.nodes(spatialIndex.index.Nearest(x, y, k))
+}
+
+// Pairs returns all pairs of nodes whose bounding boxes overlap.
+// @return [][2]Node: The pairs, the node registered first comes first.
+func (spatialIndex *spatialIndex) Pairs() [][2]Node {
+	pairs := spatialIndex.index.Pairs()
+	result := make([][2]Node, len(pairs))
+	for i, pair := range pairs {
+		result[i] = [2]Node{spatialIndex.entries[pair[0]].node, spatialIndex.entries[pair[1]].node}
+	}
+	return result
+}
+
+// nodes converts identifiers of the underlying index to nodes.
+// @param ids []int: The identifiers.
+// @return []Node: The nodes in the same order.
+func (spatialIndex *spatialIndex) nodes(ids []int) []Node {
+	result := make([]Node, len(ids))
+	for i, id := range ids {
+		result[i] = spatialIndex.entries[id].node
+	}
+	return result
+}
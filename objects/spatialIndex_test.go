@@ -0,0 +1,55 @@
+package objects
+
+import (
+	"Game_Engine/spatial"
+	"image"
+	"testing"
+)
+
+func TestSpatialIndexFollowsTransform(t *testing.T) {
+	canvas := NewImageCanvas(goldenWidth, goldenHeight)
+	grid, err := spatial.NewGrid(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := NewSpatialIndex(grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := NewShapeNode(EnhancedNewSquareObject(canvas, goldenBackground, 10, 0, 0, goldenLine))
+	child := NewShapeNode(EnhancedNewCircleObject(canvas, goldenBackground, 5, 5, 3, goldenFill))
+	other := NewShapeNode(EnhancedNewSquareObject(canvas, goldenBackground, 10, 40, 40, goldenLine))
+	parent.AddChild(child)
+	for _, node := range []Node{parent, child, other} {
+		if err := index.Register(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := index.Register(other); err == nil {
+		t.Error("expected an error for a registered node")
+	}
+	if got := index.Pairs(); len(got) != 1 || got[0] != [2]Node{parent, child} {
+		t.Errorf("pairs = %v, want the parent and its child", got)
+	}
+
+	// Moving the parent moves the child, both are updated without any call to the index.
+	parent.GetTransformableObject().Translate(40, 40)
+	if got := index.QueryPoint(45, 45); len(got) != 3 {
+		t.Errorf("point query after translate found %d nodes, want 3", len(got))
+	}
+	if got := index.QueryRect(image.Rect(0, 0, 20, 20)); len(got) != 0 {
+		t.Errorf("nodes still found at their old place: %v", got)
+	}
+	// The squares both start 40 pixels away on each axis, the circle inside them is the farthest one.
+	if got := index.Nearest(0, 0, 3); len(got) != 3 || got[0] != parent || got[2] != child {
+		t.Errorf("nearest nodes to the origin = %v, want the parent first and the child last", got)
+	}
+
+	if err := index.Unregister(child); err != nil {
+		t.Fatal(err)
+	}
+	parent.GetTransformableObject().Translate(0, 0)
+	if got := index.QueryPoint(5, 5); len(got) != 1 || got[0] != parent {
+		t.Errorf("point query after unregister = %v, want only the parent", got)
+	}
+}
@@ -3,9 +3,12 @@ package objects
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"image"
+	"io/fs"
+	"path"
 	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // SpriteObject represents an interface for managing and manipulating sprite objects.
@@ -25,6 +28,46 @@ type SpriteObject interface {
 	// @return error: Returns nil if the operation succeeds or an error if a failure occurs.
 	LoadBitmaps(folderPath string, bmNum int) error
 
+	// LoadSheet loads a sprite sheet image and cuts it into frames of the same size.
+	// The frames are named after the file with their position, hero.png gives hero_0, hero_1 and so on.
+	// @param filePath string: The path of the sprite sheet image.
+	// @param grid SheetGrid: The layout of the frames on the sheet.
+	// @param bmNum int: The index of the BitmapHandler to store the frames in.
+	// @return error: Returns an error if the image can not be loaded or the grid does not fit it.
+	LoadSheet(filePath string, grid SheetGrid, bmNum int) error
+
+	// LoadAtlas loads a JSON texture atlas (TexturePacker or Aseprite, hash or array) and its image
+	// from the file system of the BitmapHandler.
+	// The frames are named after the frames of the atlas and the tags of the atlas can be read with GetTagFrames.
+	// @param jsonPath string: The path of the JSON file, the image path of the atlas is relative to it.
+	// @param bmNum int: The index of the BitmapHandler to store the frames in.
+	// @return error: Returns an error if the atlas or its image can not be loaded or a frame is outside of the image.
+	LoadAtlas(jsonPath string, bmNum int) error
+
+	// LoadGIF loads the frames of an animated GIF from the file system of the BitmapHandler.
+	// The frames are named like the frames of LoadSheet and GetClips returns one clip named after the file,
+	// with the delays and the loop count of the GIF.
+	// @param filePath string: The path of the GIF file.
+	// @param bmNum int: The index of the BitmapHandler to store the frames in.
+	// @return error: Returns an error if the file can not be read or is not a GIF.
+	LoadGIF(filePath string, bmNum int) error
+
+	// GetFrameIndex returns the index of a bitmap by its name, the reverse of GetName.
+	// @param name string: The name of the bitmap.
+	// @return int: The index of the bitmap.
+	// @return error: Returns an error if no bitmap has the name.
+	GetFrameIndex(name string) (int, error)
+
+	// GetTagFrames returns the indices of the bitmaps of an atlas tag, ready to be used by an animation clip.
+	// @param tag string: The name of the tag.
+	// @return []int: The indices of the bitmaps in order.
+	// @return error: Returns an error if the loaded atlas has no such tag.
+	GetTagFrames(tag string) ([]int, error)
+
+	// GetClips returns the animation clips of the tags of the loaded atlas, see Atlas.Clips.
+	// @return []AnimationClip: The clips, empty if the bitmaps were not loaded from an atlas with tags.
+	GetClips() []AnimationClip
+
 	// GetName retrieves the name of a bitmap by its index.
 	// @param num int: The index of the bitmap.
 	// @return string: The name of the bitmap at the specified index.
@@ -43,15 +86,35 @@ type SpriteObject interface {
 	// @param num int: The index of the BitmapHandler to update.
 	// @return error: Returns nil if the operation succeeds or an error if movement fails.
 	MoveObject(x, y, num int) error
+
+	// GetCurrentName returns the name of the bitmap of the current animation frame.
+	// @return string: The name of the current bitmap.
+	// @return error: Returns an error if no bitmaps are loaded.
+	GetCurrentName() (string, error)
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the current frame.
+	// @param bmNum int: The index of the BitmapHandler which holds the frames.
+	// @return image.Rectangle: The bounding box of the sprite.
+	// @return error: Returns an error if no bitmaps are loaded.
+	Bounds(bmNum int) (image.Rectangle, error)
+
+	// Contains tests whether a screen point hits the current frame.
+	// @param x, y int: The screen point.
+	// @param bmNum int: The index of the BitmapHandler which holds the frames.
+	// @return bool: True if the point lies on the current frame.
+	Contains(x, y, bmNum int) bool
 }
 
 // spriteObject is an implementation of the SpriteObject interface.
 // It manages bitmaps, animation, and positional updates for a sprite.
 type spriteObject struct {
-	bitmapObject   BitmapObject   // The associated BitmapObject.
-	animatedObject AnimatedObject // The associated AnimatedObject.
-	dictionary     map[int]string // A mapping of bitmap indices to names.
-	name           string         // The name of the sprite.
+	bitmapObject   BitmapObject     // The associated BitmapObject.
+	animatedObject AnimatedObject   // The associated AnimatedObject.
+	dictionary     map[int]string   // A mapping of bitmap indices to names.
+	tags           map[string][]int // The bitmap indices of the tags of the loaded atlas.
+	clips          []AnimationClip  // The animation clips of the tags of the loaded atlas.
+	sheet          string           // The path of the loaded sprite sheet, also its bitmap name, empty if there is none.
+	name           string           // The name of the sprite.
 }
 
 // NewSpriteObject creates a new instance of a SpriteObject.
@@ -63,6 +126,9 @@ func NewSpriteObject(bitmapObject BitmapObject, name string) SpriteObject {
 		bitmapObject:   bitmapObject,
 		animatedObject: nil,
 		dictionary:     make(map[int]string),
+		tags:           make(map[string][]int),
+		clips:          nil,
+		sheet:          "",
 		name:           name,
 	}
 }
@@ -80,15 +146,23 @@ func (spriteObject *spriteObject) GetAnimatedObject() AnimatedObject {
 }
 
 // LoadBitmaps loads bitmap images from a folder and associates them with the SpriteObject.
+// The folder is read from the file system of the BitmapHandler.
 // @param folderPath string: The path to the folder containing bitmap images.
 // @param bmNum int: The index of the BitmapHandler to store the bitmaps in.
 // @return error: Returns nil if the operation succeeds or an error if a failure occurs.
 func (spriteObject *spriteObject) LoadBitmaps(folderPath string, bmNum int) error {
-	files, err := os.ReadDir(folderPath)
+	fsys := spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).GetFileSystem()
+	files, err := fs.ReadDir(fsys, assetPath(folderPath))
 	if err != nil {
 		return err
 	}
 
+	// Drop the bitmaps of the previous load, so they do not keep their images referenced.
+	for _, name := range spriteObject.dictionary {
+		spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).Delete(name)
+	}
+	spriteObject.dictionary = make(map[int]string)
+
 	for i, file := range files {
 		// Skip directories.
 		if file.IsDir() {
@@ -97,33 +171,202 @@ func (spriteObject *spriteObject) LoadBitmaps(folderPath string, bmNum int) erro
 
 		// Extract the file name without extension.
 		fileName := file.Name()
-		nameWithoutExt := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		nameWithoutExt := strings.TrimSuffix(fileName, path.Ext(fileName))
 
 		// Map the index to the file name (without extension).
 		spriteObject.dictionary[i] = nameWithoutExt
 	}
 
+	spriteObject.tags = make(map[string][]int)
+	spriteObject.clips = nil
+	if spriteObject.sheet != "" {
+		spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).Delete(spriteObject.sheet)
+		spriteObject.sheet = ""
+	}
+
 	// Initialize an AnimatedObject.
 	amOb := NewAnimatedObject(spriteObject.bitmapObject.GetDrawableObject().GetGameObject(), len(spriteObject.dictionary), spriteObject.name)
 	spriteObject.animatedObject = amOb
 
 	// Load each bitmap into the BitmapHandler.
-	for key := range spriteObject.dictionary {
-		width, height, err := getImageSize(filepath.Join(folderPath, spriteObject.dictionary[key]+".png"))
+	for i, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		err = spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).Load(spriteObject.dictionary[i], path.Join(assetPath(folderPath), file.Name()))
 		if err != nil {
-			fmt.Println("Error retrieving image dimensions:", err)
 			return err
 		}
-		spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).Create(spriteObject.dictionary[key], width, height, spriteObject.animatedObject.GetGameObject().GetBackgroundColor())
-		err = spriteObject.GetBitmapObject().GetBitmapHandler(bmNum).Load(spriteObject.dictionary[key], filepath.Join(folderPath, spriteObject.dictionary[key]+".png"))
+	}
+
+	return nil
+}
+
+// LoadSheet loads a sprite sheet image and cuts it into frames of the same size.
+// The frames share the pixels of the sheet, they are not copied.
+// @param filePath string: The path of the sprite sheet image.
+// @param grid SheetGrid: The layout of the frames on the sheet.
+// @param bmNum int: The index of the BitmapHandler to store the frames in.
+// @return error: Returns an error if the image can not be loaded or the grid does not fit it.
+func (spriteObject *spriteObject) LoadSheet(filePath string, grid SheetGrid, bmNum int) error {
+	sheet, err := spriteObject.loadSheet(filePath, bmNum)
+	if err != nil {
+		return err
+	}
+	rects, err := grid.Frames(sheet.Bounds().Dx(), sheet.Bounds().Dy())
+	if err != nil {
+		spriteObject.discardSheet(filePath, bmNum)
+		return err
+	}
+	base := strings.TrimSuffix(path.Base(assetPath(filePath)), path.Ext(filePath))
+	frames := make([]AtlasFrame, len(rects))
+	for i, rect := range rects {
+		frames[i] = AtlasFrame{Name: fmt.Sprintf("%s_%d", base, i), Rect: rect}
+	}
+	return spriteObject.setFrames(filePath, frames, nil, bmNum)
+}
+
+// LoadAtlas loads a JSON texture atlas and its image.
+// @param jsonPath string: The path of the JSON file, the image path of the atlas is relative to it.
+// @param bmNum int: The index of the BitmapHandler to store the frames in.
+// @return error: Returns an error if the atlas or its image can not be loaded or a frame is outside of the image.
+func (spriteObject *spriteObject) LoadAtlas(jsonPath string, bmNum int) error {
+	data, err := fs.ReadFile(spriteObject.bitmapObject.GetBitmapHandler(bmNum).GetFileSystem(), assetPath(jsonPath))
+	if err != nil {
+		return err
+	}
+	atlas, err := ParseAtlas(data)
+	if err != nil {
+		return err
+	}
+	if atlas.Image == "" {
+		return errors.New("atlas error: no image in meta")
+	}
+	imagePath := path.Join(path.Dir(assetPath(jsonPath)), atlas.Image)
+	if _, err := spriteObject.loadSheet(imagePath, bmNum); err != nil {
+		return err
+	}
+	return spriteObject.setFrames(imagePath, atlas.Frames, atlas.Tags, bmNum)
+}
+
+// LoadGIF loads the frames of an animated GIF, composed on a sheet which they share.
+// @param filePath string: The path of the GIF file.
+// @param bmNum int: The index of the BitmapHandler to store the frames in.
+// @return error: Returns an error if the file can not be read or is not a GIF.
+func (spriteObject *spriteObject) LoadGIF(filePath string, bmNum int) error {
+	handler := spriteObject.bitmapObject.GetBitmapHandler(bmNum)
+	file, err := handler.GetFileSystem().Open(assetPath(filePath))
+	if err != nil {
+		return &AssetError{Op: "load", Path: filePath, Err: err}
+	}
+	defer file.Close()
+	base := strings.TrimSuffix(path.Base(assetPath(filePath)), path.Ext(filePath))
+	sheet, frames, tag, err := gifSheet(file, base)
+	if err != nil {
+		return &AssetError{Op: "load", Path: filePath, Err: err}
+	}
+	if err := handler.Set(filePath, ebiten.NewImageFromImage(sheet)); err != nil {
+		return err
+	}
+	return spriteObject.setFrames(filePath, frames, []AtlasTag{tag}, bmNum)
+}
+
+// loadSheet loads the image holding every frame into the BitmapHandler, named after its path,
+// so the image comes from the AssetManager of the handler when it has one.
+// @param filePath string: The path of the image.
+// @param bmNum int: The index of the BitmapHandler to store the image in.
+// @return *ebiten.Image: The loaded image.
+// @return error: Returns an *AssetError if the image can not be loaded.
+func (spriteObject *spriteObject) loadSheet(filePath string, bmNum int) (*ebiten.Image, error) {
+	handler := spriteObject.bitmapObject.GetBitmapHandler(bmNum)
+	if err := handler.Load(filePath, filePath); err != nil {
+		return nil, err
+	}
+	sheet, _ := handler.Get(filePath)
+	return sheet, nil
+}
+
+// discardSheet deletes a sheet loaded by loadSheet whose frames could not be used, unless it is the current sheet.
+// @param filePath string: The path of the image.
+// @param bmNum int: The index of the BitmapHandler which holds the image.
+func (spriteObject *spriteObject) discardSheet(filePath string, bmNum int) {
+	if filePath != spriteObject.sheet {
+		spriteObject.bitmapObject.GetBitmapHandler(bmNum).Delete(filePath)
+	}
+}
+
+// setFrames replaces the bitmaps of the sprite with regions of a sheet and creates a new AnimatedObject for them.
+// @param sheetPath string: The path of the image holding every frame, loaded by loadSheet.
+// @param frames []AtlasFrame: The regions, their positions become the bitmap indices.
+// @param tags []AtlasTag: The tags of the frames, nil if there are none.
+// @param bmNum int: The index of the BitmapHandler to store the frames in.
+// @return error: Returns an error if a frame is outside of the sheet.
+func (spriteObject *spriteObject) setFrames(sheetPath string, frames []AtlasFrame, tags []AtlasTag, bmNum int) error {
+	handler := spriteObject.bitmapObject.GetBitmapHandler(bmNum)
+	sheet, _ := handler.Get(sheetPath)
+	for _, frame := range frames {
+		if !frame.Rect.In(sheet.Bounds()) {
+			spriteObject.discardSheet(sheetPath, bmNum)
+			return fmt.Errorf("atlas error: frame %q is outside of the image", frame.Name)
+		}
+	}
+	for _, name := range spriteObject.dictionary {
+		handler.Delete(name)
+	}
+	if spriteObject.sheet != "" && spriteObject.sheet != sheetPath {
+		handler.Delete(spriteObject.sheet)
+	}
+	spriteObject.sheet = sheetPath
+	spriteObject.dictionary = make(map[int]string, len(frames))
+	spriteObject.tags = make(map[string][]int, len(tags))
+	for i, frame := range frames {
+		spriteObject.dictionary[i] = frame.Name
+		err := handler.Set(frame.Name, sheet.SubImage(frame.Rect).(*ebiten.Image))
 		if err != nil {
 			return err
 		}
 	}
-
+	for _, tag := range tags {
+		for i := tag.From; i <= tag.To; i++ {
+			spriteObject.tags[tag.Name] = append(spriteObject.tags[tag.Name], i)
+		}
+	}
+	spriteObject.clips = Atlas{Frames: frames, Tags: tags}.Clips()
+	spriteObject.animatedObject = NewAnimatedObject(spriteObject.bitmapObject.GetDrawableObject().GetGameObject(), len(frames), spriteObject.name)
 	return nil
 }
 
+// GetFrameIndex returns the index of a bitmap by its name.
+// @param name string: The name of the bitmap.
+// @return int: The index of the bitmap.
+// @return error: Returns an error if no bitmap has the name.
+func (spriteObject *spriteObject) GetFrameIndex(name string) (int, error) {
+	for index, value := range spriteObject.dictionary {
+		if value == name {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("sprite error: no bitmap named %q", name)
+}
+
+// GetTagFrames returns the indices of the bitmaps of an atlas tag.
+// @param tag string: The name of the tag.
+// @return []int: A copy of the indices of the bitmaps in order.
+// @return error: Returns an error if the loaded atlas has no such tag.
+func (spriteObject *spriteObject) GetTagFrames(tag string) ([]int, error) {
+	frames, exists := spriteObject.tags[tag]
+	if !exists {
+		return nil, fmt.Errorf("sprite error: no tag named %q", tag)
+	}
+	return append([]int(nil), frames...), nil
+}
+
+// GetClips returns the animation clips of the tags of the loaded atlas.
+// @return []AnimationClip: A copy of the clips.
+func (spriteObject *spriteObject) GetClips() []AnimationClip {
+	return append([]AnimationClip(nil), spriteObject.clips...)
+}
+
 // SetBitmap selects a bitmap for drawing by its index and renders it using the specified BitmapHandler.
 // @param name int: The index of the bitmap to render.
 // @param bmNum int: The index of the BitmapHandler to use for rendering.
@@ -168,3 +411,37 @@ func (spriteObject *spriteObject) MoveObject(x, y, num int) error {
 	}
 	return nil
 }
+
+// GetCurrentName returns the name of the bitmap of the current animation frame.
+// @return string: The name of the current bitmap.
+// @return error: Returns an error if no bitmaps are loaded.
+func (spriteObject *spriteObject) GetCurrentName() (string, error) {
+	if spriteObject.animatedObject == nil {
+		return "", errors.New("sprite error: no bitmaps loaded")
+	}
+	return spriteObject.GetName(spriteObject.animatedObject.GetCurrentFrame())
+}
+
+// Bounds returns the axis-aligned box of screen pixels covered by the current frame.
+// @param bmNum int: The index of the BitmapHandler which holds the frames.
+// @return image.Rectangle: The bounding box of the sprite.
+// @return error: Returns an error if no bitmaps are loaded.
+func (spriteObject *spriteObject) Bounds(bmNum int) (image.Rectangle, error) {
+	name, err := spriteObject.GetCurrentName()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	return spriteObject.bitmapObject.Bounds(name, bmNum)
+}
+
+// Contains tests whether a screen point hits the current frame.
+// @param x, y int: The screen point.
+// @param bmNum int: The index of the BitmapHandler which holds the frames.
+// @return bool: True if the point lies on the current frame.
+func (spriteObject *spriteObject) Contains(x, y, bmNum int) bool {
+	name, err := spriteObject.GetCurrentName()
+	if err != nil {
+		return false
+	}
+	return spriteObject.bitmapObject.Contains(name, bmNum, x, y)
+}
@@ -0,0 +1,209 @@
+package objects
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SheetGrid describes a sprite sheet whose frames have the same size and are laid out in rows.
+type SheetGrid struct {
+	FrameWidth, FrameHeight int // The size of a frame in pixels.
+	Margin                  int // The empty pixels between the border of the sheet and the frames.
+	Spacing                 int // The empty pixels between two neighbouring frames.
+	Count                   int // The number of frames, 0 for every frame which fits on the sheet.
+}
+
+// Frames cuts a sheet into frames, from left to right and from top to bottom.
+// @param width, height int: The size of the sheet in pixels.
+// @return []image.Rectangle: The frames in reading order.
+// @return error: Returns an error if the grid is not valid or has fewer frames than Count.
+func (grid SheetGrid) Frames(width, height int) ([]image.Rectangle, error) {
+	if grid.FrameWidth <= 0 || grid.FrameHeight <= 0 {
+		return nil, errors.New("sheet error: frame size must be positive")
+	}
+	if grid.Margin < 0 || grid.Spacing < 0 || grid.Count < 0 {
+		return nil, errors.New("sheet error: margin, spacing and count can not be negative")
+	}
+	var frames []image.Rectangle
+	for y := grid.Margin; y+grid.FrameHeight <= height-grid.Margin; y += grid.FrameHeight + grid.Spacing {
+		for x := grid.Margin; x+grid.FrameWidth <= width-grid.Margin; x += grid.FrameWidth + grid.Spacing {
+			if grid.Count > 0 && len(frames) == grid.Count {
+				return frames, nil
+			}
+			frames = append(frames, image.Rect(x, y, x+grid.FrameWidth, y+grid.FrameHeight))
+		}
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("sheet error: no frame fits on the sheet")
+	}
+	if len(frames) < grid.Count {
+		return nil, fmt.Errorf("sheet error: %d frames fit on the sheet, want %d", len(frames), grid.Count)
+	}
+	return frames, nil
+}
+
+// AtlasFrame is a named region of the image of an atlas.
+type AtlasFrame struct {
+	Name     string          // The name of the frame, the file name of the source image without its extension.
+	Rect     image.Rectangle // The region of the frame on the atlas image.
+	Duration time.Duration   // How long the frame is shown in an animation, 0 if the atlas does not tell.
+}
+
+// AtlasTag names a range of frames of an atlas, for example the frames of an animation.
+type AtlasTag struct {
+	Name      string // The name of the tag.
+	From, To  int    // The positions of the first and the last frame, both included.
+	Direction string // How the frames are played, one of the Aseprite directions, AsepriteForward if empty.
+	Repeat    int    // How many times the frames are played, 0 for ever.
+}
+
+// Atlas is a texture atlas exported by TexturePacker or Aseprite as JSON.
+type Atlas struct {
+	Image  string       // The path of the atlas image, relative to the JSON file.
+	Frames []AtlasFrame // The frames in the order of the JSON file.
+	Tags   []AtlasTag   // The tags of meta.frameTags.
+}
+
+// atlasRect is the frame rectangle of the JSON atlas formats.
+type atlasRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// atlasFrameData is a frame of the JSON atlas formats, Filename is only set in the array format.
+type atlasFrameData struct {
+	Filename string    `json:"filename"`
+	Frame    atlasRect `json:"frame"`
+	Rotated  bool      `json:"rotated"`
+	Duration int       `json:"duration"` // In milliseconds, only written by Aseprite.
+}
+
+// atlasData is the JSON document of an atlas, frames are either an array or an object keyed by name.
+type atlasData struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"` // Aseprite writes the number as a string.
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// ParseAtlas reads a JSON atlas in the hash or the array format of TexturePacker and Aseprite.
+// The frames keep the order of the file, so the positions used by tags and animation clips are stable.
+// @param data []byte: The JSON document.
+// @return Atlas: The parsed atlas.
+// @return error: Returns an error if the document is not an atlas, a frame is rotated, empty, named twice
+// or has a negative duration, or a tag is out of range or has an unknown direction or repeat count.
+func ParseAtlas(data []byte) (Atlas, error) {
+	var document atlasData
+	if err := json.Unmarshal(data, &document); err != nil {
+		return Atlas{}, fmt.Errorf("atlas error: %w", err)
+	}
+	frames, err := parseAtlasFrames(document.Frames)
+	if err != nil {
+		return Atlas{}, err
+	}
+	atlas := Atlas{Image: document.Meta.Image}
+	names := make(map[string]bool)
+	for _, frame := range frames {
+		name := frame.Filename
+		if ext := filepath.Ext(name); ext != "" {
+			name = name[:len(name)-len(ext)]
+		}
+		if name == "" {
+			return Atlas{}, errors.New("atlas error: frame without a name")
+		}
+		if names[name] {
+			return Atlas{}, fmt.Errorf("atlas error: frame %q is defined twice", name)
+		}
+		if frame.Rotated {
+			return Atlas{}, fmt.Errorf("atlas error: frame %q is rotated, which is not supported", name)
+		}
+		if frame.Frame.W <= 0 || frame.Frame.H <= 0 {
+			return Atlas{}, fmt.Errorf("atlas error: frame %q is empty", name)
+		}
+		if frame.Duration < 0 {
+			return Atlas{}, fmt.Errorf("atlas error: frame %q has a negative duration", name)
+		}
+		names[name] = true
+		rect := image.Rect(frame.Frame.X, frame.Frame.Y, frame.Frame.X+frame.Frame.W, frame.Frame.Y+frame.Frame.H)
+		duration := time.Duration(frame.Duration) * time.Millisecond
+		atlas.Frames = append(atlas.Frames, AtlasFrame{Name: name, Rect: rect, Duration: duration})
+	}
+	for _, tag := range document.Meta.FrameTags {
+		if tag.Name == "" {
+			return Atlas{}, errors.New("atlas error: tag without a name")
+		}
+		if tag.From < 0 || tag.From > tag.To || tag.To >= len(atlas.Frames) {
+			return Atlas{}, fmt.Errorf("atlas error: tag %q is out of range", tag.Name)
+		}
+		switch tag.Direction {
+		case "", AsepriteForward, AsepriteReverse, AsepritePingPong, AsepritePingPongReverse:
+		default:
+			return Atlas{}, fmt.Errorf("atlas error: tag %q has an unknown direction %q", tag.Name, tag.Direction)
+		}
+		repeat := 0
+		if tag.Repeat != "" {
+			var err error
+			repeat, err = strconv.Atoi(tag.Repeat)
+			if err != nil || repeat < 0 {
+				return Atlas{}, fmt.Errorf("atlas error: tag %q has an invalid repeat count %q", tag.Name, tag.Repeat)
+			}
+		}
+		atlas.Tags = append(atlas.Tags, AtlasTag{Name: tag.Name, From: tag.From, To: tag.To, Direction: tag.Direction, Repeat: repeat})
+	}
+	return atlas, nil
+}
+
+// parseAtlasFrames reads the frames of an atlas in the order of the document.
+// The hash format is read token by token because a Go map would lose the order of the keys.
+// @param data json.RawMessage: The frames array or object.
+// @return []atlasFrameData: The frames with their names in Filename.
+// @return error: Returns an error if the frames are missing or malformed.
+func parseAtlasFrames(data json.RawMessage) ([]atlasFrameData, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("atlas error: no frames")
+	}
+	if data[0] == '[' {
+		var frames []atlasFrameData
+		if err := json.Unmarshal(data, &frames); err != nil {
+			return nil, fmt.Errorf("atlas error: %w", err)
+		}
+		return frames, nil
+	}
+	if data[0] != '{' {
+		return nil, errors.New("atlas error: frames must be an array or an object")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("atlas error: %w", err)
+	}
+	var frames []atlasFrameData
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("atlas error: %w", err)
+		}
+		var frame atlasFrameData
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, fmt.Errorf("atlas error: %w", err)
+		}
+		frame.Filename = key.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,156 @@
+package objects
+
+import (
+	"image"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSheetGridFrames(t *testing.T) {
+	cases := []struct {
+		name          string
+		grid          SheetGrid
+		width, height int
+		want          []image.Rectangle
+	}{
+		{"plain", SheetGrid{FrameWidth: 2, FrameHeight: 3}, 4, 6, []image.Rectangle{
+			image.Rect(0, 0, 2, 3), image.Rect(2, 0, 4, 3), image.Rect(0, 3, 2, 6), image.Rect(2, 3, 4, 6),
+		}},
+		{"margin and spacing", SheetGrid{FrameWidth: 2, FrameHeight: 2, Margin: 1, Spacing: 1}, 7, 4, []image.Rectangle{
+			image.Rect(1, 1, 3, 3), image.Rect(4, 1, 6, 3),
+		}},
+		{"count", SheetGrid{FrameWidth: 2, FrameHeight: 2, Count: 3}, 4, 4, []image.Rectangle{
+			image.Rect(0, 0, 2, 2), image.Rect(2, 0, 4, 2), image.Rect(0, 2, 2, 4),
+		}},
+	}
+	for _, tc := range cases {
+		got, err := tc.grid.Frames(tc.width, tc.height)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: frames = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	for _, grid := range []SheetGrid{{}, {FrameWidth: 8, FrameHeight: 8}, {FrameWidth: 2, FrameHeight: 2, Count: 5}, {FrameWidth: 2, FrameHeight: 2, Spacing: -1}} {
+		if _, err := grid.Frames(4, 4); err == nil {
+			t.Errorf("expected an error for %+v", grid)
+		}
+	}
+}
+
+func TestParseAtlas(t *testing.T) {
+	hash := `{
+		"frames": {
+			"walk2.png": {"frame": {"x": 16, "y": 0, "w": 16, "h": 16}},
+			"walk1.png": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}}
+		},
+		"meta": {"image": "hero.png", "frameTags": [{"name": "walk", "from": 0, "to": 1}]}
+	}`
+	array := `{
+		"frames": [
+			{"filename": "walk2", "frame": {"x": 16, "y": 0, "w": 16, "h": 16}},
+			{"filename": "walk1", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}}
+		],
+		"meta": {"image": "hero.png", "frameTags": [{"name": "walk", "from": 0, "to": 1}]}
+	}`
+	want := Atlas{
+		Image:  "hero.png",
+		Frames: []AtlasFrame{{Name: "walk2", Rect: image.Rect(16, 0, 32, 16)}, {Name: "walk1", Rect: image.Rect(0, 0, 16, 16)}},
+		Tags:   []AtlasTag{{Name: "walk", From: 0, To: 1}},
+	}
+	for name, data := range map[string]string{"hash": hash, "array": array} {
+		got, err := ParseAtlas([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: atlas = %+v, want %+v", name, got, want)
+		}
+	}
+
+	invalid := map[string]string{
+		"not json":     "frames",
+		"no frames":    `{"meta": {}}`,
+		"empty frame":  `{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 0, "h": 4}}}}`,
+		"rotated":      `{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}, "rotated": true}}}`,
+		"twice":        `{"frames": {"a.png": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}, "a.bmp": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}}}`,
+		"tag too long": `{"frames": [{"filename": "a", "frame": {"x": 0, "y": 0, "w": 4, "h": 4}}], "meta": {"frameTags": [{"name": "t", "from": 0, "to": 1}]}}`,
+	}
+	for name, data := range invalid {
+		if _, err := ParseAtlas([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHeroAtlas(t *testing.T) {
+	data, err := os.ReadFile("../Hero/hero.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	atlas, err := ParseAtlas(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Frames) != 24 || atlas.Frames[12].Name != "ri1" {
+		t.Errorf("hero atlas has %d frames, frame 12 is %q", len(atlas.Frames), atlas.Frames[12].Name)
+	}
+	// Every tag of the hero is a state of the player, which plays it with the durations of the export.
+	machine := newPlayerAnimation()
+	for _, clip := range atlas.Clips() {
+		if err := machine.SetClip(clip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	left := NewAnimationInput(false, true, false, false, false)
+	if frame, _ := machine.Update(left, 0); frame != 6 || machine.GetState() != ClipWalkLeft {
+		t.Errorf("walking left shows frame %d in state %q", frame, machine.GetState())
+	}
+	if frame, _ := machine.Update(left, 100*time.Millisecond); frame != 7 {
+		t.Errorf("after 100ms walking left shows frame %d, want 7", frame)
+	}
+}
+
+func TestAtlasClips(t *testing.T) {
+	data := `{
+		"frames": [
+			{"filename": "a0", "frame": {"x": 0, "y": 0, "w": 4, "h": 4}, "duration": 100},
+			{"filename": "a1", "frame": {"x": 4, "y": 0, "w": 4, "h": 4}, "duration": 50},
+			{"filename": "a2", "frame": {"x": 8, "y": 0, "w": 4, "h": 4}, "duration": 100},
+			{"filename": "b0", "frame": {"x": 0, "y": 4, "w": 4, "h": 4}}
+		],
+		"meta": {"frameTags": [
+			{"name": "walk_left", "from": 0, "to": 2, "direction": "forward"},
+			{"name": "back", "from": 0, "to": 2, "direction": "reverse"},
+			{"name": "swing", "from": 1, "to": 2, "direction": "pingpong_reverse"},
+			{"name": "attack", "from": 0, "to": 2, "direction": "pingpong", "repeat": "1"},
+			{"name": "idle", "from": 2, "to": 3}
+		]}
+	}`
+	atlas, err := ParseAtlas([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms := time.Millisecond
+	want := []AnimationClip{
+		{Name: "walk_left", Frames: []int{0, 1, 2}, Durations: []time.Duration{100 * ms, 50 * ms, 100 * ms}, Mode: PlayLoop},
+		{Name: "back", Frames: []int{2, 1, 0}, Durations: []time.Duration{100 * ms, 50 * ms, 100 * ms}, Mode: PlayLoop},
+		{Name: "swing", Frames: []int{2, 1}, Durations: []time.Duration{100 * ms, 50 * ms}, Mode: PlayPingPong},
+		{Name: "attack", Frames: []int{0, 1, 2, 1, 0}, Durations: []time.Duration{100 * ms, 50 * ms, 100 * ms, 50 * ms, 100 * ms}, Mode: PlayOnce},
+		{Name: "idle", Frames: []int{2, 3}, Mode: PlayLoop}, // b0 has no duration, so the clip uses its FPS
+	}
+	if got := atlas.Clips(); !reflect.DeepEqual(got, want) {
+		t.Errorf("clips = %+v, want %+v", got, want)
+	}
+
+	for _, tag := range []string{`"direction": "sideways"`, `"repeat": "twice"`} {
+		data := `{"frames": [{"filename": "a", "frame": {"x": 0, "y": 0, "w": 4, "h": 4}}], "meta": {"frameTags": [{"name": "t", "from": 0, "to": 0, ` + tag + `}]}}`
+		if _, err := ParseAtlas([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", tag)
+		}
+	}
+}
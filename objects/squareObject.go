@@ -1,12 +1,16 @@
 package objects
 
 import (
+	"Game_Engine/collision"
+	"errors"
+	"image"
 	"image/color"
-
-	"github.com/hajimehoshi/ebiten/v2"
+	"math"
 )
 
 // SquareObject represents a square object that can be drawn, transformed (scaled, rotated, translated), and undrawn.
+// The square is retained: it records its geometry and transformation, and is rasterized only by Draw,
+// usually called by a Renderer which clears and redraws the frame.
 // It provides methods to manipulate the square's transformations and rendering.
 // Also this object inherit ShapeObject and use primitive for drawing square
 type SquareObject interface {
@@ -18,40 +22,61 @@ type SquareObject interface {
 	// @return error: Returns nil if the drawing operation was successful.
 	Draw() error
 
-	// UnDraw removes the square object from the screen.
+	// UnDraw hides the square object, so it is skipped when the frame is redrawn. No pixels are changed.
 	// @return error: Returns nil if the undrawing operation was successful.
 	UnDraw() error
 
 	// Translate moves the square object by the specified x and y values.
-	// @param x int: The x translation value.
-	// @param y int: The y translation value.
+	// @param x float64: The x translation value.
+	// @param y float64: The y translation value.
 	// @return error: Returns nil if the translation operation was successful.
-	Translate(x, y int) error
+	Translate(x, y float64) error
 
 	// Scale scales the square object by the specified scale factor.
-	// @param S int: The scaling factor for the square.
+	// @param S float64: The scaling factor for the square.
 	// @return error: Returns nil if the scaling operation was successful.
-	Scale(S int) error
+	Scale(S float64) error
 
 	// Rotate rotates the square object by the specified angle.
-	// @param angle int: The angle to rotate the square object.
+	// @param angle float64: The angle in degrees to rotate the square object.
 	// @return error: Returns nil if the rotation operation was successful.
-	Rotate(angle int) error
+	Rotate(angle float64) error
+
+	// Bounds returns the axis-aligned box of screen pixels covered by the square with its current transformations.
+	// @return image.Rectangle: The bounding box of the square.
+	Bounds() image.Rectangle
+
+	// SetColor sets the color of the square.
+	// @param color color.Color: The new color of the square.
+	// @return error: Returns an error if the color is nil.
+	SetColor(color color.Color) error
+
+	// GetColor returns the color of the square.
+	// @return color.Color: The color of the square.
+	GetColor() color.Color
+
+	// Contains tests whether a screen point hits the square with its current transformations.
+	// @param x, y int: The screen point.
+	// @return bool: True if the point is inside the square or on its outline.
+	Contains(x, y int) bool
+
+	// Collider returns the shape of the transformed square for the collision package.
+	// @return collision.Shape: A rotated rectangle, or a polygon if the transformation shears the square.
+	Collider() collision.Shape
 }
 
 // squareObject is an internal implementation of the SquareObject interface.
-// It holds references to a shape object, square top position, square length,
-// primitive renderer class, and the color of the square.
+// It holds references to a shape object, square top position, square length and the color of the square.
+// The square is drawn with a primitive renderer on the current canvas of its game object.
 type squareObject struct {
-	shapeObject  ShapeObject            // The associated shape object.
-	squareTop    Point2D                // The top-left coordinates of the square.
-	squareLenght int                    // The length of the sides of the square.
-	primitive    PrimitiveRendererСlass // The primitive object - renderer for drawing the square.
-	color        color.Color            // The color of the square.
+	shapeObject  ShapeObject // The associated shape object.
+	squareTop    Point2D     // The top-left coordinates of the square.
+	squareLenght int         // The length of the sides of the square.
+	color        color.Color // The color of the square.
 }
 
 // NewSquareObject creates a new square object with the specified shape object,
-// top-left position, square length, and color.
+// top-left position, square length, and color. The center of the square becomes the origin of its transformation.
 // @param shapeObject ShapeObject: The shape object to associate with the square object.
 // @param x int: The x-coordinate for the top-left corner of the square.
 // @param y int: The y-coordinate for the top-left corner of the square.
@@ -59,34 +84,29 @@ type squareObject struct {
 // @param color color.Color: The color of the square.
 // @return SquareObject: A new instance of the square object.
 func NewSquareObject(shapeObject ShapeObject, x, y int, squareLenght int, color color.Color) SquareObject {
+	half := float64(squareLenght) / 2
+	shapeObject.GetTransformableObject().SetOrigin(float64(x)+half, float64(y)+half)
 	return &squareObject{
 		shapeObject:  shapeObject,
-		squareTop:    NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
+		squareTop:    NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetCanvas(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
 		squareLenght: squareLenght,
 		color:        color,
-		primitive:    NewPrimitiveRendererclass(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
 	}
 }
 
 // EnhancedNewSquareObject creates a new square object with the specified screen, background color, square length,
 // position, and color. This method initializes a new game object and shape object as well.
-// @param screen *ebiten.Image: The screen where the square will be drawn.
+// @param screen Canvas: The canvas where the square will be drawn (an *ebiten.Image can be passed directly).
 // @param backgroundColor color.Color: The background color for the square.
 // @param squareLenght int: The length of the sides of the square.
 // @param x int: The x-coordinate for the top-left corner of the square.
 // @param y int: The y-coordinate for the top-left corner of the square.
 // @param color color.Color: The color of the square.
 // @return SquareObject: A new instance of the square object.
-func EnhancedNewSquareObject(screen *ebiten.Image, backgroundColor color.Color, squareLenght, x, y int, color color.Color) SquareObject {
-	gmob := NewGameObject(screen, backgroundColor)
+func EnhancedNewSquareObject(screen Canvas, backgroundColor color.Color, squareLenght, x, y int, color color.Color) SquareObject {
+	gmob := NewCanvasGameObject(screen, backgroundColor)
 	shapeObject := NewShapeObject(NewDrawableObject(gmob), NewTransformableObject(gmob))
-	return &squareObject{
-		shapeObject:  shapeObject,
-		squareTop:    NewPoint2D(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor(), x, y, color),
-		squareLenght: squareLenght,
-		color:        color,
-		primitive:    NewPrimitiveRendererclass(shapeObject.GetDrawableObject().GetGameObject().GetScreen(), shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor()),
-	}
+	return NewSquareObject(shapeObject, x, y, squareLenght, color)
 }
 
 // GetShapeObject returns the associated shape object of the square object.
@@ -96,50 +116,137 @@ func (squareObject *squareObject) GetShapeObject() ShapeObject {
 }
 
 // Draw draws the square object on the screen with its current transformations (translation, scale, rotation).
+// The corners are mapped to the screen through the world transformation of the square.
 // @return error: Returns nil if the drawing operation was successful.
 func (squareObject *squareObject) Draw() error {
-	x, y := squareObject.squareTop.GetCoords()
-	squareObject.primitive.DrawSquare(x+squareObject.GetShapeObject().GetTransformableObject().GetTranslationX(), y+squareObject.GetShapeObject().GetTransformableObject().GetTranslationY(), squareObject.squareLenght*squareObject.GetShapeObject().GetTransformableObject().GetScale(), squareObject.GetShapeObject().GetTransformableObject().GetAngle(), squareObject.color)
+	squareObject.render(squareObject.color)
+	squareObject.shapeObject.GetDrawableObject().SetBounds(squareObject.Bounds())
 	squareObject.shapeObject.GetDrawableObject().Draw()
 	return nil
 }
 
-// UnDraw removes the square object from the screen, effectively undrawing it.
+// UnDraw hides the square object by marking it as not drawn. The pixels disappear with the next redraw of the frame.
 // @return error: Returns nil if the undrawing operation was successful.
 func (squareObject *squareObject) UnDraw() error {
-	x, y := squareObject.squareTop.GetCoords()
-	squareObject.primitive.DrawSquare(x+squareObject.GetShapeObject().GetTransformableObject().GetTranslationX(), y+squareObject.GetShapeObject().GetTransformableObject().GetTranslationY(), squareObject.squareLenght*squareObject.GetShapeObject().GetTransformableObject().GetScale(), squareObject.GetShapeObject().GetTransformableObject().GetAngle(), squareObject.shapeObject.GetDrawableObject().GetGameObject().GetBackgroundColor())
-	squareObject.shapeObject.GetDrawableObject().Draw()
+	squareObject.shapeObject.GetDrawableObject().UnDraw()
 	return nil
 }
 
+// Bounds returns the axis-aligned box of screen pixels covered by the square with its current transformations.
+// @return image.Rectangle: The bounding box of the square.
+func (squareObject *squareObject) Bounds() image.Rectangle {
+	x, y := squareObject.squareTop.GetCoords()
+	s := squareObject.squareLenght
+	transform := squareObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	xs, ys := make([]float64, 0, 4), make([]float64, 0, 4)
+	for _, corner := range [4][2]int{{x, y}, {x + s, y}, {x + s, y + s}, {x, y + s}} {
+		cornerX, cornerY := transform.Apply(float64(corner[0]), float64(corner[1]))
+		xs, ys = append(xs, cornerX), append(ys, cornerY)
+	}
+	return pixelBounds(xs, ys)
+}
+
+// render draws the outline of the transformed square in the given color.
+// @param col color.Color: The color of the outline.
+func (squareObject *squareObject) render(col color.Color) {
+	gameObject := squareObject.shapeObject.GetDrawableObject().GetGameObject()
+	primitive := NewPrimitiveRendererclass(gameObject.GetCanvas(), gameObject.GetBackgroundColor())
+	x, y := squareObject.squareTop.GetCoords()
+	s := squareObject.squareLenght
+	transform := squareObject.shapeObject.GetTransformableObject().GetWorldTransform()
+
+	corners := [4][2]int{{x, y}, {x + s, y}, {x + s, y + s}, {x, y + s}}
+	for i := range corners {
+		corners[i][0], corners[i][1] = transform.ApplyInt(corners[i][0], corners[i][1])
+	}
+	for i := range corners {
+		next := corners[(i+1)%len(corners)]
+		primitive.segment(corners[i][0], corners[i][1], next[0], next[1], col)
+	}
+}
+
 // Translate moves the square object by the specified x and y values.
-// @param x int: The x translation value.
-// @param y int: The y translation value.
+// @param x float64: The x translation value.
+// @param y float64: The y translation value.
 // @return error: Returns nil if the translation operation was successful.
-func (squareObject *squareObject) Translate(x, y int) error {
-	squareObject.UnDraw()
+func (squareObject *squareObject) Translate(x, y float64) error {
 	squareObject.GetShapeObject().GetTransformableObject().Translate(x, y)
-	squareObject.Draw()
 	return nil
 }
 
 // Scale scales the square object by the specified scale factor.
-// @param S int: The scaling factor for the square.
+// @param S float64: The scaling factor for the square.
 // @return error: Returns nil if the scaling operation was successful.
-func (squareObject *squareObject) Scale(S int) error {
-	squareObject.UnDraw()
+func (squareObject *squareObject) Scale(S float64) error {
 	squareObject.GetShapeObject().GetTransformableObject().Scale(S)
-	squareObject.Draw()
 	return nil
 }
 
 // Rotate rotates the square object by the specified angle.
-// @param angle int: The angle to rotate the square object.
+// @param angle float64: The angle in degrees to rotate the square object.
 // @return error: Returns nil if the rotation operation was successful.
-func (squareObject *squareObject) Rotate(angle int) error {
-	squareObject.UnDraw()
+func (squareObject *squareObject) Rotate(angle float64) error {
 	squareObject.GetShapeObject().GetTransformableObject().Rotate(angle)
-	squareObject.Draw()
 	return nil
 }
+
+// SetColor sets the color of the square. The new color is used by the next Draw.
+// @param color color.Color: The new color of the square.
+// @return error: Returns an error if the color is nil.
+func (squareObject *squareObject) SetColor(color color.Color) error {
+	if color == nil {
+		return errors.New("square error: color is nil")
+	}
+	squareObject.color = color
+	return nil
+}
+
+// GetColor returns the color of the square.
+// @return color.Color: The color of the square.
+func (squareObject *squareObject) GetColor() color.Color {
+	return squareObject.color
+}
+
+// Contains tests whether a screen point hits the square with its current transformations.
+// The corners are transformed like in Draw and the point is tested against the resulting polygon.
+// @param x, y int: The screen point.
+// @return bool: True if the point is inside the square or on its outline.
+func (squareObject *squareObject) Contains(x, y int) bool {
+	gameObject := squareObject.shapeObject.GetDrawableObject().GetGameObject()
+	canvas, backgroundColor := gameObject.GetCanvas(), gameObject.GetBackgroundColor()
+	left, top := squareObject.squareTop.GetCoords()
+	s := squareObject.squareLenght
+	transform := squareObject.shapeObject.GetTransformableObject().GetWorldTransform()
+
+	polygon := make([]Point2D, 0, 4)
+	for _, corner := range [4][2]int{{left, top}, {left + s, top}, {left + s, top + s}, {left, top + s}} {
+		cornerX, cornerY := transform.ApplyInt(corner[0], corner[1])
+		polygon = append(polygon, NewPoint2D(canvas, backgroundColor, cornerX, cornerY, squareObject.color))
+	}
+	point := NewPoint2D(canvas, backgroundColor, x, y, squareObject.color)
+	return isPointOnPolygon(point, polygon) || isPointInPolygon(point, polygon, canvas, backgroundColor)
+}
+
+// Collider returns the shape of the transformed square for the collision package.
+// Without shear (parents scaling non-uniformly a rotated child) the square stays a rotated rectangle.
+// @return collision.Shape: A rotated rectangle, or a polygon if the transformation shears the square.
+func (squareObject *squareObject) Collider() collision.Shape {
+	x, y := squareObject.squareTop.GetCoords()
+	s := float64(squareObject.squareLenght)
+	transform := squareObject.shapeObject.GetTransformableObject().GetWorldTransform()
+	a, b, c, d, _, _ := transform.Elements()
+	if math.Abs(a*b+c*d) < 1e-9 {
+		centerX, centerY := transform.Apply(float64(x)+s/2, float64(y)+s/2)
+		return collision.NewOBB(centerX, centerY, s*math.Hypot(a, c), s*math.Hypot(b, d), math.Atan2(c, a)*180/math.Pi)
+	}
+	corners := make([]collision.Vector, 0, 4)
+	for _, corner := range [4][2]float64{{0, 0}, {s, 0}, {s, s}, {0, s}} {
+		cornerX, cornerY := transform.Apply(float64(x)+corner[0], float64(y)+corner[1])
+		corners = append(corners, collision.NewVector(cornerX, cornerY))
+	}
+	polygon, err := collision.NewPolygon(corners...)
+	if err != nil {
+		return collision.NewAABB(float64(x), float64(y), float64(x), float64(y)) // The square is scaled to nothing.
+	}
+	return polygon
+}
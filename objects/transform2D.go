@@ -0,0 +1,162 @@
+package objects
+
+import (
+	"errors"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Transform2D is a 2D affine transformation stored as the first two rows of a 3x3 matrix:
+//
+//	| a  b  tx |
+//	| c  d  ty |
+//	| 0  0  1  |
+//
+// The layout and the order of operations match ebiten.GeoM, so a Transform2D can be converted
+// to GeoM and back without loss. Operations like Translate, Scale and Rotate are applied after
+// the existing transformation and return a new value.
+type Transform2D struct {
+	a, b, c, d float64 // The linear part of the matrix.
+	tx, ty     float64 // The translation part of the matrix.
+}
+
+// IdentityTransform2D returns the transformation which leaves every point unchanged.
+// @return Transform2D: The identity transformation.
+func IdentityTransform2D() Transform2D {
+	return Transform2D{a: 1, d: 1}
+}
+
+// NewTransform2D creates a transformation from the matrix elements.
+// @param a, b, c, d float64: The linear part of the matrix.
+// @param tx, ty float64: The translation part of the matrix.
+// @return Transform2D: The new transformation.
+func NewTransform2D(a, b, c, d, tx, ty float64) Transform2D {
+	return Transform2D{a: a, b: b, c: c, d: d, tx: tx, ty: ty}
+}
+
+// Transform2DFromGeoM converts an ebiten.GeoM to a Transform2D.
+// @param geoM ebiten.GeoM: The matrix to convert.
+// @return Transform2D: The equivalent transformation.
+func Transform2DFromGeoM(geoM ebiten.GeoM) Transform2D {
+	return Transform2D{
+		a:  geoM.Element(0, 0),
+		b:  geoM.Element(0, 1),
+		tx: geoM.Element(0, 2),
+		c:  geoM.Element(1, 0),
+		d:  geoM.Element(1, 1),
+		ty: geoM.Element(1, 2),
+	}
+}
+
+// GeoM converts the transformation to an ebiten.GeoM, ready to be used in DrawImageOptions.
+// @return ebiten.GeoM: The equivalent ebiten matrix.
+func (t Transform2D) GeoM() ebiten.GeoM {
+	var geoM ebiten.GeoM
+	geoM.SetElement(0, 0, t.a)
+	geoM.SetElement(0, 1, t.b)
+	geoM.SetElement(0, 2, t.tx)
+	geoM.SetElement(1, 0, t.c)
+	geoM.SetElement(1, 1, t.d)
+	geoM.SetElement(1, 2, t.ty)
+	return geoM
+}
+
+// Elements returns the matrix elements.
+// @return (a, b, c, d, tx, ty float64): The linear and the translation parts of the matrix.
+func (t Transform2D) Elements() (a, b, c, d, tx, ty float64) {
+	return t.a, t.b, t.c, t.d, t.tx, t.ty
+}
+
+// Concat returns the transformation which applies t first and then other.
+// @param other Transform2D: The transformation to apply after t.
+// @return Transform2D: The composed transformation.
+func (t Transform2D) Concat(other Transform2D) Transform2D {
+	return Transform2D{
+		a:  other.a*t.a + other.b*t.c,
+		b:  other.a*t.b + other.b*t.d,
+		c:  other.c*t.a + other.d*t.c,
+		d:  other.c*t.b + other.d*t.d,
+		tx: other.a*t.tx + other.b*t.ty + other.tx,
+		ty: other.c*t.tx + other.d*t.ty + other.ty,
+	}
+}
+
+// Translate returns t followed by a translation.
+// @param x, y float64: The translation offsets.
+// @return Transform2D: The resulting transformation.
+func (t Transform2D) Translate(x, y float64) Transform2D {
+	t.tx += x
+	t.ty += y
+	return t
+}
+
+// Scale returns t followed by a (possibly non-uniform) scaling around the origin.
+// @param x, y float64: The scale factors along the x and y axes.
+// @return Transform2D: The resulting transformation.
+func (t Transform2D) Scale(x, y float64) Transform2D {
+	return t.Concat(Transform2D{a: x, d: y})
+}
+
+// Rotate returns t followed by a rotation around the origin.
+// @param theta float64: The rotation angle in radians, clockwise on screen.
+// @return Transform2D: The resulting transformation.
+func (t Transform2D) Rotate(theta float64) Transform2D {
+	sin, cos := math.Sincos(theta)
+	return t.Concat(Transform2D{a: cos, b: -sin, c: sin, d: cos})
+}
+
+// Apply transforms a point.
+// @param x, y float64: The point to transform.
+// @return (float64, float64): The transformed point.
+func (t Transform2D) Apply(x, y float64) (float64, float64) {
+	return t.a*x + t.b*y + t.tx, t.c*x + t.d*y + t.ty
+}
+
+// ApplyInt transforms a point with integer coordinates and rounds the result to the nearest pixel.
+// @param x, y int: The point to transform.
+// @return (int, int): The transformed and rounded point.
+func (t Transform2D) ApplyInt(x, y int) (int, int) {
+	fx, fy := t.Apply(float64(x), float64(y))
+	return int(math.Round(fx)), int(math.Round(fy))
+}
+
+// Determinant returns the determinant of the linear part of the matrix.
+// @return float64: The determinant.
+func (t Transform2D) Determinant() float64 {
+	return t.a*t.d - t.b*t.c
+}
+
+// Invert returns the inverse transformation.
+// @return Transform2D: The inverse transformation.
+// @return error: Returns an error if the transformation is not invertible (for example scale 0).
+func (t Transform2D) Invert() (Transform2D, error) {
+	det := t.Determinant()
+	if det == 0 {
+		return Transform2D{}, errors.New("transform error: matrix is not invertible")
+	}
+	return Transform2D{
+		a:  t.d / det,
+		b:  -t.b / det,
+		c:  -t.c / det,
+		d:  t.a / det,
+		tx: (t.b*t.ty - t.d*t.tx) / det,
+		ty: (t.c*t.tx - t.a*t.ty) / det,
+	}, nil
+}
+
+// IsSimilarity reports whether the transformation only translates, rotates, mirrors and scales uniformly,
+// so circles stay circles.
+// @return bool: True if the transformation preserves shapes.
+func (t Transform2D) IsSimilarity() bool {
+	const epsilon = 1e-9
+	lengthX := t.a*t.a + t.c*t.c
+	lengthY := t.b*t.b + t.d*t.d
+	return math.Abs(lengthX-lengthY) < epsilon && math.Abs(t.a*t.b+t.c*t.d) < epsilon
+}
+
+// UniformScale returns the scale factor of a similarity transformation.
+// @return float64: The length a unit vector has after the transformation.
+func (t Transform2D) UniformScale() float64 {
+	return math.Hypot(t.a, t.c)
+}
@@ -0,0 +1,130 @@
+package objects
+
+import (
+	"math"
+	"testing"
+)
+
+// nearlyEqual compares two floats with a tolerance suitable for matrix arithmetic.
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestTransform2DApply(t *testing.T) {
+	cases := []struct {
+		name         string
+		transform    Transform2D
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{"identity", IdentityTransform2D(), 3, 4, 3, 4},
+		{"translate", IdentityTransform2D().Translate(10, -2), 3, 4, 13, 2},
+		{"scale non-uniform", IdentityTransform2D().Scale(1.5, 2), 2, 3, 3, 6},
+		{"rotate 90", IdentityTransform2D().Rotate(math.Pi / 2), 1, 0, 0, 1},
+		{"scale then translate", IdentityTransform2D().Scale(2, 2).Translate(1, 1), 1, 1, 3, 3},
+		{"translate then scale", IdentityTransform2D().Translate(1, 1).Scale(2, 2), 1, 1, 4, 4},
+	}
+	for _, tc := range cases {
+		x, y := tc.transform.Apply(tc.x, tc.y)
+		if !nearlyEqual(x, tc.wantX) || !nearlyEqual(y, tc.wantY) {
+			t.Errorf("%s: Apply(%v, %v) = (%v, %v), want (%v, %v)", tc.name, tc.x, tc.y, x, y, tc.wantX, tc.wantY)
+		}
+	}
+}
+
+func TestTransform2DMatchesGeoM(t *testing.T) {
+	transform := IdentityTransform2D().Translate(-5, 3).Scale(1.5, 0.5).Rotate(0.3).Translate(7, 11)
+	geoM := transform.GeoM()
+	if back := Transform2DFromGeoM(geoM); back != transform {
+		t.Errorf("round trip through GeoM changed the matrix: %v != %v", back, transform)
+	}
+	geoM.Rotate(-0.7)
+	transform = transform.Rotate(-0.7)
+
+	x, y := transform.Apply(2, 9)
+	gx, gy := geoM.Apply(2, 9)
+	if !nearlyEqual(x, gx) || !nearlyEqual(y, gy) {
+		t.Errorf("Transform2D gives (%v, %v), GeoM gives (%v, %v)", x, y, gx, gy)
+	}
+}
+
+func TestTransform2DInvert(t *testing.T) {
+	transform := IdentityTransform2D().Scale(2, 3).Rotate(1).Translate(4, -6)
+	inverse, err := transform.Invert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := inverse.Apply(transform.Apply(5, 7))
+	if !nearlyEqual(x, 5) || !nearlyEqual(y, 7) {
+		t.Errorf("inverse(transform(5, 7)) = (%v, %v)", x, y)
+	}
+	if _, err := IdentityTransform2D().Scale(0, 1).Invert(); err == nil {
+		t.Error("expected an error when inverting a degenerate matrix")
+	}
+}
+
+func TestTransformableObjectRelative(t *testing.T) {
+	transformable := NewTransformableObject(NewWScreenGameObject(goldenBackground))
+	transformable.Translate(10, 20)
+	transformable.TranslateBy(0.5, -1)
+	transformable.Scale(2)
+	transformable.ScaleBy(1.5, 0.5)
+	transformable.Rotate(350)
+	transformable.RotateBy(20)
+
+	if x, y := transformable.GetTranslationX(), transformable.GetTranslationY(); x != 10.5 || y != 19 {
+		t.Errorf("translation = (%v, %v), want (10.5, 19)", x, y)
+	}
+	if x, y := transformable.GetScaleX(), transformable.GetScaleY(); x != 3 || y != 1 {
+		t.Errorf("scale = (%v, %v), want (3, 1)", x, y)
+	}
+	if angle := transformable.GetAngle(); !nearlyEqual(angle, 10) {
+		t.Errorf("angle = %v, want 10", angle)
+	}
+
+	// Scaling around a pivot keeps the pivot in place.
+	transformable = NewTransformableObject(NewWScreenGameObject(goldenBackground))
+	transformable.SetPivot(4, 4)
+	transformable.ScaleXY(3, 0.25)
+	transformable.Rotate(45)
+	if x, y := transformable.GetTransform().Apply(4, 4); !nearlyEqual(x, 4) || !nearlyEqual(y, 4) {
+		t.Errorf("pivot moved to (%v, %v)", x, y)
+	}
+}
+
+// TestShapeObjectsGolden draws shape objects whose geometry is derived from their transformation matrix.
+func TestShapeObjectsGolden(t *testing.T) {
+	cases := []goldenCase{
+		{"shape_square_transformed", func(t *testing.T, c Canvas) {
+			square := EnhancedNewSquareObject(c, goldenBackground, 20, 22, 22, goldenLine)
+			transformable := square.GetShapeObject().GetTransformableObject()
+			transformable.Scale(1.5)
+			transformable.RotateBy(22.5)
+			transformable.RotateBy(22.5)
+			square.Draw()
+		}},
+		{"shape_circle_non_uniform", func(t *testing.T, c Canvas) {
+			circle := EnhancedNewCircleObject(c, goldenBackground, 32, 32, 12, goldenLine)
+			transformable := circle.GetShapeObject().GetTransformableObject()
+			transformable.ScaleXY(2.2, 1)
+			transformable.Rotate(30)
+			circle.Draw()
+		}},
+		{"shape_line_pivot", func(t *testing.T, c Canvas) {
+			line := EnhancedNewLineObject(c, goldenBackground, 12, 32, 52, 32, goldenLine)
+			transformable := line.GetShapeObject().GetTransformableObject()
+			transformable.SetPivot(-20, 0)
+			transformable.Rotate(-60)
+			transformable.TranslateBy(0, 20)
+			line.Draw()
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			canvas := NewImageCanvas(goldenWidth, goldenHeight)
+			canvas.Fill(goldenBackground)
+			tc.draw(t, canvas)
+			checkGolden(t, tc.name, canvas.(*imageCanvas).GetImage())
+		})
+	}
+}
@@ -1,68 +1,167 @@
 package objects
 
+import (
+	"errors"
+	"math"
+)
+
 // TransformableObject represents an object that can be transformed.
 // It provides methods for rotating, scaling, and translating the object,
-// as well as retrieving the current transformation values (scale, angle, and translation).
+// as well as retrieving the current transformation values (scale, angle, translation and pivot).
+// Absolute setters (Rotate, Scale, ScaleXY, Translate) replace a value, relative operations
+// (RotateBy, ScaleBy, TranslateBy) change it relative to the current one.
+// All values are combined into a single affine matrix returned by GetTransform.
 // Also this object inherit GameObject(the basic class of hierarchy)
 type TransformableObject interface {
 	// GetGameObject returns the associated game object of the transformable object.
 	// @return GameObject: The associated game object.
 	GetGameObject() GameObject
 
-	// Rotate rotates the object by the specified angle.
-	// @param angle int: The angle by which to rotate the object.
+	// Rotate sets the rotation angle of the object.
+	// @param angle float64: The angle in degrees.
+	// @return error: Returns nil if the rotation is applied successfully.
+	Rotate(angle float64) error
+
+	// Scale sets the same scale factor for both axes.
+	// @param scale float64: The scaling factor for the object.
+	// @return error: Returns nil if the scaling is applied successfully.
+	Scale(scale float64) error
+
+	// ScaleXY sets separate scale factors for the x and y axes.
+	// @param scaleX, scaleY float64: The scaling factors for the object.
+	// @return error: Returns nil if the scaling is applied successfully.
+	ScaleXY(scaleX, scaleY float64) error
+
+	// Translate sets the translation of the object.
+	// @param x float64: The x translation value.
+	// @param y float64: The y translation value.
+	// @return error: Returns nil if the translation is applied successfully.
+	Translate(x, y float64) error
+
+	// RotateBy adds the specified angle to the current rotation.
+	// @param angle float64: The angle in degrees.
 	// @return error: Returns nil if the rotation is applied successfully.
-	Rotate(angle int) error
+	RotateBy(angle float64) error
 
-	// Scale scales the object by the specified scale factor.
-	// @param scale int: The scaling factor for the object.
+	// ScaleBy multiplies the current scale factors.
+	// @param scaleX, scaleY float64: The factors to multiply the current scale with.
 	// @return error: Returns nil if the scaling is applied successfully.
-	Scale(scale int) error
+	ScaleBy(scaleX, scaleY float64) error
 
-	// Translate moves the object by the specified x and y values.
-	// @param x int: The x translation value.
-	// @param y int: The y translation value.
+	// TranslateBy adds the specified offsets to the current translation.
+	// @param x, y float64: The offsets.
 	// @return error: Returns nil if the translation is applied successfully.
-	Translate(x, y int) error
+	TranslateBy(x, y float64) error
 
-	// GetScale returns the current scale factor of the object.
-	// @return int: The current scale of the object.
-	GetScale() int
+	// SetPivot sets the point around which the object is rotated and scaled.
+	// The pivot is an offset from the object's own origin (for shapes their center, for bitmaps their top-left corner).
+	// @param x, y float64: The pivot offset.
+	// @return error: Returns nil if the pivot is set successfully.
+	SetPivot(x, y float64) error
+
+	// GetPivot returns the point around which the object is rotated and scaled.
+	// @return (float64, float64): The pivot offset.
+	GetPivot() (float64, float64)
+
+	// GetScaleX returns the current scale factor along the x axis.
+	// @return float64: The current x scale of the object.
+	GetScaleX() float64
+
+	// GetScaleY returns the current scale factor along the y axis.
+	// @return float64: The current y scale of the object.
+	GetScaleY() float64
 
 	// GetAngle returns the current rotation angle of the object.
-	// @return int: The current angle of the object.
-	GetAngle() int
+	// @return float64: The current angle of the object in degrees.
+	GetAngle() float64
 
 	// GetTranslationX returns the current x translation of the object.
-	// @return int: The current x translation value of the object.
-	GetTranslationX() int
+	// @return float64: The current x translation value of the object.
+	GetTranslationX() float64
 
 	// GetTranslationY returns the current y translation of the object.
-	// @return int: The current y translation value of the object.
-	GetTranslationY() int
+	// @return float64: The current y translation value of the object.
+	GetTranslationY() float64
+
+	// GetTransform returns the matrix combining pivot, scale, rotation and translation.
+	// Points are scaled and rotated around the pivot and then translated.
+	// @return Transform2D: The current transformation of the object.
+	GetTransform() Transform2D
+
+	// SetOrigin sets the origin of the object's geometry in screen coordinates.
+	// Shapes use their center, bitmaps their top-left corner.
+	// @param x, y float64: The origin.
+	// @return error: Returns nil if the origin is set successfully.
+	SetOrigin(x, y float64) error
+
+	// GetOrigin returns the origin of the object's geometry in screen coordinates.
+	// @return (float64, float64): The origin.
+	GetOrigin() (float64, float64)
+
+	// SetParent sets the transformable object whose transformation is applied after this one.
+	// @param parent TransformableObject: The parent, or nil to detach the object.
+	// @return error: Returns an error if the parent would create a cycle.
+	SetParent(parent TransformableObject) error
+
+	// GetParent returns the parent transformable object.
+	// @return TransformableObject: The parent or nil.
+	GetParent() TransformableObject
+
+	// GetWorldTransform returns the matrix which maps the object's geometry to the screen.
+	// The object's transformation is applied around its origin and then the parent's world transformation follows.
+	// @return Transform2D: The world transformation of the object.
+	GetWorldTransform() Transform2D
+
+	// AddChangeListener registers a function called after the world transformation of the object changes,
+	// by one of the setters of the object or of one of its parents. Setting a value equal to the current one
+	// does not call the listeners.
+	// @param listener func(): The function to call.
+	// @return int: The identifier of the listener, used to remove it.
+	AddChangeListener(listener func()) int
+
+	// RemoveChangeListener removes a function registered with AddChangeListener.
+	// @param id int: The identifier of the listener.
+	// @return error: Returns an error if no listener has this identifier.
+	RemoveChangeListener(id int) error
 }
 
 // transformableObject is an internal implementation of the TransformableObject interface.
-// It contains a reference to a game object, scale, angle, and translation values.
+// It contains a reference to a game object, scale, angle, translation and pivot values.
 type transformableObject struct {
-	gameObject   GameObject // The associated game object.
-	scale        int        // The current scale factor of the object.
-	angle        int        // The current rotation angle of the object.
-	translationX int        // The current x translation value of the object.
-	translationY int        // The current y translation value of the object.
+	gameObject   GameObject          // The associated game object.
+	scaleX       float64             // The current x scale factor of the object.
+	scaleY       float64             // The current y scale factor of the object.
+	angle        float64             // The current rotation angle of the object in degrees.
+	translationX float64             // The current x translation value of the object.
+	translationY float64             // The current y translation value of the object.
+	pivotX       float64             // The x offset of the pivot from the object's origin.
+	pivotY       float64             // The y offset of the pivot from the object's origin.
+	originX      float64             // The x coordinate of the origin of the object's geometry.
+	originY      float64             // The y coordinate of the origin of the object's geometry.
+	parent       TransformableObject // The parent whose world transformation is applied after this one.
+	parentListen int                 // The identifier of the listener registered on the parent.
+	listeners    map[int]func()      // The functions called after the world transformation changes.
+	nextListener int                 // The identifier given to the next listener.
 }
 
 // NewTransformableObject creates a new instance of a transformable object with the specified game object.
-// The object is initially set with default transformations: scale=1, angle=0, and translationX=0, translationY=0.
+// The object is initially set with default transformations: scale=1, angle=0, translation=(0, 0) and pivot=(0, 0).
 // @param gameObject GameObject: The game object to be associated with the transformable object.
 // @return TransformableObject: A new instance of the transformable object with default transformations.
 func NewTransformableObject(gameObject GameObject) TransformableObject {
 	return &transformableObject{
 		gameObject:   gameObject,
-		scale:        1,
+		scaleX:       1,
+		scaleY:       1,
 		angle:        0,
 		translationX: 0,
 		translationY: 0,
+		pivotX:       0,
+		pivotY:       0,
+		originX:      0,
+		originY:      0,
+		parent:       nil,
+		listeners:    map[int]func(){},
 	}
 }
 
@@ -72,52 +171,216 @@ func (transformableObject *transformableObject) GetGameObject() GameObject {
 	return transformableObject.gameObject
 }
 
-// Rotate rotates the object by the specified angle and updates the angle.
-// @param angle int: The angle to rotate the object.
+// Rotate sets the rotation angle of the object.
+// @param angle float64: The angle in degrees.
 // @return error: Returns nil if the rotation is successfully applied.
-func (transformableObject *transformableObject) Rotate(angle int) error {
+func (transformableObject *transformableObject) Rotate(angle float64) error {
+	if transformableObject.angle == angle {
+		return nil
+	}
 	transformableObject.angle = angle
+	transformableObject.notify()
 	return nil
 }
 
-// Scale scales the object by the specified scale factor and updates the scale.
-// @param scale int: The scaling factor for the object.
+// Scale sets the same scale factor for both axes.
+// @param scale float64: The scaling factor for the object.
 // @return error: Returns nil if the scaling is successfully applied.
-func (transformableObject *transformableObject) Scale(scale int) error {
-	transformableObject.scale = scale
+func (transformableObject *transformableObject) Scale(scale float64) error {
+	return transformableObject.ScaleXY(scale, scale)
+}
+
+// ScaleXY sets separate scale factors for the x and y axes.
+// @param scaleX, scaleY float64: The scaling factors for the object.
+// @return error: Returns nil if the scaling is successfully applied.
+func (transformableObject *transformableObject) ScaleXY(scaleX, scaleY float64) error {
+	if transformableObject.scaleX == scaleX && transformableObject.scaleY == scaleY {
+		return nil
+	}
+	transformableObject.scaleX = scaleX
+	transformableObject.scaleY = scaleY
+	transformableObject.notify()
 	return nil
 }
 
-// Translate translates the object by the specified x and y values and updates the translation.
-// @param x int: The x translation value.
-// @param y int: The y translation value.
+// Translate sets the translation of the object.
+// @param x float64: The x translation value.
+// @param y float64: The y translation value.
 // @return error: Returns nil if the translation is successfully applied.
-func (transformableObject *transformableObject) Translate(x, y int) error {
+func (transformableObject *transformableObject) Translate(x, y float64) error {
+	if transformableObject.translationX == x && transformableObject.translationY == y {
+		return nil
+	}
 	transformableObject.translationX = x
 	transformableObject.translationY = y
+	transformableObject.notify()
+	return nil
+}
+
+// RotateBy adds the specified angle to the current rotation.
+// @param angle float64: The angle in degrees.
+// @return error: Returns nil if the rotation is successfully applied.
+func (transformableObject *transformableObject) RotateBy(angle float64) error {
+	return transformableObject.Rotate(math.Mod(transformableObject.angle+angle, 360))
+}
+
+// ScaleBy multiplies the current scale factors.
+// @param scaleX, scaleY float64: The factors to multiply the current scale with.
+// @return error: Returns nil if the scaling is successfully applied.
+func (transformableObject *transformableObject) ScaleBy(scaleX, scaleY float64) error {
+	return transformableObject.ScaleXY(transformableObject.scaleX*scaleX, transformableObject.scaleY*scaleY)
+}
+
+// TranslateBy adds the specified offsets to the current translation.
+// @param x, y float64: The offsets.
+// @return error: Returns nil if the translation is successfully applied.
+func (transformableObject *transformableObject) TranslateBy(x, y float64) error {
+	return transformableObject.Translate(transformableObject.translationX+x, transformableObject.translationY+y)
+}
+
+// SetPivot sets the point around which the object is rotated and scaled.
+// @param x, y float64: The pivot offset from the object's origin.
+// @return error: Returns nil if the pivot is successfully set.
+func (transformableObject *transformableObject) SetPivot(x, y float64) error {
+	if transformableObject.pivotX == x && transformableObject.pivotY == y {
+		return nil
+	}
+	transformableObject.pivotX = x
+	transformableObject.pivotY = y
+	transformableObject.notify()
 	return nil
 }
 
-// GetScale returns the current scale factor of the transformable object.
-// @return int: The current scale of the object.
-func (t *transformableObject) GetScale() int {
-	return t.scale
+// GetPivot returns the point around which the object is rotated and scaled.
+// @return (float64, float64): The pivot offset from the object's origin.
+func (t *transformableObject) GetPivot() (float64, float64) {
+	return t.pivotX, t.pivotY
+}
+
+// GetScaleX returns the current x scale factor of the transformable object.
+// @return float64: The current x scale of the object.
+func (t *transformableObject) GetScaleX() float64 {
+	return t.scaleX
+}
+
+// GetScaleY returns the current y scale factor of the transformable object.
+// @return float64: The current y scale of the object.
+func (t *transformableObject) GetScaleY() float64 {
+	return t.scaleY
 }
 
 // GetAngle returns the current angle of rotation for the transformable object.
-// @return int: The current angle of the object.
-func (t *transformableObject) GetAngle() int {
+// @return float64: The current angle of the object in degrees.
+func (t *transformableObject) GetAngle() float64 {
 	return t.angle
 }
 
 // GetTranslationX returns the current x translation value of the transformable object.
-// @return int: The current x translation value of the object.
-func (t *transformableObject) GetTranslationX() int {
+// @return float64: The current x translation value of the object.
+func (t *transformableObject) GetTranslationX() float64 {
 	return t.translationX
 }
 
 // GetTranslationY returns the current y translation value of the transformable object.
-// @return int: The current y translation value of the object.
-func (t *transformableObject) GetTranslationY() int {
+// @return float64: The current y translation value of the object.
+func (t *transformableObject) GetTranslationY() float64 {
 	return t.translationY
 }
+
+// GetTransform returns the matrix combining pivot, scale, rotation and translation.
+// @return Transform2D: The current transformation of the object.
+func (t *transformableObject) GetTransform() Transform2D {
+	return IdentityTransform2D().
+		Translate(-t.pivotX, -t.pivotY).
+		Scale(t.scaleX, t.scaleY).
+		Rotate(t.angle*math.Pi/180.0).
+		Translate(t.pivotX+t.translationX, t.pivotY+t.translationY)
+}
+
+// SetOrigin sets the origin of the object's geometry in screen coordinates.
+// @param x, y float64: The origin.
+// @return error: Returns nil if the origin is successfully set.
+func (transformableObject *transformableObject) SetOrigin(x, y float64) error {
+	if transformableObject.originX == x && transformableObject.originY == y {
+		return nil
+	}
+	transformableObject.originX = x
+	transformableObject.originY = y
+	transformableObject.notify()
+	return nil
+}
+
+// GetOrigin returns the origin of the object's geometry in screen coordinates.
+// @return (float64, float64): The origin.
+func (t *transformableObject) GetOrigin() (float64, float64) {
+	return t.originX, t.originY
+}
+
+// SetParent sets the transformable object whose transformation is applied after this one.
+// @param parent TransformableObject: The parent, or nil to detach the object.
+// @return error: Returns an error if the object is an ancestor of the parent.
+func (transformableObject *transformableObject) SetParent(parent TransformableObject) error {
+	for ancestor := parent; ancestor != nil; ancestor = ancestor.GetParent() {
+		if ancestor == TransformableObject(transformableObject) {
+			return errors.New("transform error: parent would create a cycle")
+		}
+	}
+	if transformableObject.parent == parent {
+		return nil
+	}
+	if transformableObject.parent != nil {
+		transformableObject.parent.RemoveChangeListener(transformableObject.parentListen)
+	}
+	transformableObject.parent = parent
+	if parent != nil {
+		transformableObject.parentListen = parent.AddChangeListener(transformableObject.notify)
+	}
+	transformableObject.notify()
+	return nil
+}
+
+// GetParent returns the parent transformable object.
+// @return TransformableObject: The parent or nil.
+func (t *transformableObject) GetParent() TransformableObject {
+	return t.parent
+}
+
+// GetWorldTransform returns the matrix which maps the object's geometry to the screen.
+// @return Transform2D: The world transformation of the object.
+func (t *transformableObject) GetWorldTransform() Transform2D {
+	world := IdentityTransform2D().
+		Translate(-t.originX, -t.originY).
+		Concat(t.GetTransform()).
+		Translate(t.originX, t.originY)
+	if t.parent != nil {
+		world = world.Concat(t.parent.GetWorldTransform())
+	}
+	return world
+}
+
+// AddChangeListener registers a function called after the world transformation of the object changes.
+// @param listener func(): The function to call.
+// @return int: The identifier of the listener.
+func (transformableObject *transformableObject) AddChangeListener(listener func()) int {
+	transformableObject.nextListener++
+	transformableObject.listeners[transformableObject.nextListener] = listener
+	return transformableObject.nextListener
+}
+
+// RemoveChangeListener removes a function registered with AddChangeListener.
+// @param id int: The identifier of the listener.
+// @return error: Returns an error if no listener has this identifier.
+func (transformableObject *transformableObject) RemoveChangeListener(id int) error {
+	if _, ok := transformableObject.listeners[id]; !ok {
+		return errors.New("transform error: unknown change listener")
+	}
+	delete(transformableObject.listeners, id)
+	return nil
+}
+
+// notify calls the change listeners, including the ones of the children which follow this object.
+func (t *transformableObject) notify() {
+	for _, listener := range t.listeners {
+		listener()
+	}
+}
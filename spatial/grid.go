@@ -0,0 +1,320 @@
+package spatial
+
+import (
+	"errors"
+	"image"
+	"sort"
+)
+
+// cell identifies a square of the grid.
+type cell struct {
+	x, y int // The column and the row of the cell.
+}
+
+// gridItem stores an item of the grid.
+type gridItem struct {
+	bounds image.Rectangle // The bounding box of the item.
+	cells  image.Rectangle // The range of cells covered by the item, Max exclusive.
+}
+
+// grid implements Index with a uniform grid: every item is stored in all the cells its bounding box covers.
+type grid struct {
+	cellSize int              // The size of the cells in pixels.
+	cells    map[cell][]int   // The identifiers stored in each non-empty cell.
+	items    map[int]gridItem // The items by identifier.
+	extent   image.Rectangle  // The range of cells ever used, grows only.
+}
+
+// NewGrid creates an empty uniform grid index.
+// Objects of about the cell size give the best results, much larger objects are stored in many cells.
+// @param cellSize int: The size of the cells in pixels.
+// @return Index: The new index.
+// @return error: Returns an error if the cell size is not positive.
+func NewGrid(cellSize int) (Index, error) {
+	if cellSize <= 0 {
+		return nil, errors.New("spatial error: cell size must be positive")
+	}
+	return &grid{cellSize: cellSize, cells: map[cell][]int{}, items: map[int]gridItem{}}, nil
+}
+
+// Insert adds an item to the grid.
+// @param id int: The identifier of the item.
+// @param bounds image.Rectangle: The bounding box of the item.
+// @return error: Returns an error if the identifier is already used.
+func (grid *grid) Insert(id int, bounds image.Rectangle) error {
+	if _, ok := grid.items[id]; ok {
+		return errors.New("spatial error: item already in the index")
+	}
+	grid.add(id, bounds)
+	return nil
+}
+
+// Update moves an item to the cells covered by its new bounding box.
+// @param id int: The identifier of the item.
+// @param bounds image.Rectangle: The new bounding box of the item.
+// @return error: Returns an error if the item is not in the grid.
+func (grid *grid) Update(id int, bounds image.Rectangle) error {
+	item, ok := grid.items[id]
+	if !ok {
+		return errors.New("spatial error: item not in the index")
+	}
+	if item.bounds == bounds {
+		return nil
+	}
+	if grid.cellRange(bounds) == item.cells {
+		item.bounds = bounds
+		grid.items[id] = item
+		return nil
+	}
+	grid.remove(id, item)
+	grid.add(id, bounds)
+	return nil
+}
+
+// Remove removes an item from the grid.
+// @param id int: The identifier of the item.
+// @return error: Returns an error if the item is not in the grid.
+func (grid *grid) Remove(id int) error {
+	item, ok := grid.items[id]
+	if !ok {
+		return errors.New("spatial error: item not in the index")
+	}
+	grid.remove(id, item)
+	return nil
+}
+
+// Bounds returns the bounding box of an item.
+// @param id int: The identifier of the item.
+// @return image.Rectangle: The bounding box of the item.
+// @return bool: False if the item is not in the grid.
+func (grid *grid) Bounds(id int) (image.Rectangle, bool) {
+	item, ok := grid.items[id]
+	return item.bounds, ok
+}
+
+// Len returns the number of items in the grid.
+// @return int: The number of items.
+func (grid *grid) Len() int {
+	return len(grid.items)
+}
+
+// QueryRect returns the items whose bounding boxes overlap a rectangle, visiting only the cells it covers.
+// @param rect image.Rectangle: The rectangle.
+// @return []int: The identifiers in ascending order.
+func (grid *grid) QueryRect(rect image.Rectangle) []int {
+	if rect.Empty() {
+		return nil
+	}
+	seen := map[int]bool{}
+	var result []int
+	grid.eachCell(grid.cellRange(rect).Intersect(grid.extent), func(ids []int) {
+		for _, id := range ids {
+			if !seen[id] && grid.items[id].bounds.Overlaps(rect) {
+				result = append(result, id)
+			}
+			seen[id] = true
+		}
+	})
+	sort.Ints(result)
+	return result
+}
+
+// QueryPoint returns the items whose bounding boxes contain a point, visiting only the cell of the point.
+// @param x, y int: The point.
+// @return []int: The identifiers in ascending order.
+func (grid *grid) QueryPoint(x, y int) []int {
+	var result []int
+	point := image.Pt(x, y)
+	for _, id := range grid.cells[grid.cellOf(x, y)] {
+		if point.In(grid.items[id].bounds) {
+			result = append(result, id)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// Nearest returns the k items closest to a point, visiting rings of cells around it until
+// no unvisited item can be closer than the k-th found one.
+// @param x, y int: The point.
+// @param k int: The number of items to return.
+// @return []int: The identifiers ordered by distance, ties by identifier.
+func (grid *grid) Nearest(x, y, k int) []int {
+	if k <= 0 || len(grid.items) == 0 {
+		return nil
+	}
+	type candidate struct {
+		id       int
+		distance float64
+	}
+	var best []candidate
+	seen := map[int]bool{}
+	center := grid.cellOf(x, y)
+	last := max(center.x-grid.extent.Min.X, grid.extent.Max.X-1-center.x, center.y-grid.extent.Min.Y, grid.extent.Max.Y-1-center.y)
+	for ring := 0; ring <= last; ring++ {
+		// Items not seen yet only cover cells of this ring or further, at least (ring-1) cells away from the point.
+		if len(best) == k && best[k-1].distance <= float64((ring-1)*grid.cellSize) {
+			break
+		}
+		grid.eachRingCell(center, ring, func(ids []int) {
+			for _, id := range ids {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				best = append(best, candidate{id: id, distance: distance(x, y, grid.items[id].bounds)})
+			}
+		})
+		sort.Slice(best, func(i, j int) bool {
+			if best[i].distance != best[j].distance {
+				return best[i].distance < best[j].distance
+			}
+			return best[i].id < best[j].id
+		})
+		if len(best) > k {
+			best = best[:k]
+		}
+	}
+	result := make([]int, len(best))
+	for i, candidate := range best {
+		result[i] = candidate.id
+	}
+	return result
+}
+
+// Pairs returns all pairs of items whose bounding boxes overlap, testing only items sharing a cell.
+// @return [][2]int: The pairs with the smaller identifier first, in ascending order.
+func (grid *grid) Pairs() [][2]int {
+	var result [][2]int
+	for key, ids := range grid.cells {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				overlap := grid.items[a].bounds.Intersect(grid.items[b].bounds)
+				// Items sharing several cells are reported once, by the cell of the overlap's top-left corner.
+				if overlap.Empty() || grid.cellOf(overlap.Min.X, overlap.Min.Y) != key {
+					continue
+				}
+				result = append(result, [2]int{min(a, b), max(a, b)})
+			}
+		}
+	}
+	sortPairs(result)
+	return result
+}
+
+// add stores an item in the cells its bounding box covers.
+// @param id int: The identifier of the item.
+// @param bounds image.Rectangle: The bounding box of the item.
+func (grid *grid) add(id int, bounds image.Rectangle) {
+	cells := grid.cellRange(bounds)
+	grid.items[id] = gridItem{bounds: bounds, cells: cells}
+	if cells.Empty() {
+		return
+	}
+	if grid.extent.Empty() {
+		grid.extent = cells
+	} else {
+		grid.extent = grid.extent.Union(cells)
+	}
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			key := cell{x: x, y: y}
+			grid.cells[key] = append(grid.cells[key], id)
+		}
+	}
+}
+
+// remove deletes an item from the cells it covers.
+// @param id int: The identifier of the item.
+// @param item gridItem: The stored item.
+func (grid *grid) remove(id int, item gridItem) {
+	for y := item.cells.Min.Y; y < item.cells.Max.Y; y++ {
+		for x := item.cells.Min.X; x < item.cells.Max.X; x++ {
+			key := cell{x: x, y: y}
+			ids := grid.cells[key]
+			for i, other := range ids {
+				if other == id {
+					ids = append(ids[:i], ids[i+1:]...)
+					break
+				}
+			}
+			if len(ids) == 0 {
+				delete(grid.cells, key)
+			} else {
+				grid.cells[key] = ids
+			}
+		}
+	}
+	delete(grid.items, id)
+}
+
+// cellOf returns the cell containing a point.
+// @param x, y int: The point.
+// @return cell: The cell.
+func (grid *grid) cellOf(x, y int) cell {
+	return cell{x: floorDiv(x, grid.cellSize), y: floorDiv(y, grid.cellSize)}
+}
+
+// cellRange returns the range of cells covered by a rectangle.
+// @param rect image.Rectangle: The rectangle.
+// @return image.Rectangle: The cells, Max exclusive, empty for an empty rectangle.
+func (grid *grid) cellRange(rect image.Rectangle) image.Rectangle {
+	if rect.Empty() {
+		return image.Rectangle{}
+	}
+	first := grid.cellOf(rect.Min.X, rect.Min.Y)
+	last := grid.cellOf(rect.Max.X-1, rect.Max.Y-1)
+	return image.Rect(first.x, first.y, last.x+1, last.y+1)
+}
+
+// eachCell calls a function with the identifiers of every non-empty cell in a range.
+// @param cells image.Rectangle: The range of cells.
+// @param visit func(ids []int): The function to call.
+func (grid *grid) eachCell(cells image.Rectangle, visit func(ids []int)) {
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			if ids, ok := grid.cells[cell{x: x, y: y}]; ok {
+				visit(ids)
+			}
+		}
+	}
+}
+
+// eachRingCell calls a function with the identifiers of every non-empty cell at a Chebyshev distance from a cell.
+// @param center cell: The center of the ring.
+// @param ring int: The distance in cells, 0 is the center itself.
+// @param visit func(ids []int): The function to call.
+func (grid *grid) eachRingCell(center cell, ring int, visit func(ids []int)) {
+	if ring == 0 {
+		grid.eachCell(image.Rect(center.x, center.y, center.x+1, center.y+1), visit)
+		return
+	}
+	top := image.Rect(center.x-ring, center.y-ring, center.x+ring+1, center.y-ring+1)
+	bottom := top.Add(image.Pt(0, 2*ring))
+	left := image.Rect(center.x-ring, center.y-ring+1, center.x-ring+1, center.y+ring)
+	right := left.Add(image.Pt(2*ring, 0))
+	for _, side := range []image.Rectangle{top, bottom, left, right} {
+		grid.eachCell(side.Intersect(grid.extent), visit)
+	}
+}
+
+// floorDiv divides rounding towards negative infinity, so negative coordinates get their own cells.
+// @param a, b int: The dividend and the positive divisor.
+// @return int: The quotient.
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+// sortPairs orders pairs by their first then their second identifier.
+// @param pairs [][2]int: The pairs to sort in place.
+func sortPairs(pairs [][2]int) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+}
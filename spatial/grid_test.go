@@ -0,0 +1,170 @@
+package spatial
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// randomBoxes returns reproducible boxes of 4 to 36 pixels spread over a square world.
+func randomBoxes(n, world int) []image.Rectangle {
+	random := rand.New(rand.NewSource(int64(n)))
+	boxes := make([]image.Rectangle, n)
+	for i := range boxes {
+		x, y := random.Intn(world)-world/4, random.Intn(world)-world/4
+		boxes[i] = image.Rect(x, y, x+4+random.Intn(32), y+4+random.Intn(32))
+	}
+	return boxes
+}
+
+// newFilledGrid returns a grid holding the boxes with their positions as identifiers.
+func newFilledGrid(t testing.TB, cellSize int, boxes []image.Rectangle) Index {
+	index, err := NewGrid(cellSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, box := range boxes {
+		if err := index.Insert(id, box); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return index
+}
+
+// naivePairs tests every pair of boxes, the O(n²) scan the grid replaces.
+func naivePairs(boxes []image.Rectangle) [][2]int {
+	var pairs [][2]int
+	for a := range boxes {
+		for b := a + 1; b < len(boxes); b++ {
+			if boxes[a].Overlaps(boxes[b]) {
+				pairs = append(pairs, [2]int{a, b})
+			}
+		}
+	}
+	return pairs
+}
+
+// naiveNearest sorts all boxes by distance.
+func naiveNearest(boxes []image.Rectangle, x, y, k int) []int {
+	ids := make([]int, len(boxes))
+	for i := range ids {
+		ids[i] = i
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return distance(x, y, boxes[ids[i]]) < distance(x, y, boxes[ids[j]])
+	})
+	return ids[:min(k, len(ids))]
+}
+
+func TestGridMatchesNaiveScan(t *testing.T) {
+	boxes := randomBoxes(300, 400)
+	index := newFilledGrid(t, 32, boxes)
+
+	if got, want := index.Pairs(), naivePairs(boxes); !reflect.DeepEqual(got, want) {
+		t.Errorf("pairs: got %d, want %d", len(got), len(want))
+	}
+	rect := image.Rect(50, -20, 170, 90)
+	var want []int
+	for id, box := range boxes {
+		if box.Overlaps(rect) {
+			want = append(want, id)
+		}
+	}
+	if got := index.QueryRect(rect); !reflect.DeepEqual(got, want) {
+		t.Errorf("rect query = %v, want %v", got, want)
+	}
+	for _, point := range []image.Point{{0, 0}, {-37, 12}, {150, 260}, {900, 900}} {
+		var want []int
+		for id, box := range boxes {
+			if point.In(box) {
+				want = append(want, id)
+			}
+		}
+		if got := index.QueryPoint(point.X, point.Y); !reflect.DeepEqual(got, want) {
+			t.Errorf("point query %v = %v, want %v", point, got, want)
+		}
+		if got, want := index.Nearest(point.X, point.Y, 5), naiveNearest(boxes, point.X, point.Y, 5); !reflect.DeepEqual(got, want) {
+			t.Errorf("nearest to %v = %v, want %v", point, got, want)
+		}
+	}
+}
+
+func TestGridUpdateAndRemove(t *testing.T) {
+	index := newFilledGrid(t, 16, []image.Rectangle{image.Rect(0, 0, 10, 10), image.Rect(40, 40, 50, 50)})
+	if err := index.Insert(0, image.Rect(0, 0, 1, 1)); err == nil {
+		t.Error("expected an error for a used identifier")
+	}
+	if len(index.Pairs()) != 0 {
+		t.Error("separated boxes must not form a pair")
+	}
+	if err := index.Update(1, image.Rect(5, 5, 15, 15)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := index.Pairs(), [][2]int{{0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pairs after update = %v, want %v", got, want)
+	}
+	if got := index.QueryPoint(45, 45); len(got) != 0 {
+		t.Errorf("moved box still found at its old place: %v", got)
+	}
+	if err := index.Remove(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Remove(0); err == nil {
+		t.Error("expected an error for a removed identifier")
+	}
+	if got := index.Nearest(100, 100, 3); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("nearest after remove = %v, want [1]", got)
+	}
+	if _, err := NewGrid(0); err == nil {
+		t.Error("expected an error for a zero cell size")
+	}
+}
+
+func BenchmarkPairs(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		boxes := randomBoxes(n, 20*n/10+200)
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naivePairs(boxes)
+			}
+		})
+		b.Run(fmt.Sprintf("grid/%d", n), func(b *testing.B) {
+			index := newFilledGrid(b, 32, boxes)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				index.Pairs()
+			}
+		})
+	}
+}
+
+func BenchmarkQueryPoint(b *testing.B) {
+	boxes := randomBoxes(5000, 1200)
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, box := range boxes {
+				_ = image.Pt(i%1000, i%700).In(box)
+			}
+		}
+	})
+	b.Run("grid", func(b *testing.B) {
+		index := newFilledGrid(b, 32, boxes)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			index.QueryPoint(i%1000, i%700)
+		}
+	})
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	boxes := randomBoxes(5000, 1200)
+	index := newFilledGrid(b, 32, boxes)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := i % len(boxes)
+		index.Update(id, boxes[id].Add(image.Pt(i%7-3, i%5-2)))
+	}
+}
@@ -0,0 +1,69 @@
+// Package spatial store the broad-phase spatial indexes of the engine
+package spatial
+
+import (
+	"image"
+	"math"
+)
+
+// Index represents a broad-phase spatial index of items identified by integers and described by their bounding boxes.
+// It answers which items are near a rectangle or a point without testing every item.
+// Results are exact for the bounding boxes, a narrow-phase test (collision package, Contains) can follow.
+type Index interface {
+	// Insert adds an item to the index.
+	// @param id int: The identifier of the item.
+	// @param bounds image.Rectangle: The bounding box of the item.
+	// @return error: Returns an error if the identifier is already used.
+	Insert(id int, bounds image.Rectangle) error
+
+	// Update changes the bounding box of an item.
+	// @param id int: The identifier of the item.
+	// @param bounds image.Rectangle: The new bounding box of the item.
+	// @return error: Returns an error if the item is not in the index.
+	Update(id int, bounds image.Rectangle) error
+
+	// Remove removes an item from the index.
+	// @param id int: The identifier of the item.
+	// @return error: Returns an error if the item is not in the index.
+	Remove(id int) error
+
+	// Bounds returns the bounding box of an item.
+	// @param id int: The identifier of the item.
+	// @return image.Rectangle: The bounding box of the item.
+	// @return bool: False if the item is not in the index.
+	Bounds(id int) (image.Rectangle, bool)
+
+	// Len returns the number of items in the index.
+	// @return int: The number of items.
+	Len() int
+
+	// QueryRect returns the items whose bounding boxes overlap a rectangle.
+	// @param rect image.Rectangle: The rectangle.
+	// @return []int: The identifiers in ascending order.
+	QueryRect(rect image.Rectangle) []int
+
+	// QueryPoint returns the items whose bounding boxes contain a point.
+	// @param x, y int: The point.
+	// @return []int: The identifiers in ascending order.
+	QueryPoint(x, y int) []int
+
+	// Nearest returns the k items whose bounding boxes are the closest to a point.
+	// @param x, y int: The point.
+	// @param k int: The number of items to return.
+	// @return []int: The identifiers ordered by distance, ties by identifier.
+	Nearest(x, y, k int) []int
+
+	// Pairs returns all pairs of items whose bounding boxes overlap.
+	// @return [][2]int: The pairs with the smaller identifier first, in ascending order.
+	Pairs() [][2]int
+}
+
+// distance returns the distance of a point from a rectangle, 0 if the point is inside.
+// @param x, y int: The point.
+// @param rect image.Rectangle: The rectangle.
+// @return float64: The distance.
+func distance(x, y int, rect image.Rectangle) float64 {
+	dx := max(rect.Min.X-x, 0, x-(rect.Max.X-1))
+	dy := max(rect.Min.Y-y, 0, y-(rect.Max.Y-1))
+	return math.Hypot(float64(dx), float64(dy))
+}